@@ -28,6 +28,15 @@ type MongoOptions struct {
 	AuthorizedDatabases   *bool  `yaml:"authorizedDatabases,omitempty"`
 	AuthorizedCollections *bool  `yaml:"authorizedCollections,omitempty"`
 	Limit                 *int64 `yaml:"limit,omitempty"`
+	// Pagination is the default pagination mode of collections, "skip" or "keyset"
+	Pagination string `yaml:"pagination,omitempty"`
+	// MaxTimeMS is the default time limit of queries run on the server, 0 means no limit
+	MaxTimeMS *int64 `yaml:"maxTimeMS,omitempty"`
+	// CountStrategy is the way documents matching the query are counted,
+	// "exact", "estimated", "bounded" or "disabled"
+	CountStrategy string `yaml:"countStrategy,omitempty"`
+	// CountLimit is the number of documents at which the bounded count stops
+	CountLimit *int64 `yaml:"countLimit,omitempty"`
 }
 
 type MongoConfig struct {
@@ -33,6 +33,14 @@ type (
 		SortBar      SortBar          `yaml:"sortBar"`
 		Index        IndexKeys        `yaml:"index"`
 		IndexAddForm IndexAddFormKeys `yaml:"indexAddForm"`
+		Schema       SchemaKeys       `yaml:"schema"`
+		Operations   OperationsKeys   `yaml:"operations"`
+		Profiler     ProfilerKeys     `yaml:"profiler"`
+		Dashboard    DashboardKeys    `yaml:"dashboard"`
+		Sharding     ShardingKeys     `yaml:"sharding"`
+		Users        UsersKeys        `yaml:"users"`
+		Stats        StatsKeys        `yaml:"stats"`
+		GridFS       GridFSKeys       `yaml:"gridfs"`
 		AIQuery      AIQueryKeys      `yaml:"aiQuery"`
 		History      HistoryKeys      `yaml:"history"`
 		Aggregation  AggregationKeys  `yaml:"aggregation"`
@@ -60,6 +68,7 @@ type (
 	// as keys are passed from top to bottom
 	GlobalKeys struct {
 		CloseApp             Key `yaml:"closeApp"`
+		CancelQuery          Key `yaml:"cancelQuery"`
 		ToggleFullScreenHelp Key `yaml:"toggleFullScreenHelp"`
 		OpenConnection       Key `yaml:"openConnection"`
 		ShowStyleModal       Key `yaml:"showStyleModal"`
@@ -72,6 +81,8 @@ type (
 		HideDatabases  Key `yaml:"hideDatabases"`
 		ShowAIQuery    Key `yaml:"showAIQuery"`
 		ShowServerInfo Key `yaml:"showServerInfo"`
+		ShowOperations Key `yaml:"showOperations"`
+		ShowSharding   Key `yaml:"showSharding"`
 	}
 
 	DatabasesKeys struct {
@@ -82,6 +93,13 @@ type (
 		AddCollection    Key `yaml:"addCollection"`
 		DeleteCollection Key `yaml:"deleteCollection"`
 		RenameCollection Key `yaml:"renameCollection"`
+		ImportDocuments  Key `yaml:"importDocuments"`
+		ShowProfiler     Key `yaml:"showProfiler"`
+		ShowUsers        Key `yaml:"showUsers"`
+		EditValidation   Key `yaml:"editValidation"`
+		TestValidation   Key `yaml:"testValidation"`
+		ShowStats        Key `yaml:"showStats"`
+		ToggleSizes      Key `yaml:"toggleSizes"`
 	}
 
 	FilterBarKeys struct {
@@ -108,6 +126,8 @@ type (
 		PreviousDocument           Key `yaml:"previousDocument"`
 		NextPage                   Key `yaml:"nextPage"`
 		PreviousPage               Key `yaml:"previousPage"`
+		FirstPage                  Key `yaml:"firstPage"`
+		LastPage                   Key `yaml:"lastPage"`
 		ToggleSortBar              Key `yaml:"toggleSortBar"`
 		SortByColumn               Key `yaml:"sortByColumn"`
 		HideColumn                 Key `yaml:"hideColumn"`
@@ -115,6 +135,14 @@ type (
 		ToggleQueryOptions         Key `yaml:"toggleQueryOptions"`
 		MultipleSelect             Key `yaml:"multipleSelect"`
 		ClearSelection             Key `yaml:"clearSelection"`
+		ExportDocuments            Key `yaml:"exportDocuments"`
+		UpdateMany                 Key `yaml:"updateMany"`
+		DeleteMany                 Key `yaml:"deleteMany"`
+		ExplainQuery               Key `yaml:"explainQuery"`
+		ToggleLiveMode             Key `yaml:"toggleLiveMode"`
+		StartTransaction           Key `yaml:"startTransaction"`
+		CommitTransaction          Key `yaml:"commitTransaction"`
+		AbortTransaction           Key `yaml:"abortTransaction"`
 	}
 
 	QueryBar struct {
@@ -168,8 +196,10 @@ type (
 	}
 
 	IndexKeys struct {
-		AddIndex    Key `yaml:"addIndex"`
-		DeleteIndex Key `yaml:"deleteIndex"`
+		AddIndex              Key `yaml:"addIndex"`
+		DeleteIndex           Key `yaml:"deleteIndex"`
+		ToggleHidden          Key `yaml:"toggleHidden"`
+		ToggleRecommendations Key `yaml:"toggleRecommendations"`
 	}
 
 	IndexAddFormKeys struct {
@@ -178,6 +208,68 @@ type (
 		CreateIndex Key `yaml:"createIndex"`
 	}
 
+	SchemaKeys struct {
+		Refresh          Key `yaml:"refresh"`
+		ChangeSampleSize Key `yaml:"changeSampleSize"`
+	}
+
+	OperationsKeys struct {
+		Close             Key `yaml:"close"`
+		Refresh           Key `yaml:"refresh"`
+		ToggleAutoRefresh Key `yaml:"toggleAutoRefresh"`
+		FilterNamespace   Key `yaml:"filterNamespace"`
+		ChangeMinDuration Key `yaml:"changeMinDuration"`
+		PeekOperation     Key `yaml:"peekOperation"`
+		KillOperation     Key `yaml:"killOperation"`
+	}
+
+	ProfilerKeys struct {
+		Close         Key `yaml:"close"`
+		Refresh       Key `yaml:"refresh"`
+		ChangeLevel   Key `yaml:"changeLevel"`
+		SetSlowMs     Key `yaml:"setSlowMs"`
+		PeekOperation Key `yaml:"peekOperation"`
+		JumpToIndexes Key `yaml:"jumpToIndexes"`
+	}
+
+	DashboardKeys struct {
+		Close          Key `yaml:"close"`
+		TogglePause    Key `yaml:"togglePause"`
+		ChangeInterval Key `yaml:"changeInterval"`
+	}
+
+	ShardingKeys struct {
+		Close            Key `yaml:"close"`
+		Refresh          Key `yaml:"refresh"`
+		JumpToCollection Key `yaml:"jumpToCollection"`
+	}
+
+	UsersKeys struct {
+		Close          Key `yaml:"close"`
+		Refresh        Key `yaml:"refresh"`
+		ToggleRoles    Key `yaml:"toggleRoles"`
+		AddUser        Key `yaml:"addUser"`
+		DropUser       Key `yaml:"dropUser"`
+		ChangePassword Key `yaml:"changePassword"`
+		GrantRoles     Key `yaml:"grantRoles"`
+		RevokeRoles    Key `yaml:"revokeRoles"`
+		ShowPrivileges Key `yaml:"showPrivileges"`
+	}
+
+	StatsKeys struct {
+		Close            Key `yaml:"close"`
+		Refresh          Key `yaml:"refresh"`
+		JumpToCollection Key `yaml:"jumpToCollection"`
+	}
+
+	GridFSKeys struct {
+		Close    Key `yaml:"close"`
+		Refresh  Key `yaml:"refresh"`
+		Download Key `yaml:"download"`
+		Upload   Key `yaml:"upload"`
+		Delete   Key `yaml:"delete"`
+	}
+
 	AIQueryKeys struct {
 		ExitAIQuery Key `yaml:"exitAIQuery"`
 		ClearPrompt Key `yaml:"clearPrompt"`
@@ -198,6 +290,7 @@ type (
 		MoveStageUp          Key `yaml:"moveStageUp"`
 		FocusResults         Key `yaml:"focusResults"`
 		EditPipelineInEditor Key `yaml:"editPipelineInEditor"`
+		ExplainPipeline      Key `yaml:"explainPipeline"`
 	}
 
 	AggregationResultKeys struct {
@@ -216,6 +309,10 @@ func (k *KeyBindings) loadDefaults() {
 			Keys:        []string{"Ctrl+c"},
 			Description: "Close application",
 		},
+		CancelQuery: Key{
+			Keys:        []string{"Esc", "Ctrl+c"},
+			Description: "Cancel running query",
+		},
 		ToggleFullScreenHelp: Key{
 			Runes:       []string{"?"},
 			Description: "Toggle full screen help",
@@ -249,12 +346,20 @@ func (k *KeyBindings) loadDefaults() {
 		},
 		ShowServerInfo: Key{
 			Keys:        []string{"Alt+s"},
-			Description: "Show server info",
+			Description: "Show server status dashboard",
 		},
 		ShowAIQuery: Key{
 			Keys:        []string{"Alt+a"},
 			Description: "Show AI prompt",
 		},
+		ShowOperations: Key{
+			Keys:        []string{"Alt+p"},
+			Description: "Show current operations",
+		},
+		ShowSharding: Key{
+			Keys:        []string{"Alt+c"},
+			Description: "Show sharding status",
+		},
 	}
 
 	k.Navigation = NavigationKeys{
@@ -309,6 +414,34 @@ func (k *KeyBindings) loadDefaults() {
 			Runes:       []string{"R"},
 			Description: "Rename collection",
 		},
+		ImportDocuments: Key{
+			Runes:       []string{"I"},
+			Description: "Import documents",
+		},
+		ShowProfiler: Key{
+			Runes:       []string{"P"},
+			Description: "Show database profiler",
+		},
+		ShowUsers: Key{
+			Runes:       []string{"U"},
+			Description: "Show users and roles",
+		},
+		EditValidation: Key{
+			Runes:       []string{"V"},
+			Description: "Edit validation rules",
+		},
+		TestValidation: Key{
+			Runes:       []string{"T"},
+			Description: "Test validator against documents",
+		},
+		ShowStats: Key{
+			Runes:       []string{"S"},
+			Description: "Show collection or database stats",
+		},
+		ToggleSizes: Key{
+			Runes:       []string{"N"},
+			Description: "Toggle collection sizes in the tree",
+		},
 	}
 
 	k.FilterBar = FilterBarKeys{
@@ -420,10 +553,50 @@ func (k *KeyBindings) loadDefaults() {
 			Runes:       []string{"b"},
 			Description: "Previous page",
 		},
+		FirstPage: Key{
+			Runes:       []string{"B"},
+			Description: "First page",
+		},
+		LastPage: Key{
+			Runes:       []string{"N"},
+			Description: "Last page",
+		},
 		ToggleQueryOptions: Key{
 			Keys:        []string{"Alt+o"},
 			Description: "Toggle query options",
 		},
+		ExportDocuments: Key{
+			Keys:        []string{"Alt+e"},
+			Description: "Export documents",
+		},
+		UpdateMany: Key{
+			Runes:       []string{"U"},
+			Description: "Update matching",
+		},
+		DeleteMany: Key{
+			Runes:       []string{"X"},
+			Description: "Delete matching",
+		},
+		ExplainQuery: Key{
+			Runes:       []string{"x"},
+			Description: "Explain query",
+		},
+		ToggleLiveMode: Key{
+			Runes:       []string{"L"},
+			Description: "Toggle live mode",
+		},
+		StartTransaction: Key{
+			Runes:       []string{"T"},
+			Description: "Start transaction or show pending writes",
+		},
+		CommitTransaction: Key{
+			Keys:        []string{"Alt+t"},
+			Description: "Commit transaction",
+		},
+		AbortTransaction: Key{
+			Keys:        []string{"Alt+r"},
+			Description: "Abort transaction",
+		},
 	}
 
 	k.QueryBar = QueryBar{
@@ -551,6 +724,14 @@ func (k *KeyBindings) loadDefaults() {
 			Keys:        []string{"Ctrl+d"},
 			Description: "Delete index",
 		},
+		ToggleHidden: Key{
+			Runes:       []string{"H"},
+			Description: "Hide or unhide index",
+		},
+		ToggleRecommendations: Key{
+			Runes:       []string{"R"},
+			Description: "Toggle index recommendations",
+		},
 	}
 
 	k.IndexAddForm = IndexAddFormKeys{
@@ -568,6 +749,184 @@ func (k *KeyBindings) loadDefaults() {
 		},
 	}
 
+	k.Schema = SchemaKeys{
+		Refresh: Key{
+			Runes:       []string{"R"},
+			Description: "Sample again",
+		},
+		ChangeSampleSize: Key{
+			Runes:       []string{"s"},
+			Description: "Change sample size",
+		},
+	}
+
+	k.Operations = OperationsKeys{
+		Close: Key{
+			Keys:        []string{"Esc"},
+			Description: "Close operations",
+		},
+		Refresh: Key{
+			Runes:       []string{"R"},
+			Description: "Refresh operations",
+		},
+		ToggleAutoRefresh: Key{
+			Runes:       []string{"a"},
+			Description: "Toggle auto refresh",
+		},
+		FilterNamespace: Key{
+			Runes:       []string{"/"},
+			Description: "Filter by namespace",
+		},
+		ChangeMinDuration: Key{
+			Runes:       []string{"d"},
+			Description: "Change minimum running time",
+		},
+		PeekOperation: Key{
+			Runes:       []string{"p"},
+			Keys:        []string{"Enter"},
+			Description: "Peek operation",
+		},
+		KillOperation: Key{
+			Runes:       []string{"K"},
+			Description: "Kill operation",
+		},
+	}
+
+	k.Profiler = ProfilerKeys{
+		Close: Key{
+			Keys:        []string{"Esc"},
+			Description: "Close profiler",
+		},
+		Refresh: Key{
+			Runes:       []string{"R"},
+			Description: "Refresh slow operations",
+		},
+		ChangeLevel: Key{
+			Runes:       []string{"l"},
+			Description: "Change profiling level",
+		},
+		SetSlowMs: Key{
+			Runes:       []string{"s"},
+			Description: "Set slowms threshold",
+		},
+		PeekOperation: Key{
+			Runes:       []string{"p"},
+			Keys:        []string{"Enter"},
+			Description: "Peek operation",
+		},
+		JumpToIndexes: Key{
+			Runes:       []string{"i"},
+			Description: "Jump to collection indexes",
+		},
+	}
+
+	k.Dashboard = DashboardKeys{
+		Close: Key{
+			Keys:        []string{"Esc"},
+			Description: "Close dashboard",
+		},
+		TogglePause: Key{
+			Runes:       []string{"p"},
+			Description: "Pause or resume polling",
+		},
+		ChangeInterval: Key{
+			Runes:       []string{"i"},
+			Description: "Change polling interval",
+		},
+	}
+
+	k.Sharding = ShardingKeys{
+		Close: Key{
+			Keys:        []string{"Esc"},
+			Description: "Close sharding status",
+		},
+		Refresh: Key{
+			Runes:       []string{"R"},
+			Description: "Refresh sharding status",
+		},
+		JumpToCollection: Key{
+			Keys:        []string{"Enter"},
+			Description: "Jump to collection",
+		},
+	}
+
+	k.Users = UsersKeys{
+		Close: Key{
+			Keys:        []string{"Esc"},
+			Description: "Close users",
+		},
+		Refresh: Key{
+			Runes:       []string{"R"},
+			Description: "Refresh users and roles",
+		},
+		ToggleRoles: Key{
+			Runes:       []string{"t"},
+			Description: "Toggle users and roles",
+		},
+		AddUser: Key{
+			Runes:       []string{"a"},
+			Description: "Create user",
+		},
+		DropUser: Key{
+			Runes:       []string{"D"},
+			Description: "Drop user",
+		},
+		ChangePassword: Key{
+			Runes:       []string{"p"},
+			Description: "Change password",
+		},
+		GrantRoles: Key{
+			Runes:       []string{"g"},
+			Description: "Grant roles",
+		},
+		RevokeRoles: Key{
+			Runes:       []string{"r"},
+			Description: "Revoke roles",
+		},
+		ShowPrivileges: Key{
+			Keys:        []string{"Enter"},
+			Description: "Show effective privileges",
+		},
+	}
+
+	k.Stats = StatsKeys{
+		Close: Key{
+			Keys:        []string{"Esc"},
+			Description: "Close stats",
+		},
+		Refresh: Key{
+			Runes:       []string{"R"},
+			Description: "Refresh stats",
+		},
+		JumpToCollection: Key{
+			Keys:        []string{"Enter"},
+			Description: "Jump to collection",
+		},
+	}
+
+	k.GridFS = GridFSKeys{
+		Close: Key{
+			Keys:        []string{"Esc"},
+			Description: "Close GridFS bucket",
+		},
+		Refresh: Key{
+			Runes:       []string{"R"},
+			Description: "Refresh files",
+		},
+		Download: Key{
+			Runes:       []string{"d"},
+			Description: "Download file",
+		},
+		Upload: Key{
+			Runes:       []string{"u"},
+			Description: "Upload file",
+		},
+		Delete: Key{
+			Runes:       []string{"D"},
+			Description: "Delete file",
+		},
+	}
+
 	k.AIQuery = AIQueryKeys{
 		ExitAIQuery: Key{
 			Keys:        []string{"Esc"},
@@ -617,6 +976,10 @@ func (k *KeyBindings) loadDefaults() {
 				Keys:        []string{"Ctrl+e"},
 				Description: "Edit full pipeline in external editor",
 			},
+			ExplainPipeline: Key{
+				Runes:       []string{"x"},
+				Description: "Explain pipeline",
+			},
 		},
 		Results: AggregationResultKeys{
 			FocusStages: Key{
@@ -92,6 +92,9 @@ type (
 		OpenNodeSymbol   Style `yaml:"openNodeSymbol"`
 		ClosedNodeSymbol Style `yaml:"closedNodeSymbol"`
 		LeafSymbol       Style `yaml:"leafSymbol"`
+		ViewSymbol       Style `yaml:"viewSymbol"`
+		TimeSeriesSymbol Style `yaml:"timeSeriesSymbol"`
+		BucketSymbol     Style `yaml:"bucketSymbol"`
 	}
 
 	// ContentStyle is a struct that contains all the styles for the content
@@ -103,6 +106,7 @@ type (
 		CellTextColor            Style `yaml:"cellTextColor"`
 		SelectedRowColor         Style `yaml:"selectedRowColor"`
 		MultiSelectedRowColor    Style `yaml:"multiSelectedRowColor"`
+		ChangedRowColor          Style `yaml:"changedRowColor"`
 	}
 
 	// DocPeekerStyle is a struct that contains all the styles for the json peeker
@@ -221,6 +225,9 @@ func (s *Styles) loadDefaults() {
 		OpenNodeSymbol:   "▼",
 		ClosedNodeSymbol: "▶",
 		LeafSymbol:       "◆",
+		ViewSymbol:       "◇",
+		TimeSeriesSymbol: "◷",
+		BucketSymbol:     "▤",
 	}
 
 	s.Content = ContentStyle{
@@ -231,6 +238,7 @@ func (s *Styles) loadDefaults() {
 		CellTextColor:            "#387D44",
 		SelectedRowColor:         "#4ADE80",
 		MultiSelectedRowColor:    "#2E6B4A",
+		ChangedRowColor:          "#854D0E",
 	}
 
 	s.DocPeeker = DocPeekerStyle{
@@ -323,6 +331,9 @@ func LoadStyles(styleName string, useBetterSymbols bool) (*Styles, error) {
 		styles.Databases.OpenNodeSymbol = defaultStyles.Databases.OpenNodeSymbol
 		styles.Databases.ClosedNodeSymbol = defaultStyles.Databases.ClosedNodeSymbol
 		styles.Databases.LeafSymbol = defaultStyles.Databases.LeafSymbol
+		styles.Databases.ViewSymbol = defaultStyles.Databases.ViewSymbol
+		styles.Databases.TimeSeriesSymbol = defaultStyles.Databases.TimeSeriesSymbol
+		styles.Databases.BucketSymbol = defaultStyles.Databases.BucketSymbol
 	}
 	return styles, nil
 }
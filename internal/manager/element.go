@@ -12,6 +12,7 @@ const (
 	StyleChanged           MessageType = "style_changed"
 	UpdateAutocompleteKeys MessageType = "update_autocomplete"
 	UpdateQueryBar         MessageType = "update_query_bar"
+	RunPipeline            MessageType = "run_pipeline"
 )
 
 type (
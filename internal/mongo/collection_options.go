@@ -0,0 +1,156 @@
+package mongo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection types as returned by listCollections
+const (
+	CollectionTypeCollection = "collection"
+	CollectionTypeView       = "view"
+	CollectionTypeTimeSeries = "timeseries"
+)
+
+// TimeSeriesGranularities are the values accepted by timeseries.granularity
+var TimeSeriesGranularities = []string{"seconds", "minutes", "hours"}
+
+// CollectionOptions describe a collection or a view to create
+type CollectionOptions struct {
+	// Type is one of CollectionTypeCollection, CollectionTypeTimeSeries or CollectionTypeView
+	Type string
+
+	Capped       bool
+	SizeInBytes  int64
+	MaxDocuments int64
+
+	Clustered bool
+
+	TimeField   string
+	MetaField   string
+	Granularity string
+	// ExpireAfterSeconds is used by time-series and clustered collections, 0 means no expiration
+	ExpireAfterSeconds int64
+
+	// CollationLocale sets the default collation, empty means simple binary comparison
+	CollationLocale string
+
+	// ViewOn is the source collection of a view
+	ViewOn string
+	// Pipeline is the view pipeline as a JSON array of stages
+	Pipeline string
+}
+
+// Validate checks if options are consistent with the collection type
+func (o CollectionOptions) Validate() error {
+	switch o.Type {
+	case CollectionTypeCollection:
+		if o.Capped && o.Clustered {
+			return fmt.Errorf("capped collection can't be clustered")
+		}
+		if o.Capped && o.SizeInBytes <= 0 {
+			return fmt.Errorf("capped collection requires size in bytes greater than 0")
+		}
+		if !o.Capped && (o.SizeInBytes != 0 || o.MaxDocuments != 0) {
+			return fmt.Errorf("size and max documents can be set only for capped collection")
+		}
+		if !o.Clustered && o.ExpireAfterSeconds != 0 {
+			return fmt.Errorf("expire after seconds can be set only for clustered or time-series collection")
+		}
+	case CollectionTypeTimeSeries:
+		if strings.TrimSpace(o.TimeField) == "" {
+			return fmt.Errorf("time-series collection requires time field")
+		}
+		if o.Granularity != "" && !slices.Contains(TimeSeriesGranularities, o.Granularity) {
+			return fmt.Errorf("invalid granularity %q, expected one of %v", o.Granularity, TimeSeriesGranularities)
+		}
+	case CollectionTypeView:
+		if strings.TrimSpace(o.ViewOn) == "" {
+			return fmt.Errorf("view requires source collection")
+		}
+	default:
+		return fmt.Errorf("unknown collection type %q", o.Type)
+	}
+	if o.ExpireAfterSeconds < 0 {
+		return fmt.Errorf("expire after seconds can't be negative")
+	}
+	return nil
+}
+
+// ToCreateOptions converts options of a collection into driver options
+func (o CollectionOptions) ToCreateOptions() *options.CreateCollectionOptions {
+	opts := options.CreateCollection()
+	if o.Capped {
+		opts.SetCapped(true).SetSizeInBytes(o.SizeInBytes)
+		if o.MaxDocuments > 0 {
+			opts.SetMaxDocuments(o.MaxDocuments)
+		}
+	}
+	if o.Clustered {
+		opts.SetClusteredIndex(bson.D{
+			{Key: "key", Value: bson.D{{Key: "_id", Value: 1}}},
+			{Key: "unique", Value: true},
+		})
+	}
+	if o.Type == CollectionTypeTimeSeries {
+		timeSeries := options.TimeSeries().SetTimeField(strings.TrimSpace(o.TimeField))
+		if metaField := strings.TrimSpace(o.MetaField); metaField != "" {
+			timeSeries.SetMetaField(metaField)
+		}
+		if o.Granularity != "" {
+			timeSeries.SetGranularity(o.Granularity)
+		}
+		opts.SetTimeSeriesOptions(timeSeries)
+	}
+	if o.ExpireAfterSeconds > 0 {
+		opts.SetExpireAfterSeconds(o.ExpireAfterSeconds)
+	}
+	if collation := o.collation(); collation != nil {
+		opts.SetCollation(collation)
+	}
+	return opts
+}
+
+// ToViewOptions converts options of a view into driver options
+func (o CollectionOptions) ToViewOptions() *options.CreateViewOptions {
+	opts := options.CreateView()
+	if collation := o.collation(); collation != nil {
+		opts.SetCollation(collation)
+	}
+	return opts
+}
+
+func (o CollectionOptions) collation() *options.Collation {
+	locale := strings.TrimSpace(o.CollationLocale)
+	if locale == "" {
+		return nil
+	}
+	return &options.Collation{Locale: locale}
+}
+
+// ParseViewPipeline parses a JSON array of stages keeping the order of fields,
+// empty text means a view without stages
+func ParseViewPipeline(text string) (mongo.Pipeline, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return mongo.Pipeline{}, nil
+	}
+
+	var wrapper struct {
+		Pipeline mongo.Pipeline `bson:"pipeline"`
+	}
+	if err := bson.UnmarshalExtJSON([]byte(`{"pipeline":`+text+`}`), false, &wrapper); err != nil {
+		return nil, fmt.Errorf("invalid pipeline, expected an array of stages: %w", err)
+	}
+	for i, stage := range wrapper.Pipeline {
+		if len(stage) != 1 || !strings.HasPrefix(stage[0].Key, "$") {
+			return nil, fmt.Errorf("invalid stage %d, expected a single stage operator", i+1)
+		}
+	}
+	return wrapper.Pipeline, nil
+}
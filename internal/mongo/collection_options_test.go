@@ -0,0 +1,111 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestCollectionOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    CollectionOptions
+		wantErr bool
+	}{
+		{name: "regular collection", opts: CollectionOptions{Type: CollectionTypeCollection}},
+		{name: "capped collection", opts: CollectionOptions{Type: CollectionTypeCollection, Capped: true, SizeInBytes: 1024, MaxDocuments: 10}},
+		{name: "capped without size", opts: CollectionOptions{Type: CollectionTypeCollection, Capped: true}, wantErr: true},
+		{name: "size without capped", opts: CollectionOptions{Type: CollectionTypeCollection, SizeInBytes: 1024}, wantErr: true},
+		{name: "capped and clustered", opts: CollectionOptions{Type: CollectionTypeCollection, Capped: true, SizeInBytes: 1024, Clustered: true}, wantErr: true},
+		{name: "clustered with expiration", opts: CollectionOptions{Type: CollectionTypeCollection, Clustered: true, ExpireAfterSeconds: 60}},
+		{name: "expiration without clustered", opts: CollectionOptions{Type: CollectionTypeCollection, ExpireAfterSeconds: 60}, wantErr: true},
+		{name: "time-series", opts: CollectionOptions{Type: CollectionTypeTimeSeries, TimeField: "ts", Granularity: "minutes"}},
+		{name: "time-series without time field", opts: CollectionOptions{Type: CollectionTypeTimeSeries}, wantErr: true},
+		{name: "time-series with invalid granularity", opts: CollectionOptions{Type: CollectionTypeTimeSeries, TimeField: "ts", Granularity: "days"}, wantErr: true},
+		{name: "time-series with negative expiration", opts: CollectionOptions{Type: CollectionTypeTimeSeries, TimeField: "ts", ExpireAfterSeconds: -1}, wantErr: true},
+		{name: "view", opts: CollectionOptions{Type: CollectionTypeView, ViewOn: "users"}},
+		{name: "view without source", opts: CollectionOptions{Type: CollectionTypeView}, wantErr: true},
+		{name: "unknown type", opts: CollectionOptions{Type: "table"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opts.Validate()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCollectionOptionsToCreateOptions(t *testing.T) {
+	t.Run("capped with collation", func(t *testing.T) {
+		opts := CollectionOptions{
+			Type:            CollectionTypeCollection,
+			Capped:          true,
+			SizeInBytes:     4096,
+			MaxDocuments:    100,
+			CollationLocale: "en",
+		}.ToCreateOptions()
+
+		assert.Equal(t, true, *opts.Capped)
+		assert.Equal(t, int64(4096), *opts.SizeInBytes)
+		assert.Equal(t, int64(100), *opts.MaxDocuments)
+		assert.Equal(t, "en", opts.Collation.Locale)
+		assert.Nil(t, opts.TimeSeriesOptions)
+		assert.Nil(t, opts.ClusteredIndex)
+	})
+
+	t.Run("clustered", func(t *testing.T) {
+		opts := CollectionOptions{Type: CollectionTypeCollection, Clustered: true, ExpireAfterSeconds: 3600}.ToCreateOptions()
+
+		assert.Equal(t, bson.D{
+			{Key: "key", Value: bson.D{{Key: "_id", Value: 1}}},
+			{Key: "unique", Value: true},
+		}, opts.ClusteredIndex)
+		assert.Equal(t, int64(3600), *opts.ExpireAfterSeconds)
+		assert.Nil(t, opts.Capped)
+		assert.Nil(t, opts.Collation)
+	})
+
+	t.Run("time-series", func(t *testing.T) {
+		opts := CollectionOptions{
+			Type:        CollectionTypeTimeSeries,
+			TimeField:   "ts",
+			MetaField:   "sensor",
+			Granularity: "hours",
+		}.ToCreateOptions()
+
+		require.NotNil(t, opts.TimeSeriesOptions)
+		assert.Equal(t, "ts", opts.TimeSeriesOptions.TimeField)
+		assert.Equal(t, "sensor", *opts.TimeSeriesOptions.MetaField)
+		assert.Equal(t, "hours", *opts.TimeSeriesOptions.Granularity)
+		assert.Nil(t, opts.ExpireAfterSeconds)
+	})
+}
+
+func TestParseViewPipeline(t *testing.T) {
+	pipeline, err := ParseViewPipeline(`[{"$match": {"active": true}}, {"$sort": {"b": 1, "a": -1}}]`)
+	require.NoError(t, err)
+	require.Len(t, pipeline, 2)
+	assert.Equal(t, "$match", pipeline[0][0].Key)
+	// order of sort fields must be preserved
+	assert.Equal(t, bson.D{{Key: "b", Value: int32(1)}, {Key: "a", Value: int32(-1)}}, pipeline[1][0].Value)
+
+	pipeline, err = ParseViewPipeline("  ")
+	require.NoError(t, err)
+	assert.Empty(t, pipeline)
+
+	_, err = ParseViewPipeline(`{"$match": {}}`)
+	assert.Error(t, err)
+
+	_, err = ParseViewPipeline(`[{"match": {}}]`)
+	assert.Error(t, err)
+
+	_, err = ParseViewPipeline(`[{"$match": {}, "$limit": 1}]`)
+	assert.Error(t, err)
+}
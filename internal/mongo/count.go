@@ -0,0 +1,157 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CountStrategy is the way documents matching the query are counted
+type CountStrategy string
+
+const (
+	// CountStrategyExact counts all matching documents
+	CountStrategyExact CountStrategy = "exact"
+	// CountStrategyEstimated uses the collection metadata when there is no filter,
+	// filtered queries are counted exactly
+	CountStrategyEstimated CountStrategy = "estimated"
+	// CountStrategyBounded counts matching documents up to the count limit
+	CountStrategyBounded CountStrategy = "bounded"
+	// CountStrategyDisabled doesn't count documents at all
+	CountStrategyDisabled CountStrategy = "disabled"
+)
+
+// DefaultCountLimit is the number of documents at which the bounded count stops
+const DefaultCountLimit int64 = 10000
+
+// CountKind tells how accurate the count of documents is
+type CountKind int
+
+const (
+	// CountExact is the number of all matching documents
+	CountExact CountKind = iota
+	// CountEstimated is taken from the collection metadata, it may be slightly off
+	CountEstimated
+	// CountAtLeast means counting stopped at the limit and more documents match
+	CountAtLeast
+	// CountUnknown means documents were not counted
+	CountUnknown
+)
+
+// countStrategy returns the count strategy of the connection, exact when it's not set
+func (d *Dao) countStrategy() CountStrategy {
+	if d.Config == nil {
+		return CountStrategyExact
+	}
+	switch strategy := CountStrategy(d.Config.Options.CountStrategy); strategy {
+	case CountStrategyEstimated, CountStrategyBounded, CountStrategyDisabled:
+		return strategy
+	}
+	return CountStrategyExact
+}
+
+func (d *Dao) countLimit() int64 {
+	if d.Config == nil || d.Config.Options.CountLimit == nil || *d.Config.Options.CountLimit <= 0 {
+		return DefaultCountLimit
+	}
+	return *d.Config.Options.CountLimit
+}
+
+// countDocuments counts documents matching the filter with the count strategy of the connection
+func (d *Dao) countDocuments(ctx context.Context, coll *mongo.Collection, filter primitive.M) (int64, CountKind, error) {
+	switch d.countStrategy() {
+	case CountStrategyDisabled:
+		return 0, CountUnknown, nil
+	case CountStrategyEstimated:
+		if len(filter) == 0 {
+			count, err := coll.EstimatedDocumentCount(ctx, &options.EstimatedDocumentCountOptions{MaxTime: d.maxTime()})
+			return count, CountEstimated, err
+		}
+	case CountStrategyBounded:
+		limit := d.countLimit()
+		// one document over the limit tells that there are more of them
+		bound := limit + 1
+		count, err := coll.CountDocuments(ctx, filter, &options.CountOptions{Limit: &bound, MaxTime: d.maxTime()})
+		if err != nil {
+			return 0, CountExact, err
+		}
+		count, kind := boundCount(count, limit)
+		return count, kind, nil
+	}
+
+	count, err := coll.CountDocuments(ctx, filter, &options.CountOptions{MaxTime: d.maxTime()})
+	return count, CountExact, err
+}
+
+// limitCount caps the count at the result limit, the count which reached
+// the limit is exact as no more documents are shown
+func limitCount(count int64, kind CountKind, resultLimit int64) (int64, CountKind) {
+	if resultLimit <= 0 || kind == CountUnknown {
+		return count, kind
+	}
+	if count >= resultLimit && kind != CountEstimated {
+		return resultLimit, CountExact
+	}
+	return min(count, resultLimit), kind
+}
+
+// boundCount caps the count at the limit, such count is marked as CountAtLeast
+func boundCount(count, limit int64) (int64, CountKind) {
+	if count > limit {
+		return limit, CountAtLeast
+	}
+	return count, CountExact
+}
+
+// FormatCount formats the count of documents by its kind, e.g. "~1200" or "10000+"
+func (c *CollectionState) FormatCount() string {
+	switch c.CountKind {
+	case CountEstimated:
+		return fmt.Sprintf("~%d", c.Count)
+	case CountAtLeast:
+		return fmt.Sprintf("%d+", c.Count)
+	case CountUnknown:
+		return "?"
+	}
+	return strconv.FormatInt(c.Count, 10)
+}
+
+// FormatPage formats the current page with the number of pages, e.g. "2/~5" or "2/1000+",
+// when documents are not counted only the current page is shown
+func (c *CollectionState) FormatPage() string {
+	page, total := c.GetCurrentPage(), c.GetTotalPages()
+	switch c.CountKind {
+	case CountEstimated:
+		return fmt.Sprintf("%d/~%d", page, total)
+	case CountAtLeast:
+		return fmt.Sprintf("%d/%d+", page, total)
+	case CountUnknown:
+		return strconv.FormatInt(page, 10)
+	}
+	return fmt.Sprintf("%d/%d", page, total)
+}
+
+// HasNextPage returns true when documents after the current page may exist,
+// without the exact count a full page means that there may be more of them
+func (c *CollectionState) HasNextPage() bool {
+	if c.ResultLimit > 0 && c.Skip+c.Limit >= c.ResultLimit {
+		return false
+	}
+	if c.Skip+c.Limit < c.Count {
+		return true
+	}
+	if c.CountKind == CountExact {
+		return false
+	}
+	return int64(len(c.docs)) >= c.Limit
+}
+
+// HasLastPage returns true when the last page can be found, which needs the exact count,
+// with the estimated one the last page could miss documents or be empty
+func (c *CollectionState) HasLastPage() bool {
+	return c.CountKind == CountExact && c.Skip+c.Limit < c.Count
+}
@@ -0,0 +1,149 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCountStrategy(t *testing.T) {
+	newDao := func(options config.MongoOptions) *Dao {
+		return &Dao{Config: &config.MongoConfig{Options: options}}
+	}
+	limit := int64(500)
+
+	assert.Equal(t, CountStrategyExact, (&Dao{}).countStrategy())
+	assert.Equal(t, CountStrategyExact, newDao(config.MongoOptions{}).countStrategy())
+	assert.Equal(t, CountStrategyExact, newDao(config.MongoOptions{CountStrategy: "sometimes"}).countStrategy())
+	assert.Equal(t, CountStrategyBounded, newDao(config.MongoOptions{CountStrategy: "bounded"}).countStrategy())
+	assert.Equal(t, CountStrategyDisabled, newDao(config.MongoOptions{CountStrategy: "disabled"}).countStrategy())
+
+	assert.Equal(t, DefaultCountLimit, newDao(config.MongoOptions{}).countLimit())
+	assert.Equal(t, limit, newDao(config.MongoOptions{CountLimit: &limit}).countLimit())
+}
+
+func TestBoundCount(t *testing.T) {
+	count, kind := boundCount(10, 10)
+	assert.Equal(t, int64(10), count)
+	assert.Equal(t, CountExact, kind)
+
+	count, kind = boundCount(11, 10)
+	assert.Equal(t, int64(10), count)
+	assert.Equal(t, CountAtLeast, kind)
+}
+
+func TestLimitCount(t *testing.T) {
+	tests := []struct {
+		name        string
+		count       int64
+		kind        CountKind
+		resultLimit int64
+		wantCount   int64
+		wantKind    CountKind
+	}{
+		{"no result limit", 50, CountExact, 0, 50, CountExact},
+		{"count under the limit", 5, CountExact, 20, 5, CountExact},
+		{"count over the limit", 50, CountExact, 20, 20, CountExact},
+		{"bounded count over the limit", 10000, CountAtLeast, 20, 20, CountExact},
+		{"estimated count over the limit", 50, CountEstimated, 20, 20, CountEstimated},
+		{"unknown count", 0, CountUnknown, 20, 0, CountUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			count, kind := limitCount(tt.count, tt.kind, tt.resultLimit)
+			assert.Equal(t, tt.wantCount, count)
+			assert.Equal(t, tt.wantKind, kind)
+		})
+	}
+}
+
+func TestCollectionState_CountFormatting(t *testing.T) {
+	state := NewCollectionState("db", "coll")
+	state.Limit = 50
+	state.Skip = 50
+	state.Count = 10000
+
+	tests := []struct {
+		kind  CountKind
+		count string
+		page  string
+	}{
+		{kind: CountExact, count: "10000", page: "2/200"},
+		{kind: CountEstimated, count: "~10000", page: "2/~200"},
+		{kind: CountAtLeast, count: "10000+", page: "2/200+"},
+		{kind: CountUnknown, count: "?", page: "2"},
+	}
+	for _, tt := range tests {
+		state.CountKind = tt.kind
+		assert.Equal(t, tt.count, state.FormatCount())
+		assert.Equal(t, tt.page, state.FormatPage())
+	}
+}
+
+func TestCollectionState_PageAvailability(t *testing.T) {
+	fullPage := []primitive.M{{"_id": 1}, {"_id": 2}}
+
+	t.Run("Exact count ends at the last page", func(t *testing.T) {
+		state := NewCollectionState("db", "coll")
+		state.Limit = 2
+		state.Count = 4
+		state.PopulateDocs(fullPage)
+		assert.True(t, state.HasNextPage())
+		assert.True(t, state.HasLastPage())
+
+		state.Skip = 2
+		assert.False(t, state.HasNextPage())
+		assert.False(t, state.HasLastPage())
+	})
+
+	t.Run("Estimated count has no last page", func(t *testing.T) {
+		state := NewCollectionState("db", "coll")
+		state.Limit = 2
+		state.Count = 4
+		state.CountKind = CountEstimated
+		state.PopulateDocs(fullPage)
+		assert.True(t, state.HasNextPage())
+		assert.False(t, state.HasLastPage())
+	})
+
+	t.Run("Result limit ends the pages", func(t *testing.T) {
+		state := NewCollectionState("db", "coll")
+		state.Limit = 2
+		state.CountKind = CountUnknown
+		state.ResultLimit = 3
+		state.PopulateDocs(fullPage)
+		assert.True(t, state.HasNextPage())
+
+		state.Skip = 2
+		assert.False(t, state.HasNextPage())
+	})
+
+	t.Run("Bounded count continues while pages are full", func(t *testing.T) {
+		state := NewCollectionState("db", "coll")
+		state.Limit = 2
+		state.Count = 4
+		state.CountKind = CountAtLeast
+		state.Skip = 4
+		state.PopulateDocs(fullPage)
+		assert.True(t, state.HasNextPage())
+		assert.False(t, state.HasLastPage())
+
+		state.PopulateDocs(fullPage[:1])
+		assert.False(t, state.HasNextPage())
+	})
+
+	t.Run("Unknown count continues while pages are full", func(t *testing.T) {
+		state := NewCollectionState("db", "coll")
+		state.Limit = 2
+		state.CountKind = CountUnknown
+		state.PopulateDocs(fullPage)
+		assert.True(t, state.HasNextPage())
+		assert.False(t, state.HasLastPage())
+
+		state.PopulateDocs(nil)
+		assert.False(t, state.HasNextPage())
+	})
+}
@@ -2,11 +2,14 @@ package mongo
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
 	"slices"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/kopecmaciej/vi-mongo/internal/config"
@@ -15,18 +18,26 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type Dao struct {
 	client *mongo.Client
 	Config *config.MongoConfig
+	// queryShapes are queries run from the query bar, used for index recommendations
+	queryShapes *QueryShapeLog
+
+	txMu sync.Mutex
+	// transaction is the active transaction, nil when writes are applied immediately
+	transaction *Transaction
 }
 
 func NewDao(client *mongo.Client, config *config.MongoConfig) *Dao {
 	return &Dao{
-		client: client,
-		Config: config,
+		client:      client,
+		Config:      config,
+		queryShapes: NewQueryShapeLog(),
 	}
 }
 
@@ -39,25 +50,166 @@ func (d *Dao) Ping(ctx context.Context) error {
 	return nil
 }
 
-func (d *Dao) GetServerStatus(ctx context.Context) (*ServerStatus, error) {
-	var status ServerStatus
-	err := d.client.Database("admin").RunCommand(ctx, primitive.D{{Key: "serverStatus", Value: 1}}).Decode(&status)
+// GetServerMetrics returns the current values of serverStatus together
+// with the replica set status when the server is a replica set member
+func (d *Dao) GetServerMetrics(ctx context.Context) (ServerMetrics, error) {
+	status, err := d.runAdminCommand(ctx, "serverStatus", 1)
 	if err != nil {
-		log.Error().Err(err).Msg("Failed to get server status")
-		return nil, fmt.Errorf("failed to get server status: %w", err)
+		return ServerMetrics{}, fmt.Errorf("failed to get server status: %w", err)
 	}
+	metrics := ParseServerMetrics(status, time.Now())
 
-	isMaster, err := d.runAdminCommand(ctx, "isMaster", 1)
+	// serverStatus reports the role only for replica set members, hello knows
+	// also about standalone servers and mongos
+	hello, err := d.hello(ctx)
 	if err != nil {
-		return nil, err
+		return ServerMetrics{}, err
 	}
-	var ok bool
-	status.Repl.IsMaster, ok = isMaster["ismaster"].(bool)
-	if !ok {
-		status.Repl.IsMaster = false
+	metrics.Role = ServerRole(hello)
+
+	replicaSet, err := d.replicaSetStatus(ctx, hello)
+	if err == nil {
+		metrics.ReplicaSet = &replicaSet
+	}
+
+	return metrics, nil
+}
+
+// GetReplicaSetStatus returns the replica set topology,
+// ErrNotReplicaSet is returned for standalone servers and mongos
+func (d *Dao) GetReplicaSetStatus(ctx context.Context) (ReplicaSetStatus, error) {
+	hello, err := d.hello(ctx)
+	if err != nil {
+		return ReplicaSetStatus{}, err
+	}
+	return d.replicaSetStatus(ctx, hello)
+}
+
+func (d *Dao) replicaSetStatus(ctx context.Context, hello primitive.M) (ReplicaSetStatus, error) {
+	if _, ok := hello["setName"]; !ok {
+		return ReplicaSetStatus{}, ErrNotReplicaSet
+	}
+
+	status, err := d.runAdminCommand(ctx, "replSetGetStatus", 1)
+	if err != nil {
+		return ReplicaSetStatus{}, fmt.Errorf("failed to get replica set status: %w", err)
+	}
+
+	return ParseReplicaSetStatus(status, hello), nil
+}
+
+// hello returns the server's role in the deployment
+func (d *Dao) hello(ctx context.Context) (primitive.M, error) {
+	hello, err := d.runAdminCommand(ctx, "hello", 1)
+	if err != nil {
+		// hello is available since MongoDB 4.4.2, older servers know only isMaster
+		hello, err = d.runAdminCommand(ctx, "isMaster", 1)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run hello: %w", err)
+		}
+	}
+	return hello, nil
+}
+
+// IsMongos checks if the client is connected to a mongos router
+func (d *Dao) IsMongos(ctx context.Context) (bool, error) {
+	hello, err := d.hello(ctx)
+	if err != nil {
+		return false, err
+	}
+	return IsMongosHello(hello), nil
+}
+
+// GetShardingStatus returns shards, sharded collections with their chunk
+// distribution and the balancer state, ErrNotMongos is returned when
+// the client is not connected to mongos
+func (d *Dao) GetShardingStatus(ctx context.Context) (ShardingStatus, error) {
+	isMongos, err := d.IsMongos(ctx)
+	if err != nil {
+		return ShardingStatus{}, err
+	}
+	if !isMongos {
+		return ShardingStatus{}, ErrNotMongos
+	}
+
+	config := d.client.Database("config")
+
+	shardsCursor, err := config.Collection("shards").Find(ctx, primitive.M{}, options.Find().SetSort(primitive.M{"_id": 1}))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list shards")
+		return ShardingStatus{}, fmt.Errorf("failed to list shards: %w", err)
+	}
+	var rawShards []primitive.M
+	if err := shardsCursor.All(ctx, &rawShards); err != nil {
+		log.Error().Err(err).Msg("Failed to decode shards")
+		return ShardingStatus{}, fmt.Errorf("failed to decode shards: %w", err)
+	}
+
+	colls, err := d.getConfigCollections(ctx)
+	if err != nil {
+		return ShardingStatus{}, err
+	}
+
+	chunksCursor, err := config.Collection("chunks").Aggregate(ctx, chunkCountPipeline)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to count chunks")
+		return ShardingStatus{}, fmt.Errorf("failed to count chunks: %w", err)
+	}
+	var counts []chunkCount
+	if err := chunksCursor.All(ctx, &counts); err != nil {
+		log.Error().Err(err).Msg("Failed to decode chunk counts")
+		return ShardingStatus{}, fmt.Errorf("failed to decode chunk counts: %w", err)
+	}
+
+	balancer, err := d.runAdminCommand(ctx, "balancerStatus", 1)
+	if err != nil {
+		return ShardingStatus{}, fmt.Errorf("failed to get balancer status: %w", err)
+	}
+
+	status := ShardingStatus{
+		Collections: buildShardedCollections(colls, counts),
+		Balancer:    ParseBalancerStatus(balancer),
+	}
+	for _, raw := range rawShards {
+		status.Shards = append(status.Shards, ParseShard(raw))
+	}
+	return status, nil
+}
+
+// GetShardKeys returns shard keys of sharded collections by namespace,
+// the map is empty when the client is not connected to mongos
+func (d *Dao) GetShardKeys(ctx context.Context) (map[string]string, error) {
+	shardKeys := map[string]string{}
+
+	isMongos, err := d.IsMongos(ctx)
+	if err != nil || !isMongos {
+		return shardKeys, err
 	}
 
-	return &status, nil
+	colls, err := d.getConfigCollections(ctx)
+	if err != nil {
+		return shardKeys, err
+	}
+	for _, coll := range colls {
+		if !coll.Dropped {
+			shardKeys[coll.Id] = FormatShardKey(coll.Key)
+		}
+	}
+	return shardKeys, nil
+}
+
+func (d *Dao) getConfigCollections(ctx context.Context) ([]configCollection, error) {
+	cursor, err := d.client.Database("config").Collection("collections").Find(ctx, primitive.M{})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list sharded collections")
+		return nil, fmt.Errorf("failed to list sharded collections: %w", err)
+	}
+	var colls []configCollection
+	if err := cursor.All(ctx, &colls); err != nil {
+		log.Error().Err(err).Msg("Failed to decode sharded collections")
+		return nil, fmt.Errorf("failed to decode sharded collections: %w", err)
+	}
+	return colls, nil
 }
 
 func (d *Dao) GetLiveSessions(ctx context.Context) (int64, error) {
@@ -71,6 +223,93 @@ func (d *Dao) GetLiveSessions(ctx context.Context) (int64, error) {
 	return int64(len(sessions)), nil
 }
 
+// GetCurrentOperations returns active operations reported by currentOp
+func (d *Dao) GetCurrentOperations(ctx context.Context) ([]Operation, error) {
+	results, err := d.runAdminCommand(ctx, "currentOp", 1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current operations: %w", err)
+	}
+
+	inprog, ok := results["inprog"].(primitive.A)
+	if !ok {
+		return nil, fmt.Errorf("unexpected currentOp response")
+	}
+
+	return ParseOperations(inprog), nil
+}
+
+// KillOperation terminates the operation with the given opid
+func (d *Dao) KillOperation(ctx context.Context, opId any) error {
+	command := primitive.D{{Key: "killOp", Value: 1}, {Key: "op", Value: opId}}
+	err := d.client.Database("admin").RunCommand(ctx, command).Err()
+	if err != nil {
+		log.Error().Err(err).Interface("opid", opId).Msg("Failed to kill operation")
+		return fmt.Errorf("failed to kill operation %v: %w", opId, err)
+	}
+
+	log.Debug().Msgf("Killed operation %v", opId)
+	return nil
+}
+
+// GetProfilingStatus returns the current profiling level and slowms of the database
+func (d *Dao) GetProfilingStatus(ctx context.Context, db string) (ProfilingStatus, error) {
+	result := primitive.M{}
+	err := d.client.Database(db).RunCommand(ctx, primitive.D{{Key: "profile", Value: -1}}).Decode(&result)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Msg("Failed to get profiling status")
+		return ProfilingStatus{}, fmt.Errorf("failed to get profiling status: %w", err)
+	}
+
+	return ProfilingStatus{
+		Level:  int32(toInt64(result["was"])),
+		SlowMs: toInt64(result["slowms"]),
+	}, nil
+}
+
+// SetProfilingLevel sets the profiling level and slowms threshold of the database
+func (d *Dao) SetProfilingLevel(ctx context.Context, db string, level int32, slowMs int64) error {
+	command := primitive.D{{Key: "profile", Value: level}, {Key: "slowms", Value: slowMs}}
+	err := d.client.Database(db).RunCommand(ctx, command).Err()
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Int32("level", level).Msg("Failed to set profiling level")
+		return fmt.Errorf("failed to set profiling level: %w", err)
+	}
+
+	log.Debug().Msgf("Profiling level of %s set to %d with slowms %d", db, level, slowMs)
+	return nil
+}
+
+// GetProfileEntries returns the slowest operations stored in system.profile
+func (d *Dao) GetProfileEntries(ctx context.Context, db string, limit int64) ([]ProfileEntry, error) {
+	opts := options.Find().SetSort(primitive.D{{Key: "millis", Value: -1}}).SetLimit(limit)
+	cursor, err := d.client.Database(db).Collection("system.profile").Find(ctx, primitive.M{}, opts)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Msg("Failed to read system.profile")
+		return nil, fmt.Errorf("failed to read system.profile: %w", err)
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to close cursor")
+		}
+	}()
+
+	entries := []ProfileEntry{}
+	for cursor.Next(ctx) {
+		var doc primitive.M
+		if err := cursor.Decode(&doc); err != nil {
+			log.Error().Err(err).Str("db", db).Msg("Failed to decode system.profile")
+			return nil, fmt.Errorf("failed to decode system.profile: %w", err)
+		}
+		entry := ParseProfileEntry(doc)
+		entry.Sort = ParseProfiledSort(cursor.Current)
+		entries = append(entries, entry)
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read system.profile: %w", err)
+	}
+	return entries, nil
+}
+
 func (d *Dao) ListDbsWithCollections(ctx context.Context, nameRegex string) ([]DBsWithCollections, error) {
 	dbCollMap := []DBsWithCollections{}
 
@@ -89,83 +328,368 @@ func (d *Dao) ListDbsWithCollections(ctx context.Context, nameRegex string) ([]D
 	for _, dbName := range dbNames {
 		listCollOptions := options.ListCollections().SetAuthorizedCollections(*d.Config.GetOptions().AuthorizedCollections)
 
-		collNames, err := d.client.Database(dbName).ListCollectionNames(ctx, primitive.M{}, listCollOptions)
+		specs, err := d.client.Database(dbName).ListCollectionSpecifications(ctx, primitive.M{}, listCollOptions)
 		if err != nil {
 			log.Error().Err(err).Str("database", dbName).Msg("Failed to list collections")
 			continue
 		}
 
+		collNames := make([]string, 0, len(specs))
+		collTypes := make(map[string]string, len(specs))
+		for _, spec := range specs {
+			collNames = append(collNames, spec.Name)
+			collTypes[spec.Name] = spec.Type
+		}
+		collNames = GroupGridFSBuckets(collNames, collTypes)
 		slices.Sort(collNames)
 
-		dbCollMap = append(dbCollMap, DBsWithCollections{DB: dbName, Collections: collNames})
+		dbCollMap = append(dbCollMap, DBsWithCollections{DB: dbName, Collections: collNames, CollectionTypes: collTypes})
 	}
 
 	return dbCollMap, nil
 }
 
-func (d *Dao) ListDocuments(ctx context.Context, state *CollectionState, filter primitive.M, sort primitive.M,
-	projection primitive.M, countCallback func(int64)) ([]primitive.M, error) {
+// ListDocuments returns documents of the page query built by CollectionState.PageQuery,
+// filter and sort of the user query are used for counting and query shapes. The page query
+// is passed by value, so it can run in the background while the state changes
+func (d *Dao) ListDocuments(ctx context.Context, db, collName string, filter primitive.M, sort primitive.D,
+	query PageQuery, projection primitive.M, countCallback func(count int64, kind CountKind)) ([]primitive.M, error) {
 
-	coll := d.client.Database(state.Db).Collection(state.Coll)
+	coll := d.client.Database(db).Collection(collName)
 
-	options := options.FindOptions{
-		Limit:      &state.Limit,
-		Skip:       &state.Skip,
-		Sort:       sort,
+	findOptions := options.FindOptions{
+		Limit:      &query.Limit,
+		Skip:       &query.Skip,
+		Sort:       query.Sort,
 		Projection: projection,
+		MaxTime:    d.maxTime(),
 	}
 
-	cursor, err := coll.Find(ctx, filter, &options)
+	// documents are read in the transaction to see its pending writes,
+	// counting runs concurrently so it stays outside of the session
+	txCtx, tx, err := d.txContext(ctx, true)
 	if err != nil {
-		log.Error().Err(err).Str("db", state.Db).Str("collection", state.Coll).Msg("Failed to find documents")
-		return nil, fmt.Errorf("failed to find documents: %w", err)
+		return nil, err
+	}
+	defer tx.release()
+	cursor, err := coll.Find(txCtx, query.Filter, &findOptions)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", collName).Msg("Failed to find documents")
+		return nil, fmt.Errorf("failed to find documents: %w", d.wrapMaxTimeError(d.txError(txCtx, tx, err)))
 	}
 	defer func() {
-		if err := cursor.Close(ctx); err != nil {
+		if err := cursor.Close(txCtx); err != nil {
 			log.Error().Err(err).Msg("Failed to close cursor")
 		}
 	}()
 
 	var documents []primitive.M
-	err = cursor.All(ctx, &documents)
-	if err != nil {
-		log.Error().Err(err).Str("db", state.Db).Str("collection", state.Coll).Msg("Failed to decode documents")
-		return nil, fmt.Errorf("failed to decode documents: %w", err)
+	for cursor.Next(txCtx) {
+		doc, err := decodeDocument(cursor.Current)
+		if err != nil {
+			log.Error().Err(err).Str("db", db).Str("collection", collName).Msg("Failed to decode documents")
+			return nil, fmt.Errorf("failed to decode documents: %w", err)
+		}
+		documents = append(documents, doc)
 	}
 	if err := cursor.Err(); err != nil {
-		return nil, err
+		return nil, d.wrapMaxTimeError(d.txError(txCtx, tx, err))
+	}
+	if query.Reversed {
+		slices.Reverse(documents)
 	}
+	d.queryShapes.Record(db+"."+collName, filter, sort)
 
 	go func() {
-		count, err := coll.CountDocuments(ctx, filter)
+		count, kind, err := d.countDocuments(ctx, coll, filter)
 		if err != nil {
-			log.Error().Err(err).Str("db", state.Db).Str("collection", state.Coll).Msg("Failed to count documents")
+			log.Error().Err(err).Str("db", db).Str("collection", collName).Msg("Failed to count documents")
 			return
 		}
+		count, kind = limitCount(count, kind, query.ResultLimit)
 
 		if countCallback != nil {
-			countCallback(count)
+			countCallback(count, kind)
 		}
 	}()
 
 	return documents, nil
 }
 
-func (d *Dao) GetDocument(ctx context.Context, db string, coll string, id primitive.ObjectID) (primitive.M, error) {
-	var document primitive.M
-	err := d.client.Database(db).Collection(coll).FindOne(ctx, primitive.M{"_id": id}).Decode(&document)
+// ExportDocuments streams every document matching the filter into w using given format,
+// progress is called after each written document together with the number of all matching documents
+func (d *Dao) ExportDocuments(ctx context.Context, db, coll string, filter primitive.M, sort primitive.D, projection primitive.M,
+	format FileFormat, w io.Writer, progress func(written, total int64)) (int64, error) {
+
+	collection := d.client.Database(db).Collection(coll)
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to count documents")
+		return 0, fmt.Errorf("failed to count documents: %w", err)
+	}
+
+	var columns []string
+	if format == FormatCSV {
+		columns, err = d.collectExportColumns(ctx, collection, filter, projection)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	writer, err := newDocumentWriter(w, format, columns)
+	if err != nil {
+		return 0, err
+	}
+
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(sort).SetProjection(projection))
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to find documents")
+		return 0, fmt.Errorf("failed to find documents: %w", err)
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to close cursor")
+		}
+	}()
+
+	var written int64
+	for cursor.Next(ctx) {
+		if err := writer.WriteDocument(cursor.Current); err != nil {
+			log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to write document")
+			return written, fmt.Errorf("failed to write document: %w", err)
+		}
+		written++
+		if progress != nil {
+			progress(written, total)
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to export documents")
+		return written, fmt.Errorf("failed to export documents: %w", err)
+	}
+
+	if err := writer.Close(); err != nil {
+		return written, fmt.Errorf("failed to finish export: %w", err)
+	}
+
+	return written, nil
+}
+
+// collectExportColumns runs through all matching documents to find every
+// flattened field path, CSV header has to be known before first row is written
+func (d *Dao) collectExportColumns(ctx context.Context, collection *mongo.Collection, filter, projection primitive.M) ([]string, error) {
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(projection))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find documents: %w", err)
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to close cursor")
+		}
+	}()
+
+	columns := make(map[string]struct{})
+	for cursor.Next(ctx) {
+		var doc primitive.D
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to decode document: %w", err)
+		}
+		for column := range FlattenDocument(doc) {
+			columns[column] = struct{}{}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to collect columns: %w", err)
+	}
+
+	return flattenedColumns(columns), nil
+}
+
+// ImportDocuments reads documents from r and inserts them in batches, documents that
+// can't be parsed or inserted are counted as failed and described in the result.
+// progress is called after every batch
+func (d *Dao) ImportDocuments(ctx context.Context, db, coll string, r io.Reader, opts ImportOptions,
+	progress func(ImportResult)) (ImportResult, error) {
+
+	result := ImportResult{}
+	reader, err := newDocumentReader(r, opts)
+	if err != nil {
+		return result, err
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultImportBatchSize
+	}
+
+	collection := d.client.Database(db).Collection(coll)
+	batch := make([]primitive.M, 0, batchSize)
+	rows := make([]int, 0, batchSize)
+
+	flush := func() (bool, error) {
+		if len(batch) == 0 {
+			return false, nil
+		}
+		stopped, err := d.importBatch(ctx, collection, batch, rows, opts, &result)
+		batch, rows = batch[:0], rows[:0]
+		if progress != nil {
+			progress(result)
+		}
+		return stopped, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		doc, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *rowError
+		if errors.As(err, &rowErr) {
+			result.Read++
+			result.addFailure(1, rowErr.Error())
+			if opts.Ordered {
+				// documents read before the failed one should still be inserted
+				_, err := flush()
+				return result, err
+			}
+			continue
+		}
+		if err != nil {
+			log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to read documents")
+			return result, fmt.Errorf("failed to read documents: %w", err)
+		}
+
+		result.Read++
+		batch = append(batch, doc)
+		rows = append(rows, reader.Row())
+		if len(batch) >= batchSize {
+			stopped, err := flush()
+			if err != nil || stopped {
+				return result, err
+			}
+		}
+	}
+
+	_, err = flush()
+	return result, err
+}
+
+// importBatch writes a single batch and updates the result, it returns true if
+// import should be stopped, which happens only in ordered mode
+func (d *Dao) importBatch(ctx context.Context, collection *mongo.Collection, batch []primitive.M, rows []int,
+	opts ImportOptions, result *ImportResult) (bool, error) {
+
+	var err error
+	var succeeded int64
+
+	if opts.UpsertById {
+		models := make([]mongo.WriteModel, len(batch))
+		for i, doc := range batch {
+			if id, ok := doc["_id"]; ok {
+				models[i] = mongo.NewReplaceOneModel().SetFilter(primitive.M{"_id": id}).SetReplacement(doc).SetUpsert(true)
+			} else {
+				models[i] = mongo.NewInsertOneModel().SetDocument(doc)
+			}
+		}
+		var res *mongo.BulkWriteResult
+		res, err = collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(opts.Ordered))
+		var bulkErr mongo.BulkWriteException
+		if res != nil && (err == nil || errors.As(err, &bulkErr)) {
+			result.Inserted += res.InsertedCount + res.UpsertedCount
+			result.Updated += res.MatchedCount
+			succeeded = res.InsertedCount + res.UpsertedCount + res.MatchedCount
+		}
+	} else {
+		docs := make([]any, len(batch))
+		for i, doc := range batch {
+			docs[i] = doc
+		}
+		_, err = collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(opts.Ordered))
+		succeeded = insertedCount(len(batch), opts.Ordered, err)
+		result.Inserted += succeeded
+	}
+
+	if err == nil {
+		return false, nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) {
+		log.Error().Err(err).Str("collection", collection.Name()).Msg("Failed to import documents")
+		return true, fmt.Errorf("failed to import documents: %w", err)
+	}
+
+	for _, writeErr := range bulkErr.WriteErrors {
+		row := writeErr.Index + 1
+		if writeErr.Index < len(rows) {
+			row = rows[writeErr.Index]
+		}
+		result.addFailure(1, fmt.Sprintf("row %d: %s", row, writeErr.Message))
+	}
+	// in ordered mode documents after the failed one are not written at all
+	if notWritten := int64(len(batch)) - succeeded - int64(len(bulkErr.WriteErrors)); notWritten > 0 {
+		result.addFailure(notWritten, fmt.Sprintf("%d documents not written after the first error", notWritten))
+	}
+	if bulkErr.WriteConcernError != nil {
+		result.addError(bulkErr.WriteConcernError.Message)
+	}
+
+	return opts.Ordered, nil
+}
+
+// insertedCount returns the number of documents of the batch written by InsertMany,
+// after errors other than BulkWriteException (e.g. network error or cancelled context)
+// it's unknown which documents were written, so none of them is counted
+func insertedCount(batchSize int, ordered bool, err error) int64 {
+	if err == nil {
+		return int64(batchSize)
+	}
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) {
+		return 0
+	}
+	if ordered && len(bulkErr.WriteErrors) > 0 {
+		return int64(bulkErr.WriteErrors[0].Index)
+	}
+	return int64(batchSize - len(bulkErr.WriteErrors))
+}
+
+func (d *Dao) GetDocument(ctx context.Context, db string, coll string, id any) (primitive.M, error) {
+	ctx, tx, err := d.txContext(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.release()
+	raw, err := d.client.Database(db).Collection(coll).FindOne(ctx, primitive.M{"_id": id}).Raw()
 	if err != nil {
-		log.Error().Err(err).Str("db", db).Str("collection", coll).Str("id", id.Hex()).Msg("Failed to get document")
-		return nil, fmt.Errorf("failed to get document: %w", err)
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Interface("id", id).Msg("Failed to get document")
+		return nil, fmt.Errorf("failed to get document: %w", d.txError(ctx, tx, err))
+	}
+	document, err := decodeDocument(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode document: %w", err)
 	}
 	return document, nil
 }
 
 func (d *Dao) InsetDocument(ctx context.Context, db string, coll string, document primitive.M) (any, error) {
+	ctx, tx, err := d.txContext(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.release()
 	res, err := d.client.Database(db).Collection(coll).InsertOne(ctx, document)
 	if err != nil {
 		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to insert document")
-		return nil, fmt.Errorf("failed to insert document: %w", err)
+		return nil, fmt.Errorf("failed to insert document: %w", d.txError(ctx, tx, err))
+	}
+	if tx != nil {
+		tx.record(PendingInsert, db, coll, res.InsertedID, "_id: "+StringifyId(res.InsertedID))
 	}
 
 	log.Debug().Msgf("Document inserted, document: %v, db: %v, collection: %v", document, db, coll)
@@ -201,101 +725,474 @@ func (d *Dao) UpdateDocument(ctx context.Context, db string, coll string, id any
 		return nil
 	}
 
+	ctx, tx, err := d.txContext(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer tx.release()
 	updated, err := d.client.Database(db).Collection(coll).UpdateOne(ctx, primitive.M{"_id": id}, update)
 	if err != nil {
 		log.Error().Err(err).Str("db", db).Str("collection", coll).Interface("id", id).Msg("Failed to update document")
-		return fmt.Errorf("failed to update document: %w", err)
+		return fmt.Errorf("failed to update document: %w", d.txError(ctx, tx, err))
 	}
 
 	if updated.MatchedCount == 0 {
 		log.Error().Str("db", db).Str("collection", coll).Interface("id", id).Msg("No document found to update")
 		return mongo.ErrNoDocuments
 	}
+	if tx != nil {
+		tx.record(PendingUpdate, db, coll, id, "_id: "+StringifyId(id))
+	}
 
 	log.Debug().Msgf("Document updated, id: %v, document: %v, db: %v, collection: %v", id, document, db, coll)
 
 	return nil
 }
 
-func (d *Dao) DeleteDocument(ctx context.Context, db string, coll string, id any) error {
-	deleted, err := d.client.Database(db).Collection(coll).DeleteOne(ctx, primitive.M{"_id": id})
-	if err != nil {
-		log.Error().Err(err).Str("db", db).Str("collection", coll).Interface("id", id).Msg("Failed to delete document")
-		return fmt.Errorf("failed to delete document: %w", err)
+func (d *Dao) CountDocuments(ctx context.Context, db, coll string, filter primitive.M) (int64, error) {
+	ctx, tx, err := d.txContext(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.release()
+	count, err := d.client.Database(db).Collection(coll).CountDocuments(ctx, filter)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to count documents")
+		return 0, fmt.Errorf("failed to count documents: %w", d.txError(ctx, tx, err))
+	}
+	return count, nil
+}
+
+// PreviewUpdate returns up to limit documents matching the filter as they are
+// now and as they would look after the update, nothing is written to the collection
+func (d *Dao) PreviewUpdate(ctx context.Context, db, coll string, filter primitive.M, update any, limit int64) (UpdatePreview, error) {
+	var preview UpdatePreview
+
+	afterStages, err := UpdatePreviewStages(update)
+	if err != nil {
+		if !errors.Is(err, ErrPreviewUnsupported) {
+			return preview, err
+		}
+		preview.Unavailable = err.Error()
+	}
+
+	facet := primitive.D{{Key: "before", Value: primitive.A{primitive.D{{Key: "$match", Value: primitive.M{}}}}}}
+	if preview.Unavailable == "" {
+		facet = append(facet, primitive.E{Key: "after", Value: afterStages})
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$limit", Value: limit}},
+		{{Key: "$facet", Value: facet}},
+	}
+
+	ctx, tx, err := d.txContext(ctx, false)
+	if err != nil {
+		return preview, err
+	}
+	defer tx.release()
+	cursor, err := d.client.Database(db).Collection(coll).Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to preview update")
+		return preview, fmt.Errorf("failed to preview update: %w", d.txError(ctx, tx, err))
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to close cursor")
+		}
+	}()
+
+	var results []struct {
+		Before []primitive.M `bson:"before"`
+		After  []primitive.M `bson:"after"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return preview, fmt.Errorf("failed to decode preview: %w", err)
+	}
+	if len(results) > 0 {
+		preview.Before = results[0].Before
+		preview.After = results[0].After
+	}
+
+	return preview, nil
+}
+
+// UpdateManyDocuments applies the update to every document matching the filter,
+// update can be a document with update operators or an update pipeline
+func (d *Dao) UpdateManyDocuments(ctx context.Context, db, coll string, filter primitive.M, update any) (matched, modified int64, err error) {
+	ctx, tx, err := d.txContext(ctx, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.release()
+	res, err := d.client.Database(db).Collection(coll).UpdateMany(ctx, filter, update)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to update documents")
+		return 0, 0, fmt.Errorf("failed to update documents: %w", d.txError(ctx, tx, err))
+	}
+	if tx != nil {
+		tx.record(PendingUpdateMany, db, coll, nil, fmt.Sprintf("%s: %d modified", compactJson(filter), res.ModifiedCount))
+	}
+
+	log.Debug().Msgf("Documents updated, matched: %d, modified: %d, db: %v, collection: %v", res.MatchedCount, res.ModifiedCount, db, coll)
+
+	return res.MatchedCount, res.ModifiedCount, nil
+}
+
+func (d *Dao) DeleteDocument(ctx context.Context, db string, coll string, id any) error {
+	ctx, tx, err := d.txContext(ctx, false)
+	if err != nil {
+		return err
+	}
+	defer tx.release()
+	deleted, err := d.client.Database(db).Collection(coll).DeleteOne(ctx, primitive.M{"_id": id})
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Interface("id", id).Msg("Failed to delete document")
+		return fmt.Errorf("failed to delete document: %w", d.txError(ctx, tx, err))
+	}
+
+	if deleted.DeletedCount == 0 {
+		log.Error().Str("db", db).Str("collection", coll).Interface("id", id).Msg("No document found to delete")
+		return mongo.ErrNoDocuments
+	}
+	if tx != nil {
+		tx.record(PendingDelete, db, coll, id, "_id: "+StringifyId(id))
+	}
+
+	log.Debug().Msgf("Document deleted, id: %v, db: %v, collection: %v", id, db, coll)
+
+	return nil
+}
+
+// DeleteManyDocuments deletes every document matching the filter and returns the number of deleted documents
+func (d *Dao) DeleteManyDocuments(ctx context.Context, db, coll string, filter primitive.M) (int64, error) {
+	ctx, tx, err := d.txContext(ctx, false)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.release()
+	deleted, err := d.client.Database(db).Collection(coll).DeleteMany(ctx, filter)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to delete documents")
+		return 0, fmt.Errorf("failed to delete documents: %w", d.txError(ctx, tx, err))
+	}
+	if tx != nil {
+		tx.record(PendingDeleteMany, db, coll, nil, fmt.Sprintf("%s: %d deleted", compactJson(filter), deleted.DeletedCount))
+	}
+
+	log.Debug().Msgf("Documents deleted, count: %d, db: %v, collection: %v", deleted.DeletedCount, db, coll)
+
+	return deleted.DeletedCount, nil
+}
+
+// CreateCollection creates a collection or a view described by opts
+func (d *Dao) CreateCollection(ctx context.Context, db string, coll string, opts CollectionOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	database := d.client.Database(db)
+	var err error
+	if opts.Type == CollectionTypeView {
+		pipeline, perr := ParseViewPipeline(opts.Pipeline)
+		if perr != nil {
+			return perr
+		}
+		err = database.CreateView(ctx, coll, opts.ViewOn, pipeline, opts.ToViewOptions())
+	} else {
+		err = database.CreateCollection(ctx, coll, opts.ToCreateOptions())
+	}
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Str("type", opts.Type).Msg("Failed to add collection")
+		return err
+	}
+
+	log.Debug().Msgf("Collection added, db: %v, collection: %v, type: %v", db, coll, opts.Type)
+
+	return nil
+}
+
+func (d *Dao) DeleteCollection(ctx context.Context, db string, coll string) error {
+	err := d.client.Database(db).Collection(coll).Drop(ctx)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to delete collection")
+		return err
+	}
+
+	log.Debug().Msgf("Collection deleted, db: %v, collection: %v", db, coll)
+
+	return nil
+}
+
+func (d *Dao) RenameCollection(ctx context.Context, db string, oldColl string, newColl string) error {
+	renameCmd := bson.D{
+		{Key: "renameCollection", Value: fmt.Sprintf("%s.%s", db, oldColl)},
+		{Key: "to", Value: fmt.Sprintf("%s.%s", db, newColl)},
+	}
+
+	err := d.client.Database("admin").RunCommand(ctx, renameCmd).Err()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to rename collection")
+		return err
+	}
+
+	log.Debug().Msgf("Collection renamed, db: %v, old collection: %v, new collection: %v", db, oldColl, newColl)
+
+	return nil
+}
+
+func (d *Dao) ForceClose(ctx context.Context) error {
+	if err := d.client.Disconnect(ctx); err != nil {
+		log.Error().Err(err).Msg("Error disconnecting from the database")
+		return err
+	}
+
+	log.Debug().Msg("Connection closed")
+	return nil
+}
+
+func (d *Dao) runAdminCommand(ctx context.Context, key string, value any) (primitive.M, error) {
+	results := primitive.M{}
+	command := primitive.D{{Key: key, Value: value}}
+
+	err := d.client.Database("admin").RunCommand(ctx, command).Decode(&results)
+	if err != nil {
+		log.Error().Err(err).Str("key", key).Interface("value", value).Msg("Failed to run addmin command")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// runDbCommand runs the command on the given database, only the command name
+// is logged as user management commands contain passwords
+func (d *Dao) runDbCommand(ctx context.Context, db string, command primitive.D) (primitive.M, error) {
+	results := primitive.M{}
+
+	err := d.client.Database(db).RunCommand(ctx, command).Decode(&results)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("command", command[0].Key).Msg("Failed to run command")
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// ListUsers returns users defined in the given database
+func (d *Dao) ListUsers(ctx context.Context, db string) ([]User, error) {
+	results, err := d.runDbCommand(ctx, db, primitive.D{{Key: "usersInfo", Value: 1}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	rawUsers, _ := results["users"].(primitive.A)
+	users := make([]User, 0, len(rawUsers))
+	for _, raw := range rawUsers {
+		if raw, ok := raw.(primitive.M); ok {
+			users = append(users, ParseUser(raw))
+		}
+	}
+	slices.SortFunc(users, func(a, b User) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return users, nil
+}
+
+// GetUser returns the user together with its effective privileges
+func (d *Dao) GetUser(ctx context.Context, db, name string) (User, error) {
+	command := primitive.D{
+		{Key: "usersInfo", Value: primitive.M{"user": name, "db": db}},
+		{Key: "showPrivileges", Value: true},
+	}
+	results, err := d.runDbCommand(ctx, db, command)
+	if err != nil {
+		return User{}, fmt.Errorf("failed to get user %s: %w", name, err)
+	}
+
+	rawUsers, _ := results["users"].(primitive.A)
+	if len(rawUsers) == 0 {
+		return User{}, fmt.Errorf("user %s not found in %s", name, db)
+	}
+	raw, ok := rawUsers[0].(primitive.M)
+	if !ok {
+		return User{}, fmt.Errorf("unexpected usersInfo response")
+	}
+	return ParseUser(raw), nil
+}
+
+// ListRoles returns user defined and builtin roles of the given database
+func (d *Dao) ListRoles(ctx context.Context, db string) ([]Role, error) {
+	command := primitive.D{
+		{Key: "rolesInfo", Value: 1},
+		{Key: "showBuiltinRoles", Value: true},
+		{Key: "showPrivileges", Value: true},
+	}
+	results, err := d.runDbCommand(ctx, db, command)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	rawRoles, _ := results["roles"].(primitive.A)
+	roles := make([]Role, 0, len(rawRoles))
+	for _, raw := range rawRoles {
+		if raw, ok := raw.(primitive.M); ok {
+			roles = append(roles, ParseRole(raw))
+		}
+	}
+	slices.SortFunc(roles, func(a, b Role) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return roles, nil
+}
+
+// CreateUser creates the user in the given database with granted roles
+func (d *Dao) CreateUser(ctx context.Context, db, name, password string, roles []RoleRef) error {
+	command := primitive.D{
+		{Key: "createUser", Value: name},
+		{Key: "pwd", Value: password},
+		{Key: "roles", Value: roleRefsToBson(roles)},
+	}
+	if _, err := d.runDbCommand(ctx, db, command); err != nil {
+		return fmt.Errorf("failed to create user %s: %w", name, err)
+	}
+	return nil
+}
+
+func (d *Dao) DropUser(ctx context.Context, db, name string) error {
+	if _, err := d.runDbCommand(ctx, db, primitive.D{{Key: "dropUser", Value: name}}); err != nil {
+		return fmt.Errorf("failed to drop user %s: %w", name, err)
+	}
+	return nil
+}
+
+func (d *Dao) ChangeUserPassword(ctx context.Context, db, name, password string) error {
+	command := primitive.D{
+		{Key: "updateUser", Value: name},
+		{Key: "pwd", Value: password},
 	}
-
-	if deleted.DeletedCount == 0 {
-		log.Error().Str("db", db).Str("collection", coll).Interface("id", id).Msg("No document found to delete")
-		return mongo.ErrNoDocuments
+	if _, err := d.runDbCommand(ctx, db, command); err != nil {
+		return fmt.Errorf("failed to change password of %s: %w", name, err)
 	}
-
-	log.Debug().Msgf("Document deleted, id: %v, db: %v, collection: %v", id, db, coll)
-
 	return nil
 }
 
-func (d *Dao) AddCollection(ctx context.Context, db string, coll string) error {
-	err := d.client.Database(db).CreateCollection(ctx, coll)
-	if err != nil {
-		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to add collection")
-		return err
+func (d *Dao) GrantRoles(ctx context.Context, db, name string, roles []RoleRef) error {
+	command := primitive.D{
+		{Key: "grantRolesToUser", Value: name},
+		{Key: "roles", Value: roleRefsToBson(roles)},
+	}
+	if _, err := d.runDbCommand(ctx, db, command); err != nil {
+		return fmt.Errorf("failed to grant roles to %s: %w", name, err)
 	}
-
-	log.Debug().Msgf("Collection added, db: %v, collection: %v", db, coll)
-
 	return nil
 }
 
-func (d *Dao) DeleteCollection(ctx context.Context, db string, coll string) error {
-	err := d.client.Database(db).Collection(coll).Drop(ctx)
-	if err != nil {
-		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to delete collection")
-		return err
+func (d *Dao) RevokeRoles(ctx context.Context, db, name string, roles []RoleRef) error {
+	command := primitive.D{
+		{Key: "revokeRolesFromUser", Value: name},
+		{Key: "roles", Value: roleRefsToBson(roles)},
+	}
+	if _, err := d.runDbCommand(ctx, db, command); err != nil {
+		return fmt.Errorf("failed to revoke roles from %s: %w", name, err)
 	}
-
-	log.Debug().Msgf("Collection deleted, db: %v, collection: %v", db, coll)
-
 	return nil
 }
 
-func (d *Dao) RenameCollection(ctx context.Context, db string, oldColl string, newColl string) error {
-	renameCmd := bson.D{
-		{Key: "renameCollection", Value: fmt.Sprintf("%s.%s", db, oldColl)},
-		{Key: "to", Value: fmt.Sprintf("%s.%s", db, newColl)},
+// GetValidationRules returns the validator, validationLevel and
+// validationAction of the collection
+func (d *Dao) GetValidationRules(ctx context.Context, db, coll string) (ValidationRules, error) {
+	cursor, err := d.client.Database(db).ListCollections(ctx, primitive.M{"name": coll})
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to list collections")
+		return ValidationRules{}, fmt.Errorf("failed to get validation rules: %w", err)
 	}
 
-	err := d.client.Database("admin").RunCommand(ctx, renameCmd).Err()
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to rename collection")
-		return err
+	var specs []primitive.M
+	if err := cursor.All(ctx, &specs); err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to decode collection info")
+		return ValidationRules{}, fmt.Errorf("failed to get validation rules: %w", err)
+	}
+	if len(specs) == 0 {
+		return ValidationRules{}, fmt.Errorf("collection %s not found in %s", coll, db)
 	}
 
-	log.Debug().Msgf("Collection renamed, db: %v, old collection: %v, new collection: %v", db, oldColl, newColl)
+	options, _ := specs[0]["options"].(primitive.M)
+	return ParseValidationRules(options), nil
+}
 
+// SetValidationRules applies validation rules to the collection with collMod
+func (d *Dao) SetValidationRules(ctx context.Context, db, coll string, rules ValidationRules) error {
+	command := primitive.D{
+		{Key: "collMod", Value: coll},
+		{Key: "validator", Value: rules.Validator},
+		{Key: "validationLevel", Value: rules.Level},
+		{Key: "validationAction", Value: rules.Action},
+	}
+	if _, err := d.runDbCommand(ctx, db, command); err != nil {
+		return fmt.Errorf("failed to set validation rules: %w", err)
+	}
 	return nil
 }
 
-func (d *Dao) ForceClose(ctx context.Context) error {
-	if err := d.client.Disconnect(ctx); err != nil {
-		log.Error().Err(err).Msg("Error disconnecting from the database")
-		return err
+// CountInvalidDocuments returns the number of documents in the collection
+// which do not pass the validator
+func (d *Dao) CountInvalidDocuments(ctx context.Context, db, coll string, validator primitive.M) (int64, error) {
+	if len(validator) == 0 {
+		return 0, nil
 	}
+	count, err := d.client.Database(db).Collection(coll).CountDocuments(ctx, InvalidDocumentsFilter(validator))
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to count invalid documents")
+		return 0, fmt.Errorf("failed to count invalid documents: %w", err)
+	}
+	return count, nil
+}
 
-	log.Debug().Msg("Connection closed")
-	return nil
+// GetCollectionStats returns storage statistics of the collection
+func (d *Dao) GetCollectionStats(ctx context.Context, db, coll string) (CollectionStats, error) {
+	results, err := d.runDbCommand(ctx, db, primitive.D{{Key: "collStats", Value: coll}})
+	if err != nil {
+		return CollectionStats{}, fmt.Errorf("failed to get collection stats: %w", err)
+	}
+	return ParseCollectionStats(coll, results), nil
 }
 
-func (d *Dao) runAdminCommand(ctx context.Context, key string, value any) (primitive.M, error) {
-	results := primitive.M{}
-	command := primitive.D{{Key: key, Value: value}}
+// GetDatabaseStats returns storage statistics of the database
+func (d *Dao) GetDatabaseStats(ctx context.Context, db string) (DatabaseStats, error) {
+	command := primitive.D{{Key: "dbStats", Value: 1}, {Key: "freeStorage", Value: 1}}
+	results, err := d.runDbCommand(ctx, db, command)
+	if err != nil {
+		return DatabaseStats{}, fmt.Errorf("failed to get database stats: %w", err)
+	}
+	return ParseDatabaseStats(db, results), nil
+}
 
-	err := d.client.Database("admin").RunCommand(ctx, command).Decode(&results)
+// ListCollectionStats returns statistics of every collection of the database,
+// views are skipped as they store no data and collections which
+// statistics can't be read are logged and skipped
+func (d *Dao) ListCollectionStats(ctx context.Context, db string) ([]CollectionStats, error) {
+	listCollOptions := options.ListCollections().SetAuthorizedCollections(*d.Config.GetOptions().AuthorizedCollections)
+	specs, err := d.client.Database(db).ListCollectionSpecifications(ctx, primitive.M{}, listCollOptions)
 	if err != nil {
-		log.Error().Err(err).Str("key", key).Interface("value", value).Msg("Failed to run addmin command")
-		return nil, err
+		log.Error().Err(err).Str("db", db).Msg("Failed to list collections")
+		return nil, fmt.Errorf("failed to list collections: %w", err)
 	}
 
-	return results, nil
+	stats := make([]CollectionStats, 0, len(specs))
+	for _, spec := range specs {
+		if spec.Type == CollectionTypeView {
+			continue
+		}
+		collStats, err := d.GetCollectionStats(ctx, db, spec.Name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			continue
+		}
+		stats = append(stats, collStats)
+	}
+	return stats, nil
 }
 
 // GetIndexes fetches the indexes for a given database and collection
@@ -319,34 +1216,17 @@ func (d *Dao) GetIndexes(ctx context.Context, db string, coll string) ([]IndexIn
 			log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Error unmarshalling indexes")
 			return nil, err
 		}
-
-		indexInfo := IndexInfo{
-			Name:       idx["name"].(string),
-			Definition: idx["key"].(bson.M),
-			Type:       "REGULAR",
-			Size:       "N/A",
-			Usage:      "N/A",
-			Properties: []string{},
-		}
-
-		// Determine index type and properties
-		if unique, ok := idx["unique"]; ok && unique.(bool) {
-			indexInfo.Properties = append(indexInfo.Properties, "UNIQUE")
-		}
-		if sparse, ok := idx["sparse"]; ok && sparse.(bool) {
-			indexInfo.Properties = append(indexInfo.Properties, "SPARSE")
+		// key order is lost in bson.M and matters for compound indexes
+		var ordered struct {
+			Key primitive.D `bson:"key"`
 		}
-		if ttl, ok := idx["expireAfterSeconds"]; ok && ttl.(int32) > 0 {
-			indexInfo.Properties = append(indexInfo.Properties, "TTL")
-			indexInfo.Type = "TTL"
-		}
-		if len(indexInfo.Definition) > 1 {
-			indexInfo.Properties = append(indexInfo.Properties, "COMPOUND")
-		}
-		if indexInfo.Name == "_id_" {
-			indexInfo.Properties = append(indexInfo.Properties, "UNIQUE")
+		if err := cursor.Decode(&ordered); err != nil {
+			log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Error unmarshalling index keys")
+			return nil, err
 		}
 
+		indexInfo := ParseIndexInfo(idx)
+		indexInfo.Keys = ordered.Key
 		indexes = append(indexes, indexInfo)
 	}
 
@@ -369,6 +1249,8 @@ func (d *Dao) GetIndexes(ctx context.Context, db string, coll string) ([]IndexIn
 			if stat, ok := stats[idx.Name]; ok {
 				indexes[i].Size = stat.Size
 				indexes[i].Usage = formatIndexUsage(stat.Accesses["ops"].(int64), stat.Accesses["since"].(primitive.DateTime).Time())
+				indexes[i].Ops = stat.Accesses["ops"].(int64)
+				indexes[i].HasStats = true
 			}
 		}
 	}
@@ -432,10 +1314,11 @@ func (d *Dao) CreateIndex(ctx context.Context, db, coll string, indexDef mongo.I
 }
 
 func (d *Dao) AggregateDocuments(ctx context.Context, db, collection string, pipeline mongo.Pipeline) ([]primitive.M, error) {
-	cursor, err := d.client.Database(db).Collection(collection).Aggregate(ctx, pipeline)
+	opts := &options.AggregateOptions{MaxTime: d.maxTime()}
+	cursor, err := d.client.Database(db).Collection(collection).Aggregate(ctx, pipeline, opts)
 	if err != nil {
 		log.Error().Err(err).Str("db", db).Str("collection", collection).Msg("Error running aggregation")
-		return nil, fmt.Errorf("aggregation error: %w", err)
+		return nil, fmt.Errorf("aggregation error: %w", d.wrapMaxTimeError(err))
 	}
 	defer func() {
 		if err := cursor.Close(ctx); err != nil {
@@ -445,11 +1328,163 @@ func (d *Dao) AggregateDocuments(ctx context.Context, db, collection string, pip
 
 	var results []primitive.M
 	if err := cursor.All(ctx, &results); err != nil {
-		return nil, fmt.Errorf("error reading aggregation results: %w", err)
+		return nil, fmt.Errorf("error reading aggregation results: %w", d.wrapMaxTimeError(err))
 	}
 	return results, nil
 }
 
+// SampleDocuments returns randomly selected documents from the collection
+func (d *Dao) SampleDocuments(ctx context.Context, db, coll string, size int64) ([]primitive.M, error) {
+	pipeline := mongo.Pipeline{{{Key: "$sample", Value: primitive.M{"size": size}}}}
+	cursor, err := d.client.Database(db).Collection(coll).Aggregate(ctx, pipeline)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to sample documents")
+		return nil, fmt.Errorf("failed to sample documents: %w", err)
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to close cursor")
+		}
+	}()
+
+	var docs []primitive.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to decode sampled documents")
+		return nil, fmt.Errorf("failed to decode sampled documents: %w", err)
+	}
+
+	log.Debug().Msgf("Sampled %d documents from %s.%s", len(docs), db, coll)
+	return docs, nil
+}
+
+func isShownUpdate(event ChangeEvent, shownIds []any) bool {
+	if event.OperationType != OperationUpdate && event.OperationType != OperationReplace {
+		return false
+	}
+	return slices.ContainsFunc(shownIds, func(id any) bool { return IdsEqual(id, event.DocumentId) })
+}
+
+// documentMatches reports whether the document with the id matches the filter
+func documentMatches(ctx context.Context, coll *mongo.Collection, filter primitive.M, id any) (bool, error) {
+	limit := int64(1)
+	count, err := coll.CountDocuments(ctx, primitive.M{"$and": primitive.A{filter, primitive.M{"_id": id}}},
+		&options.CountOptions{Limit: &limit})
+	return count > 0, err
+}
+
+// WatchCollection opens a change stream on the collection and calls onChange for
+// every received event until ctx is cancelled, errors of the opened stream are
+// passed to onError. Updates of shownIds which don't match the filter anymore
+// have LeftFilter set. When the server doesn't support change streams
+// ErrChangeStreamUnsupported is returned
+func (d *Dao) WatchCollection(ctx context.Context, db, coll string, filter primitive.M, shownIds []any,
+	onChange func(ChangeEvent), onError func(error)) error {
+
+	pipeline, err := WatchPipeline(filter, shownIds)
+	if err != nil {
+		return err
+	}
+	collection := d.client.Database(db).Collection(coll)
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	stream, err := collection.Watch(ctx, pipeline, opts)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to open change stream")
+		if isChangeStreamUnsupported(err) {
+			return ErrChangeStreamUnsupported
+		}
+		return fmt.Errorf("failed to open change stream: %w", err)
+	}
+
+	go func() {
+		defer func() {
+			if err := stream.Close(context.Background()); err != nil {
+				log.Error().Err(err).Msg("Failed to close change stream")
+			}
+		}()
+
+		for stream.Next(ctx) {
+			var raw primitive.M
+			if err := stream.Decode(&raw); err != nil {
+				log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to decode change event")
+				continue
+			}
+			event := ParseChangeEvent(raw)
+			if documentKey, ok := stream.Current.Lookup("documentKey").DocumentOK(); ok {
+				if id, ok := orderedId(documentKey); ok {
+					event.DocumentId = id
+					if event.FullDocument != nil {
+						event.FullDocument["_id"] = id
+					}
+				}
+			}
+			if len(filter) > 0 && isShownUpdate(event, shownIds) {
+				// the event passed either by the filter or by the _id of the shown document
+				matches, err := documentMatches(ctx, collection, filter, event.DocumentId)
+				if err != nil {
+					log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to match changed document")
+				} else {
+					event.LeftFilter = !matches
+				}
+			}
+			onChange(event)
+		}
+
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Change stream failed")
+			onError(fmt.Errorf("change stream failed: %w", err))
+		}
+	}()
+
+	log.Debug().Msgf("Watching changes of %s.%s", db, coll)
+	return nil
+}
+
+// ExplainFind runs explain with executionStats verbosity for the find command
+func (d *Dao) ExplainFind(ctx context.Context, db, coll string, filter primitive.M, sort primitive.D, projection primitive.M, skip, limit int64) (*ExplainPlan, error) {
+	find := bson.D{{Key: "find", Value: coll}, {Key: "filter", Value: filter}}
+	if len(sort) > 0 {
+		find = append(find, bson.E{Key: "sort", Value: sort})
+	}
+	if len(projection) > 0 {
+		find = append(find, bson.E{Key: "projection", Value: projection})
+	}
+	if skip > 0 {
+		find = append(find, bson.E{Key: "skip", Value: skip})
+	}
+	if limit > 0 {
+		find = append(find, bson.E{Key: "limit", Value: limit})
+	}
+
+	return d.runExplain(ctx, db, coll, find)
+}
+
+// ExplainAggregate runs explain with executionStats verbosity for the aggregation pipeline
+func (d *Dao) ExplainAggregate(ctx context.Context, db, coll string, pipeline mongo.Pipeline) (*ExplainPlan, error) {
+	aggregate := bson.D{
+		{Key: "aggregate", Value: coll},
+		{Key: "pipeline", Value: pipeline},
+		{Key: "cursor", Value: bson.D{}},
+	}
+
+	return d.runExplain(ctx, db, coll, aggregate)
+}
+
+func (d *Dao) runExplain(ctx context.Context, db, coll string, command bson.D) (*ExplainPlan, error) {
+	explainCmd := bson.D{
+		{Key: "explain", Value: command},
+		{Key: "verbosity", Value: "executionStats"},
+	}
+
+	var result primitive.M
+	err := d.client.Database(db).RunCommand(ctx, explainCmd).Decode(&result)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Msg("Failed to explain query")
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	return ParseExplain(result), nil
+}
+
 func (d *Dao) DropIndex(ctx context.Context, db, coll, indexName string) error {
 	_, err := d.client.Database(db).Collection(coll).Indexes().DropOne(ctx, indexName)
 	if err != nil {
@@ -458,3 +1493,303 @@ func (d *Dao) DropIndex(ctx context.Context, db, coll, indexName string) error {
 	}
 	return nil
 }
+
+// GetIndexRecommendations flags unused and redundant indexes of the collection
+// and suggests indexes for queries which scanned the whole collection,
+// queries are taken from the profiler and from the query bar of this session
+func (d *Dao) GetIndexRecommendations(ctx context.Context, db, coll string) ([]IndexRecommendation, error) {
+	indexes, err := d.GetIndexes(ctx, db, coll)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get indexes: %w", err)
+	}
+	namespace := db + "." + coll
+
+	recommendations := UnusedIndexes(indexes)
+	recommendations = append(recommendations, RedundantIndexes(indexes)...)
+
+	shapes := []QueryShape{}
+	entries, err := d.GetProfileEntries(ctx, db, recommendationProfileEntries)
+	if err != nil {
+		log.Warn().Err(err).Str("db", db).Msg("Skipping profiler in index recommendations")
+	}
+	for _, entry := range entries {
+		if shape, ok := QueryShapeFromProfile(entry, namespace); ok {
+			shapes = append(shapes, shape)
+		}
+	}
+	for _, shape := range d.queryShapes.Get(namespace) {
+		plan, err := d.ExplainFind(ctx, db, coll, shape.Filter, shape.Sort, nil, 0, 0)
+		if err != nil {
+			continue
+		}
+		if plan.HasCollectionScan() {
+			shapes = append(shapes, shape)
+		}
+	}
+	recommendations = append(recommendations, CandidateIndexes(shapes, indexes)...)
+
+	return recommendations, nil
+}
+
+// SetIndexHidden hides or unhides the index from the query planner,
+// hiding is a safe way to check the impact of dropping the index
+func (d *Dao) SetIndexHidden(ctx context.Context, db, coll, indexName string, hidden bool) error {
+	command := primitive.D{
+		{Key: "collMod", Value: coll},
+		{Key: "index", Value: primitive.D{{Key: "name", Value: indexName}, {Key: "hidden", Value: hidden}}},
+	}
+	if _, err := d.runDbCommand(ctx, db, command); err != nil {
+		log.Error().Err(err).Str("db", db).Str("collection", coll).Str("index", indexName).Msg("Failed to change index visibility")
+		return fmt.Errorf("failed to change index visibility: %w", err)
+	}
+	return nil
+}
+
+// GetIndexBuilds returns index builds in progress on the collection
+func (d *Dao) GetIndexBuilds(ctx context.Context, db, coll string) ([]IndexBuild, error) {
+	operations, err := d.GetCurrentOperations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return ParseIndexBuilds(operations, db+"."+coll), nil
+}
+
+// gridFSBucket returns the bucket with read and write deadlines of the context,
+// GridFS streams don't accept the context directly
+func (d *Dao) gridFSBucket(ctx context.Context, db, bucket string) (*gridfs.Bucket, error) {
+	b, err := gridfs.NewBucket(d.client.Database(db), options.GridFSBucket().SetName(bucket))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GridFS bucket %s: %w", bucket, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = b.SetReadDeadline(deadline)
+		_ = b.SetWriteDeadline(deadline)
+	}
+	return b, nil
+}
+
+// ListGridFSFiles returns files of the bucket, the most recently uploaded first
+func (d *Dao) ListGridFSFiles(ctx context.Context, db, bucket string, limit int32) ([]GridFSFile, error) {
+	b, err := d.gridFSBucket(ctx, db, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.GridFSFind().SetSort(primitive.D{{Key: "uploadDate", Value: -1}}).SetLimit(limit)
+	cursor, err := b.FindContext(ctx, primitive.M{}, opts)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("bucket", bucket).Msg("Failed to list GridFS files")
+		return nil, fmt.Errorf("failed to list GridFS files: %w", err)
+	}
+	defer func() {
+		if err := cursor.Close(ctx); err != nil {
+			log.Error().Err(err).Msg("Failed to close cursor")
+		}
+	}()
+
+	var docs []primitive.M
+	if err := cursor.All(ctx, &docs); err != nil {
+		log.Error().Err(err).Str("db", db).Str("bucket", bucket).Msg("Failed to decode GridFS files")
+		return nil, fmt.Errorf("failed to decode GridFS files: %w", err)
+	}
+
+	files := make([]GridFSFile, 0, len(docs))
+	for _, doc := range docs {
+		files = append(files, ParseGridFSFile(doc))
+	}
+	return files, nil
+}
+
+// DownloadGridFSFile writes the content of the file into w and returns the number of written bytes
+func (d *Dao) DownloadGridFSFile(ctx context.Context, db, bucket string, id any, w io.Writer) (int64, error) {
+	b, err := d.gridFSBucket(ctx, db, bucket)
+	if err != nil {
+		return 0, err
+	}
+	written, err := b.DownloadToStream(id, w)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("bucket", bucket).Msg("Failed to download GridFS file")
+		return written, fmt.Errorf("failed to download GridFS file: %w", err)
+	}
+	return written, nil
+}
+
+// UploadGridFSFile stores the content of r as a new file in the bucket, metadata is optional
+func (d *Dao) UploadGridFSFile(ctx context.Context, db, bucket, filename string, r io.Reader, metadata primitive.M) (primitive.ObjectID, error) {
+	b, err := d.gridFSBucket(ctx, db, bucket)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+	opts := options.GridFSUpload()
+	if len(metadata) > 0 {
+		opts.SetMetadata(metadata)
+	}
+	id, err := b.UploadFromStream(filename, r, opts)
+	if err != nil {
+		log.Error().Err(err).Str("db", db).Str("bucket", bucket).Str("filename", filename).Msg("Failed to upload GridFS file")
+		return primitive.NilObjectID, fmt.Errorf("failed to upload GridFS file: %w", err)
+	}
+	return id, nil
+}
+
+// DeleteGridFSFile deletes the file together with its chunks
+func (d *Dao) DeleteGridFSFile(ctx context.Context, db, bucket string, id any) error {
+	b, err := d.gridFSBucket(ctx, db, bucket)
+	if err != nil {
+		return err
+	}
+	if err := b.DeleteContext(ctx, id); err != nil {
+		log.Error().Err(err).Str("db", db).Str("bucket", bucket).Msg("Failed to delete GridFS file")
+		return fmt.Errorf("failed to delete GridFS file: %w", err)
+	}
+	return nil
+}
+
+// DropGridFSBucket drops files and chunks collections of the bucket
+func (d *Dao) DropGridFSBucket(ctx context.Context, db, bucket string) error {
+	b, err := d.gridFSBucket(ctx, db, bucket)
+	if err != nil {
+		return err
+	}
+	if err := b.DropContext(ctx); err != nil {
+		log.Error().Err(err).Str("db", db).Str("bucket", bucket).Msg("Failed to drop GridFS bucket")
+		return fmt.Errorf("failed to drop GridFS bucket: %w", err)
+	}
+	return nil
+}
+
+// StartTransaction starts a transaction in a new session, document reads and writes
+// of the Dao run inside it until CommitTransaction or AbortTransaction is called
+func (d *Dao) StartTransaction(ctx context.Context) (*Transaction, error) {
+	d.txMu.Lock()
+	defer d.txMu.Unlock()
+	if d.transaction != nil {
+		return nil, ErrTransactionActive
+	}
+
+	hello, err := d.hello(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !SupportsTransactions(hello) {
+		return nil, ErrTransactionsUnsupported
+	}
+
+	session, err := d.client.StartSession()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to start session")
+		return nil, fmt.Errorf("failed to start session: %w", err)
+	}
+	if err := session.StartTransaction(); err != nil {
+		session.EndSession(ctx)
+		log.Error().Err(err).Msg("Failed to start transaction")
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+
+	d.transaction = newTransaction(session)
+	return d.transaction, nil
+}
+
+// ActiveTransaction returns the active transaction, nil when there is none
+func (d *Dao) ActiveTransaction() *Transaction {
+	d.txMu.Lock()
+	defer d.txMu.Unlock()
+	return d.transaction
+}
+
+// CommitTransaction commits writes of the active transaction and ends its session,
+// the transaction is finished even when the commit fails
+func (d *Dao) CommitTransaction(ctx context.Context) ([]PendingOperation, error) {
+	tx, err := d.finishTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.end(ctx)
+
+	if err := tx.session.CommitTransaction(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to commit transaction")
+		if isTransactionAborted(err) {
+			err = fmt.Errorf("%w, %d staged writes were rolled back: %w", ErrTransactionAborted, len(tx.Pending()), err)
+		}
+		return tx.Pending(), fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return tx.Pending(), nil
+}
+
+// AbortTransaction rolls back writes of the active transaction and ends its session
+func (d *Dao) AbortTransaction(ctx context.Context) ([]PendingOperation, error) {
+	tx, err := d.finishTransaction()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.end(ctx)
+
+	if err := tx.session.AbortTransaction(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to abort transaction")
+		return tx.Pending(), fmt.Errorf("failed to abort transaction: %w", err)
+	}
+	return tx.Pending(), nil
+}
+
+// finishTransaction detaches the active transaction from the Dao,
+// its session stays locked until the transaction is ended
+func (d *Dao) finishTransaction() (*Transaction, error) {
+	d.txMu.Lock()
+	defer d.txMu.Unlock()
+	tx := d.transaction
+	if tx == nil {
+		return nil, ErrNoTransaction
+	}
+	if !tx.tryLockSession() {
+		return nil, ErrTransactionBusy
+	}
+	d.transaction = nil
+	return tx, nil
+}
+
+// txError ends the transaction tx when err shows the server aborted it,
+// as its session can't be used for further writes. It's called with the session locked
+func (d *Dao) txError(ctx context.Context, tx *Transaction, err error) error {
+	if tx == nil || !isTransactionAborted(err) {
+		return err
+	}
+	log.Error().Err(err).Msg("Transaction was aborted by the server")
+
+	d.txMu.Lock()
+	if d.transaction == tx {
+		d.transaction = nil
+	}
+	d.txMu.Unlock()
+	tx.session.EndSession(ctx)
+	tx.ended = true
+
+	return fmt.Errorf("%w, %d staged writes were rolled back: %w", ErrTransactionAborted, len(tx.Pending()), err)
+}
+
+// txContext returns the context bound to the session of the active transaction,
+// the session is locked until tx.release is called, as it can't be used concurrently.
+// Loading documents in the background waits for the session until ctx is cancelled,
+// other operations get ErrTransactionBusy instead of blocking the UI. ctx is returned
+// unchanged with nil transaction when no transaction is active
+func (d *Dao) txContext(ctx context.Context, wait bool) (context.Context, *Transaction, error) {
+	d.txMu.Lock()
+	tx := d.transaction
+	d.txMu.Unlock()
+	if tx == nil {
+		return ctx, nil, nil
+	}
+
+	if wait {
+		if err := tx.lockSession(ctx); err != nil {
+			return nil, nil, err
+		}
+	} else if !tx.tryLockSession() {
+		return nil, nil, ErrTransactionBusy
+	}
+	if tx.ended {
+		// the transaction was finished while waiting for its session
+		tx.unlockSession()
+		return ctx, nil, nil
+	}
+	return mongo.NewSessionContext(ctx, tx.session), tx, nil
+}
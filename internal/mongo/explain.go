@@ -0,0 +1,286 @@
+package mongo
+
+import (
+	"sort"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PlanStage is a single stage of the query plan, statistics are set
+// only when the plan comes from executionStats
+type PlanStage struct {
+	Stage        string
+	IndexName    string
+	KeyPattern   string
+	Filter       string
+	HasStats     bool
+	NReturned    int64
+	KeysExamined int64
+	DocsExamined int64
+	// ExecutionTimeMillis is an estimate of time spent in this stage and all its children
+	ExecutionTimeMillis int64
+	Children            []*PlanStage
+}
+
+// PipelineStage is an aggregation stage executed after the query,
+// like $group or $lookup
+type PipelineStage struct {
+	Name                string
+	NReturned           int64
+	ExecutionTimeMillis int64
+}
+
+// ExplainPlan is a summary of the explain command run with executionStats verbosity
+type ExplainPlan struct {
+	Namespace           string
+	WinningPlan         *PlanStage
+	RejectedPlans       []*PlanStage
+	PipelineStages      []PipelineStage
+	NReturned           int64
+	TotalKeysExamined   int64
+	TotalDocsExamined   int64
+	ExecutionTimeMillis int64
+}
+
+// UsedIndexes returns names of indexes used by the winning plan
+func (p *ExplainPlan) UsedIndexes() []string {
+	unique := map[string]struct{}{}
+	p.WinningPlan.walk(func(stage *PlanStage) {
+		if stage.IndexName != "" {
+			unique[stage.IndexName] = struct{}{}
+		}
+	})
+
+	indexes := make([]string, 0, len(unique))
+	for name := range unique {
+		indexes = append(indexes, name)
+	}
+	sort.Strings(indexes)
+	return indexes
+}
+
+// HasCollectionScan reports if the winning plan reads the whole collection
+func (p *ExplainPlan) HasCollectionScan() bool {
+	found := false
+	p.WinningPlan.walk(func(stage *PlanStage) {
+		if stage.Stage == "COLLSCAN" {
+			found = true
+		}
+	})
+	return found
+}
+
+func (s *PlanStage) walk(fn func(*PlanStage)) {
+	if s == nil {
+		return
+	}
+	fn(s)
+	for _, child := range s.Children {
+		child.walk(fn)
+	}
+}
+
+// ParseExplain converts the output of explain command for find or aggregate
+func ParseExplain(explain primitive.M) *ExplainPlan {
+	plan := &ExplainPlan{}
+
+	if shards, ok := explain["shards"].(primitive.M); ok && explain["queryPlanner"] == nil {
+		parseShardedExplain(plan, shards)
+		return plan
+	}
+
+	stages, ok := explain["stages"].(primitive.A)
+	if !ok {
+		parseQueryExplain(plan, explain)
+		return plan
+	}
+
+	// pipeline that was not fully pushed down to the query layer, first
+	// stage is a $cursor with the query plan, the rest are pipeline stages
+	for _, rawStage := range stages {
+		stage, ok := rawStage.(primitive.M)
+		if !ok {
+			continue
+		}
+		if cursor, ok := stage["$cursor"].(primitive.M); ok {
+			parseQueryExplain(plan, cursor)
+			continue
+		}
+		for name := range stage {
+			if name == "nReturned" || name == "executionTimeMillisEstimate" {
+				continue
+			}
+			plan.PipelineStages = append(plan.PipelineStages, PipelineStage{
+				Name:                name,
+				NReturned:           toInt64(stage["nReturned"]),
+				ExecutionTimeMillis: toInt64(stage["executionTimeMillisEstimate"]),
+			})
+			break
+		}
+	}
+	if len(plan.PipelineStages) > 0 {
+		last := plan.PipelineStages[len(plan.PipelineStages)-1]
+		plan.NReturned = last.NReturned
+		// estimates include time spent in previous stages
+		plan.ExecutionTimeMillis = max(plan.ExecutionTimeMillis, last.ExecutionTimeMillis)
+	}
+
+	return plan
+}
+
+// parseShardedExplain merges explain of the aggregation from every shard,
+// each shard plan becomes a child of a single SHARDS stage
+func parseShardedExplain(plan *ExplainPlan, shards primitive.M) {
+	names := make([]string, 0, len(shards))
+	for name := range shards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	plan.WinningPlan = &PlanStage{Stage: "SHARDS"}
+	for _, name := range names {
+		shardExplain, ok := shards[name].(primitive.M)
+		if !ok {
+			continue
+		}
+		shardPlan := ParseExplain(shardExplain)
+		if plan.Namespace == "" {
+			plan.Namespace = shardPlan.Namespace
+		}
+		if shardPlan.WinningPlan != nil {
+			shardPlan.WinningPlan.Stage = name + ": " + shardPlan.WinningPlan.Stage
+			plan.WinningPlan.Children = append(plan.WinningPlan.Children, shardPlan.WinningPlan)
+		}
+		plan.RejectedPlans = append(plan.RejectedPlans, shardPlan.RejectedPlans...)
+		plan.PipelineStages = append(plan.PipelineStages, shardPlan.PipelineStages...)
+		plan.NReturned += shardPlan.NReturned
+		plan.TotalKeysExamined += shardPlan.TotalKeysExamined
+		plan.TotalDocsExamined += shardPlan.TotalDocsExamined
+		plan.ExecutionTimeMillis = max(plan.ExecutionTimeMillis, shardPlan.ExecutionTimeMillis)
+	}
+}
+
+func parseQueryExplain(plan *ExplainPlan, explain primitive.M) {
+	planner, _ := explain["queryPlanner"].(primitive.M)
+	if planner != nil {
+		plan.Namespace, _ = planner["namespace"].(string)
+		if winning, ok := planner["winningPlan"].(primitive.M); ok {
+			plan.WinningPlan = parsePlanStage(unwrapQueryPlan(winning))
+		}
+		if rejected, ok := planner["rejectedPlans"].(primitive.A); ok {
+			for _, rawPlan := range rejected {
+				if rejectedPlan, ok := rawPlan.(primitive.M); ok {
+					plan.RejectedPlans = append(plan.RejectedPlans, parsePlanStage(unwrapQueryPlan(rejectedPlan)))
+				}
+			}
+		}
+	}
+
+	stats, _ := explain["executionStats"].(primitive.M)
+	if stats == nil {
+		return
+	}
+	plan.NReturned = toInt64(stats["nReturned"])
+	plan.TotalKeysExamined = toInt64(stats["totalKeysExamined"])
+	plan.TotalDocsExamined = toInt64(stats["totalDocsExamined"])
+	plan.ExecutionTimeMillis = toInt64(stats["executionTimeMillis"])
+	if executionStages, ok := stats["executionStages"].(primitive.M); ok {
+		// execution stages have the same shape as the winning plan, but with statistics
+		plan.WinningPlan = parsePlanStage(executionStages)
+	}
+}
+
+// unwrapQueryPlan returns the plan from under queryPlan key, which is used
+// when the query is executed by the slot based execution engine
+func unwrapQueryPlan(plan primitive.M) primitive.M {
+	if queryPlan, ok := plan["queryPlan"].(primitive.M); ok {
+		return queryPlan
+	}
+	return plan
+}
+
+func parsePlanStage(raw primitive.M) *PlanStage {
+	stage := &PlanStage{}
+	stage.Stage, _ = raw["stage"].(string)
+	stage.IndexName, _ = raw["indexName"].(string)
+	if keyPattern, ok := raw["keyPattern"].(primitive.M); ok {
+		stage.KeyPattern = compactJson(keyPattern)
+	}
+	if filter, ok := raw["filter"].(primitive.M); ok {
+		stage.Filter = compactJson(filter)
+	}
+	if _, ok := raw["nReturned"]; ok {
+		stage.HasStats = true
+		stage.NReturned = toInt64(raw["nReturned"])
+		stage.KeysExamined = toInt64(raw["keysExamined"])
+		stage.DocsExamined = toInt64(raw["docsExamined"])
+		stage.ExecutionTimeMillis = toInt64(raw["executionTimeMillisEstimate"])
+	}
+
+	// children are stored under different keys depending on the stage
+	// (inputStage, inputStages, outerStage, innerStage, thenStage, shards...)
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		switch value := raw[key].(type) {
+		case primitive.M:
+			if _, ok := value["stage"]; ok {
+				stage.Children = append(stage.Children, parsePlanStage(value))
+			}
+		case primitive.A:
+			for _, item := range value {
+				if child := planStageFromArrayItem(item); child != nil {
+					stage.Children = append(stage.Children, child)
+				}
+			}
+		}
+	}
+
+	return stage
+}
+
+// planStageFromArrayItem parses stages from inputStages and per shard plans
+func planStageFromArrayItem(item any) *PlanStage {
+	doc, ok := item.(primitive.M)
+	if !ok {
+		return nil
+	}
+	if _, ok := doc["stage"]; ok {
+		return parsePlanStage(doc)
+	}
+
+	var shardPlan primitive.M
+	if executionStages, ok := doc["executionStages"].(primitive.M); ok {
+		shardPlan = executionStages
+	} else if winningPlan, ok := doc["winningPlan"].(primitive.M); ok {
+		shardPlan = unwrapQueryPlan(winningPlan)
+	} else {
+		return nil
+	}
+
+	child := parsePlanStage(shardPlan)
+	if shardName, ok := doc["shardName"].(string); ok {
+		child.Stage = shardName + ": " + child.Stage
+	}
+	return child
+}
+
+func compactJson(doc primitive.M) string {
+	bytes, err := bson.MarshalExtJSON(sortDocumentKeys(doc), false, false)
+	if err != nil {
+		return ""
+	}
+	return string(bytes)
+}
+
+func toInt64(value any) int64 {
+	number, ok := anyToFloat64(value)
+	if !ok {
+		return 0
+	}
+	return int64(number)
+}
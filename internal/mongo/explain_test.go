@@ -0,0 +1,166 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseExplainFind(t *testing.T) {
+	explain := primitive.M{
+		"queryPlanner": primitive.M{
+			"namespace": "test.users",
+			"winningPlan": primitive.M{
+				"stage": "FETCH",
+				"inputStage": primitive.M{
+					"stage":      "IXSCAN",
+					"indexName":  "name_1",
+					"keyPattern": primitive.M{"name": int32(1)},
+				},
+			},
+			"rejectedPlans": primitive.A{
+				primitive.M{
+					"stage":  "COLLSCAN",
+					"filter": primitive.M{"name": primitive.M{"$eq": "John"}},
+				},
+			},
+		},
+		"executionStats": primitive.M{
+			"nReturned":           int32(2),
+			"executionTimeMillis": int32(3),
+			"totalKeysExamined":   int32(2),
+			"totalDocsExamined":   int32(2),
+			"executionStages": primitive.M{
+				"stage":                       "FETCH",
+				"nReturned":                   int32(2),
+				"executionTimeMillisEstimate": int32(1),
+				"docsExamined":                int32(2),
+				"inputStage": primitive.M{
+					"stage":                       "IXSCAN",
+					"indexName":                   "name_1",
+					"keyPattern":                  primitive.M{"name": int32(1)},
+					"nReturned":                   int32(2),
+					"executionTimeMillisEstimate": int32(0),
+					"keysExamined":                int32(2),
+				},
+			},
+		},
+	}
+
+	plan := ParseExplain(explain)
+
+	assert.Equal(t, "test.users", plan.Namespace)
+	assert.Equal(t, int64(2), plan.NReturned)
+	assert.Equal(t, int64(3), plan.ExecutionTimeMillis)
+	assert.Equal(t, int64(2), plan.TotalKeysExamined)
+	assert.Equal(t, int64(2), plan.TotalDocsExamined)
+
+	require.NotNil(t, plan.WinningPlan)
+	assert.Equal(t, "FETCH", plan.WinningPlan.Stage)
+	assert.True(t, plan.WinningPlan.HasStats)
+	assert.Equal(t, int64(2), plan.WinningPlan.DocsExamined)
+	require.Len(t, plan.WinningPlan.Children, 1)
+
+	ixscan := plan.WinningPlan.Children[0]
+	assert.Equal(t, "IXSCAN", ixscan.Stage)
+	assert.Equal(t, "name_1", ixscan.IndexName)
+	assert.Equal(t, `{"name":1}`, ixscan.KeyPattern)
+	assert.Equal(t, int64(2), ixscan.KeysExamined)
+
+	require.Len(t, plan.RejectedPlans, 1)
+	assert.Equal(t, "COLLSCAN", plan.RejectedPlans[0].Stage)
+	assert.Equal(t, `{"name":{"$eq":"John"}}`, plan.RejectedPlans[0].Filter)
+	assert.False(t, plan.RejectedPlans[0].HasStats)
+
+	assert.Equal(t, []string{"name_1"}, plan.UsedIndexes())
+	assert.False(t, plan.HasCollectionScan())
+}
+
+func TestParseExplainSlotBasedEngine(t *testing.T) {
+	explain := primitive.M{
+		"queryPlanner": primitive.M{
+			"namespace": "test.users",
+			"winningPlan": primitive.M{
+				"queryPlan": primitive.M{
+					"stage": "COLLSCAN",
+				},
+				"slotBasedPlan": primitive.M{"stages": "..."},
+			},
+			"rejectedPlans": primitive.A{},
+		},
+	}
+
+	plan := ParseExplain(explain)
+
+	require.NotNil(t, plan.WinningPlan)
+	assert.Equal(t, "COLLSCAN", plan.WinningPlan.Stage)
+	assert.True(t, plan.HasCollectionScan())
+	assert.Empty(t, plan.UsedIndexes())
+}
+
+func TestParseExplainAggregate(t *testing.T) {
+	explain := primitive.M{
+		"stages": primitive.A{
+			primitive.M{
+				"$cursor": primitive.M{
+					"queryPlanner": primitive.M{
+						"namespace":   "test.orders",
+						"winningPlan": primitive.M{"stage": "COLLSCAN"},
+					},
+					"executionStats": primitive.M{
+						"nReturned":         int32(100),
+						"totalDocsExamined": int32(100),
+						"executionStages": primitive.M{
+							"stage":        "COLLSCAN",
+							"nReturned":    int32(100),
+							"docsExamined": int32(100),
+						},
+					},
+				},
+				"nReturned":                   int64(100),
+				"executionTimeMillisEstimate": int64(4),
+			},
+			primitive.M{
+				"$group":                      primitive.M{"_id": "$status"},
+				"nReturned":                   int64(3),
+				"executionTimeMillisEstimate": int64(6),
+			},
+		},
+	}
+
+	plan := ParseExplain(explain)
+
+	assert.Equal(t, "test.orders", plan.Namespace)
+	require.NotNil(t, plan.WinningPlan)
+	assert.Equal(t, "COLLSCAN", plan.WinningPlan.Stage)
+	assert.Equal(t, int64(100), plan.TotalDocsExamined)
+	assert.Equal(t, []PipelineStage{{Name: "$group", NReturned: 3, ExecutionTimeMillis: 6}}, plan.PipelineStages)
+	assert.Equal(t, int64(3), plan.NReturned)
+	assert.Equal(t, int64(6), plan.ExecutionTimeMillis)
+}
+
+func TestParseExplainSharded(t *testing.T) {
+	explain := primitive.M{
+		"queryPlanner": primitive.M{
+			"winningPlan": primitive.M{
+				"stage": "SHARD_MERGE",
+				"shards": primitive.A{
+					primitive.M{
+						"shardName":   "shard01",
+						"winningPlan": primitive.M{"stage": "IXSCAN", "indexName": "_id_"},
+					},
+				},
+			},
+		},
+	}
+
+	plan := ParseExplain(explain)
+
+	require.NotNil(t, plan.WinningPlan)
+	assert.Equal(t, "SHARD_MERGE", plan.WinningPlan.Stage)
+	require.Len(t, plan.WinningPlan.Children, 1)
+	assert.Equal(t, "shard01: IXSCAN", plan.WinningPlan.Children[0].Stage)
+	assert.Equal(t, []string{"_id_"}, plan.UsedIndexes())
+}
@@ -0,0 +1,243 @@
+package mongo
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FileFormat is a file format that documents can be exported to or imported from
+type FileFormat string
+
+const (
+	FormatJSON   FileFormat = "JSON"
+	FormatNDJSON FileFormat = "NDJSON"
+	FormatCSV    FileFormat = "CSV"
+	FormatBSON   FileFormat = "BSON"
+)
+
+// ExportFormats lists all supported formats in the order they are presented to the user
+var ExportFormats = []FileFormat{FormatJSON, FormatNDJSON, FormatCSV, FormatBSON}
+
+// FileFormatFromPath guesses the format based on the file extension
+func FileFormatFromPath(path string) (FileFormat, bool) {
+	for _, format := range ExportFormats {
+		if strings.HasSuffix(strings.ToLower(path), format.Extension()) {
+			return format, true
+		}
+	}
+	return "", false
+}
+
+// Extension returns the default file extension for the format
+func (f FileFormat) Extension() string {
+	switch f {
+	case FormatNDJSON:
+		return ".ndjson"
+	case FormatCSV:
+		return ".csv"
+	case FormatBSON:
+		return ".bson"
+	default:
+		return ".json"
+	}
+}
+
+// documentWriter writes exported documents one by one, Close flushes
+// anything that was buffered and writes closing characters if format needs them
+type documentWriter interface {
+	WriteDocument(doc bson.Raw) error
+	Close() error
+}
+
+// newDocumentWriter returns writer for the given format, columns are used only by CSV
+func newDocumentWriter(w io.Writer, format FileFormat, columns []string) (documentWriter, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonArrayWriter{w: bufio.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return &ndjsonWriter{w: bufio.NewWriter(w)}, nil
+	case FormatCSV:
+		cw := &csvWriter{w: csv.NewWriter(w), columns: columns}
+		if err := cw.w.Write(columns); err != nil {
+			return nil, err
+		}
+		return cw, nil
+	case FormatBSON:
+		return &bsonWriter{w: bufio.NewWriter(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// jsonArrayWriter writes documents as an Extended JSON array
+type jsonArrayWriter struct {
+	w       *bufio.Writer
+	written bool
+}
+
+func (j *jsonArrayWriter) WriteDocument(doc bson.Raw) error {
+	jsonBytes, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return err
+	}
+	prefix := ",\n  "
+	if !j.written {
+		prefix = "[\n  "
+		j.written = true
+	}
+	if _, err := j.w.WriteString(prefix); err != nil {
+		return err
+	}
+	_, err = j.w.Write(jsonBytes)
+	return err
+}
+
+func (j *jsonArrayWriter) Close() error {
+	closing := "\n]\n"
+	if !j.written {
+		closing = "[]\n"
+	}
+	if _, err := j.w.WriteString(closing); err != nil {
+		return err
+	}
+	return j.w.Flush()
+}
+
+// ndjsonWriter writes every document as Extended JSON in a separate line
+type ndjsonWriter struct {
+	w *bufio.Writer
+}
+
+func (n *ndjsonWriter) WriteDocument(doc bson.Raw) error {
+	jsonBytes, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return err
+	}
+	if _, err := n.w.Write(jsonBytes); err != nil {
+		return err
+	}
+	return n.w.WriteByte('\n')
+}
+
+func (n *ndjsonWriter) Close() error {
+	return n.w.Flush()
+}
+
+// bsonWriter writes raw documents one after another, the same way mongodump does
+type bsonWriter struct {
+	w *bufio.Writer
+}
+
+func (b *bsonWriter) WriteDocument(doc bson.Raw) error {
+	_, err := b.w.Write(doc)
+	return err
+}
+
+func (b *bsonWriter) Close() error {
+	return b.w.Flush()
+}
+
+// csvWriter writes flattened documents, every nested field becomes
+// a separate column named with a dotted path
+type csvWriter struct {
+	w       *csv.Writer
+	columns []string
+}
+
+func (c *csvWriter) WriteDocument(doc bson.Raw) error {
+	var decoded primitive.D
+	if err := bson.Unmarshal(doc, &decoded); err != nil {
+		return err
+	}
+	flat := FlattenDocument(decoded)
+
+	record := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		record[i] = flat[column]
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// FlattenDocument converts nested documents into a single level map where keys
+// are dotted paths (e.g. address.city) and values are stringified, arrays are kept as JSON
+func FlattenDocument(doc primitive.D) map[string]string {
+	flat := make(map[string]string)
+	flattenInto(flat, "", doc)
+	return flat
+}
+
+func flattenInto(flat map[string]string, prefix string, doc primitive.D) {
+	for _, elem := range doc {
+		key := elem.Key
+		if prefix != "" {
+			key = prefix + "." + elem.Key
+		}
+		switch v := elem.Value.(type) {
+		case primitive.D:
+			flattenInto(flat, key, v)
+		case primitive.M:
+			flattenInto(flat, key, sortDocumentKeys(v))
+		default:
+			flat[key] = stringifyCsvValue(v)
+		}
+	}
+}
+
+// flattenedColumns returns sorted set of dotted paths, _id always goes first
+func flattenedColumns(columns map[string]struct{}) []string {
+	sorted := make([]string, 0, len(columns))
+	for column := range columns {
+		sorted = append(sorted, column)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i] == "_id" || sorted[j] == "_id" {
+			return sorted[i] == "_id"
+		}
+		return sorted[i] < sorted[j]
+	})
+	return sorted
+}
+
+func stringifyCsvValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC().Format("2006-01-02T15:04:05.000Z")
+	case primitive.Decimal128:
+		return v.String()
+	default:
+		// wrap value in a document, so every other type can be marshaled to relaxed Extended JSON
+		jsonBytes, err := bson.MarshalExtJSON(primitive.D{{Key: "v", Value: v}}, false, false)
+		if err != nil {
+			return fmt.Sprintf("%v", v)
+		}
+		jsonValue := strings.TrimPrefix(string(jsonBytes), `{"v":`)
+		return strings.TrimSuffix(jsonValue, "}")
+	}
+}
@@ -0,0 +1,129 @@
+package mongo
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func mustMarshal(t *testing.T, doc any) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(doc)
+	require.NoError(t, err)
+	return raw
+}
+
+func writeDocuments(t *testing.T, format FileFormat, columns []string, docs ...primitive.D) string {
+	t.Helper()
+	var buf bytes.Buffer
+	writer, err := newDocumentWriter(&buf, format, columns)
+	require.NoError(t, err)
+	for _, doc := range docs {
+		require.NoError(t, writer.WriteDocument(mustMarshal(t, doc)))
+	}
+	require.NoError(t, writer.Close())
+	return buf.String()
+}
+
+func TestJsonArrayWriter(t *testing.T) {
+	result := writeDocuments(t, FormatJSON, nil,
+		primitive.D{{Key: "_id", Value: 1}, {Key: "name", Value: "a"}},
+		primitive.D{{Key: "_id", Value: 2}, {Key: "name", Value: "b"}},
+	)
+	assert.Equal(t, "[\n  {\"_id\":1,\"name\":\"a\"},\n  {\"_id\":2,\"name\":\"b\"}\n]\n", result)
+
+	empty := writeDocuments(t, FormatJSON, nil)
+	assert.Equal(t, "[]\n", empty)
+}
+
+func TestNdjsonWriter(t *testing.T) {
+	oid, err := primitive.ObjectIDFromHex("507f1f77bcf86cd799439011")
+	require.NoError(t, err)
+
+	result := writeDocuments(t, FormatNDJSON, nil,
+		primitive.D{{Key: "_id", Value: oid}},
+		primitive.D{{Key: "_id", Value: "str"}},
+	)
+	assert.Equal(t, "{\"_id\":{\"$oid\":\"507f1f77bcf86cd799439011\"}}\n{\"_id\":\"str\"}\n", result)
+}
+
+func TestBsonWriter(t *testing.T) {
+	first := primitive.D{{Key: "_id", Value: 1}}
+	second := primitive.D{{Key: "_id", Value: 2}}
+
+	result := writeDocuments(t, FormatBSON, nil, first, second)
+
+	expected := append(mustMarshal(t, first), mustMarshal(t, second)...)
+	assert.Equal(t, string(expected), result)
+}
+
+func TestCsvWriter(t *testing.T) {
+	columns := []string{"_id", "address.city", "name", "tags"}
+	result := writeDocuments(t, FormatCSV, columns,
+		primitive.D{
+			{Key: "_id", Value: 1},
+			{Key: "name", Value: "Doe, John"},
+			{Key: "address", Value: primitive.D{{Key: "city", Value: "Paris"}}},
+			{Key: "tags", Value: primitive.A{"a", "b"}},
+		},
+		primitive.D{{Key: "_id", Value: 2}},
+	)
+
+	expected := "_id,address.city,name,tags\n" +
+		"1,Paris,\"Doe, John\",\"[\"\"a\"\",\"\"b\"\"]\"\n" +
+		"2,,,\n"
+	assert.Equal(t, expected, result)
+}
+
+func TestFlattenDocument(t *testing.T) {
+	oid, err := primitive.ObjectIDFromHex("507f1f77bcf86cd799439011")
+	require.NoError(t, err)
+	date := primitive.NewDateTimeFromTime(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	doc := primitive.D{
+		{Key: "_id", Value: oid},
+		{Key: "age", Value: int32(30)},
+		{Key: "score", Value: 9.5},
+		{Key: "active", Value: true},
+		{Key: "created", Value: date},
+		{Key: "missing", Value: nil},
+		{Key: "address", Value: primitive.D{
+			{Key: "city", Value: "Paris"},
+			{Key: "geo", Value: primitive.M{"lat": 1.5}},
+		}},
+	}
+
+	expected := map[string]string{
+		"_id":             "507f1f77bcf86cd799439011",
+		"age":             "30",
+		"score":           "9.5",
+		"active":          "true",
+		"created":         "2024-01-02T03:04:05.000Z",
+		"missing":         "",
+		"address.city":    "Paris",
+		"address.geo.lat": "1.5",
+	}
+	assert.Equal(t, expected, FlattenDocument(doc))
+}
+
+func TestFlattenedColumns(t *testing.T) {
+	columns := map[string]struct{}{
+		"name":         {},
+		"_id":          {},
+		"Age":          {},
+		"address.city": {},
+	}
+	assert.Equal(t, []string{"_id", "Age", "address.city", "name"}, flattenedColumns(columns))
+}
+
+func TestExportFormatExtension(t *testing.T) {
+	assert.Equal(t, ".json", FormatJSON.Extension())
+	assert.Equal(t, ".ndjson", FormatNDJSON.Extension())
+	assert.Equal(t, ".csv", FormatCSV.Extension())
+	assert.Equal(t, ".bson", FormatBSON.Extension())
+}
@@ -0,0 +1,87 @@
+package mongo
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CollectionTypeGridFSBucket marks a GridFS bucket, a pair of <bucket>.files
+// and <bucket>.chunks collections shown as a single node
+const CollectionTypeGridFSBucket = "gridfs"
+
+const (
+	gridFSFilesSuffix  = ".files"
+	gridFSChunksSuffix = ".chunks"
+)
+
+// GridFSCollections returns names of the files and chunks collections of the bucket
+func GridFSCollections(bucket string) (files, chunks string) {
+	return bucket + gridFSFilesSuffix, bucket + gridFSChunksSuffix
+}
+
+// GridFSFile is a file stored in a GridFS bucket
+type GridFSFile struct {
+	ID         any
+	Filename   string
+	Length     int64
+	ChunkSize  int64
+	UploadDate time.Time
+	Metadata   primitive.M
+}
+
+// ParseGridFSFile converts the document from the files collection into GridFSFile
+func ParseGridFSFile(doc primitive.M) GridFSFile {
+	file := GridFSFile{
+		ID:        doc["_id"],
+		Length:    toInt64(doc["length"]),
+		ChunkSize: toInt64(doc["chunkSize"]),
+	}
+	file.Filename, _ = doc["filename"].(string)
+	if uploadDate, ok := doc["uploadDate"].(primitive.DateTime); ok {
+		file.UploadDate = uploadDate.Time()
+	}
+	file.Metadata, _ = doc["metadata"].(primitive.M)
+	return file
+}
+
+// MetadataJson returns the metadata as compact JSON, empty when the file has no metadata
+func (f GridFSFile) MetadataJson() string {
+	if len(f.Metadata) == 0 {
+		return ""
+	}
+	return compactJson(f.Metadata)
+}
+
+// GroupGridFSBuckets replaces pairs of <bucket>.files and <bucket>.chunks collections
+// with the bucket name and marks it in types
+func GroupGridFSBuckets(names []string, types map[string]string) []string {
+	existing := make(map[string]bool, len(names))
+	for _, name := range names {
+		existing[name] = true
+	}
+
+	buckets := map[string]bool{}
+	for _, name := range names {
+		bucket, ok := strings.CutSuffix(name, gridFSFilesSuffix)
+		if !ok || bucket == "" || existing[bucket] || !existing[bucket+gridFSChunksSuffix] {
+			continue
+		}
+		buckets[bucket] = true
+	}
+
+	grouped := make([]string, 0, len(names))
+	for _, name := range names {
+		if bucket, ok := strings.CutSuffix(name, gridFSFilesSuffix); ok && buckets[bucket] {
+			grouped = append(grouped, bucket)
+			types[bucket] = CollectionTypeGridFSBucket
+			continue
+		}
+		if bucket, ok := strings.CutSuffix(name, gridFSChunksSuffix); ok && buckets[bucket] {
+			continue
+		}
+		grouped = append(grouped, name)
+	}
+	return grouped
+}
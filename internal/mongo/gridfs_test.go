@@ -0,0 +1,52 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGroupGridFSBuckets(t *testing.T) {
+	names := []string{"avatars.chunks", "avatars.files", "fs.chunks", "fs.files", "logs.files", "users"}
+	types := map[string]string{}
+
+	grouped := GroupGridFSBuckets(names, types)
+	assert.Equal(t, []string{"avatars", "fs", "logs.files", "users"}, grouped)
+	assert.Equal(t, map[string]string{"avatars": CollectionTypeGridFSBucket, "fs": CollectionTypeGridFSBucket}, types)
+
+	// collection with the bucket name prevents grouping
+	types = map[string]string{}
+	grouped = GroupGridFSBuckets([]string{"fs", "fs.chunks", "fs.files"}, types)
+	assert.Equal(t, []string{"fs", "fs.chunks", "fs.files"}, grouped)
+	assert.Empty(t, types)
+}
+
+func TestParseGridFSFile(t *testing.T) {
+	id := primitive.NewObjectID()
+	uploaded := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	file := ParseGridFSFile(primitive.M{
+		"_id":        id,
+		"filename":   "report.pdf",
+		"length":     int64(1048576),
+		"chunkSize":  int32(261120),
+		"uploadDate": primitive.NewDateTimeFromTime(uploaded),
+		"metadata":   primitive.M{"owner": "alice"},
+	})
+	assert.Equal(t, id, file.ID)
+	assert.Equal(t, "report.pdf", file.Filename)
+	assert.Equal(t, int64(1048576), file.Length)
+	assert.Equal(t, int64(261120), file.ChunkSize)
+	assert.Equal(t, uploaded, file.UploadDate.UTC())
+	assert.Equal(t, primitive.M{"owner": "alice"}, file.Metadata)
+	assert.Equal(t, `{"owner":"alice"}`, file.MetadataJson())
+
+	file = ParseGridFSFile(primitive.M{"_id": "custom", "length": int32(10)})
+	assert.Equal(t, "custom", file.ID)
+	assert.Equal(t, int64(10), file.Length)
+	assert.True(t, file.UploadDate.IsZero())
+	assert.Nil(t, file.Metadata)
+	assert.Empty(t, file.MetadataJson())
+}
@@ -1,58 +1,60 @@
 package mongo
 
 import (
-	"encoding/json"
 	"fmt"
+	"math"
+	"reflect"
 
+	"github.com/kopecmaciej/vi-mongo/internal/util"
 	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// GetIDFromJSON returns the _id field of a JSON string as a primitive.ObjectID.
+// GetIDFromJSON returns the _id field of a mongo compatible JSON string,
+// extended JSON types like $oid, $binary or $numberLong are decoded to their BSON types
 func GetIDFromJSON(jsonString string) (any, error) {
-	var doc map[string]any
-	err := json.Unmarshal([]byte(jsonString), &doc)
+	var doc primitive.M
+	err := bson.UnmarshalExtJSON([]byte(jsonString), false, &doc)
 	if err != nil {
 		log.Error().Err(err).Msg("Error unmarshaling JSON")
 		return nil, err
 	}
 
-	objectID, err := getIdFromDocument(doc)
+	id, err := getIdFromDocument(doc)
 	if err != nil {
-		log.Error().Err(err).Msg("Error converting _id to ObjectID")
+		log.Error().Err(err).Msg("Error getting _id from document")
 		return nil, err
 	}
 
-	return objectID, nil
+	return id, nil
 }
 
-// getIdFromDocument returns the _id field of a document as a primitive.ObjectID
+// getIdFromDocument returns the _id field of a document, plain maps with
+// extended JSON fields (e.g. {"$oid": "..."}) are converted to their BSON types
 func getIdFromDocument(document map[string]any) (any, error) {
 	rawId, ok := document["_id"]
 	if !ok {
 		return nil, fmt.Errorf("document has no _id")
 	}
-	var id any
-	switch typedId := rawId.(type) {
-	case primitive.ObjectID:
-		return typedId, nil
-	case string:
-		id = typedId
-	case map[string]any:
-		oidString, ok := typedId["$oid"].(string)
-		if !ok {
-			return nil, fmt.Errorf("invalid $oid field in _id")
-		}
-		objectId, err := primitive.ObjectIDFromHex(oidString)
-		if err != nil {
-			return nil, fmt.Errorf("invalid ObjectID: %w", err)
-		}
-		id = objectId
-	default:
-		return nil, fmt.Errorf("document _id is not a string or primitive.ObjectID")
+
+	typedId, ok := rawId.(map[string]any)
+	if !ok {
+		return rawId, nil
 	}
 
-	return id, nil
+	// round trip through extended JSON, so wrappers are decoded by the driver
+	jsonBytes, err := bson.MarshalExtJSON(primitive.M{"_id": typedId}, false, false)
+	if err != nil {
+		return nil, fmt.Errorf("invalid _id: %w", err)
+	}
+	var doc primitive.M
+	if err := bson.UnmarshalExtJSON(jsonBytes, false, &doc); err != nil {
+		return nil, fmt.Errorf("invalid _id: %w", err)
+	}
+
+	return doc["_id"], nil
 }
 
 // StringifyId converts the _id field of a document to a string
@@ -60,7 +62,96 @@ func StringifyId(id any) string {
 	switch v := id.(type) {
 	case primitive.ObjectID:
 		return v.Hex()
+	case primitive.Binary:
+		if uuid, ok := util.FormatUUID(v); ok {
+			return uuid
+		}
+		return stringifyExtJson(v)
+	case primitive.M:
+		return stringifyExtJson(sortDocumentKeys(v))
+	case primitive.D, primitive.A:
+		return stringifyExtJson(v)
 	default:
 		return fmt.Sprintf("%v", v)
 	}
 }
+
+func stringifyExtJson(value any) string {
+	bytes, err := bson.MarshalExtJSON(primitive.M{"v": value}, false, false)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	// strip the wrapping document, MarshalExtJSON accepts documents only
+	return string(bytes[len(`{"v":`) : len(bytes)-1])
+}
+
+// IdsEqual reports whether both _id values point to the same document.
+// Numbers are compared by value, as parsing relaxed JSON can change their type
+// (e.g. int64 to int32), and compound ids are compared regardless of being
+// decoded into primitive.D or primitive.M
+func IdsEqual(a, b any) bool {
+	return reflect.DeepEqual(normalizeId(a), normalizeId(b))
+}
+
+func normalizeId(id any) any {
+	switch v := id.(type) {
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < math.MaxInt64 {
+			return int64(v)
+		}
+		return v
+	case primitive.D:
+		normalized := make(primitive.M, len(v))
+		for _, elem := range v {
+			normalized[elem.Key] = normalizeId(elem.Value)
+		}
+		return normalized
+	case primitive.M:
+		normalized := make(primitive.M, len(v))
+		for key, value := range v {
+			normalized[key] = normalizeId(value)
+		}
+		return normalized
+	case map[string]any:
+		return normalizeId(primitive.M(v))
+	case primitive.A:
+		normalized := make(primitive.A, len(v))
+		for i, value := range v {
+			normalized[i] = normalizeId(value)
+		}
+		return normalized
+	default:
+		return id
+	}
+}
+
+// decodeDocument decodes a raw document into primitive.M, a compound _id is
+// decoded into primitive.D as its key order is part of the value and it
+// has to be kept to query the document by _id later
+func decodeDocument(raw bson.Raw) (primitive.M, error) {
+	var doc primitive.M
+	if err := bson.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	if id, ok := orderedId(raw); ok {
+		doc["_id"] = id
+	}
+	return doc, nil
+}
+
+// orderedId returns the _id of a raw document as primitive.D when it's an embedded document
+func orderedId(raw bson.Raw) (primitive.D, bool) {
+	value, err := raw.LookupErr("_id")
+	if err != nil || value.Type != bsontype.EmbeddedDocument {
+		return nil, false
+	}
+	var id primitive.D
+	if err := value.Unmarshal(&id); err != nil {
+		return nil, false
+	}
+	return id, true
+}
@@ -4,9 +4,15 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+var testUUID = primitive.Binary{
+	Subtype: bson.TypeBinaryUUID,
+	Data:    []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00},
+}
+
 func TestGetIDFromJSON(t *testing.T) {
 	t.Run("Valid JSON with ObjectID", func(t *testing.T) {
 		jsonString := `{"_id": {"$oid": "5f8f9e5f1c9d440000d1b3c5"}}`
@@ -21,6 +27,27 @@ func TestGetIDFromJSON(t *testing.T) {
 		_, err := GetIDFromJSON(jsonString)
 		assert.Error(t, err)
 	})
+
+	t.Run("UUID", func(t *testing.T) {
+		jsonString := `{"_id": {"$binary": {"base64": "Ej5FZ+ibEtOkVkJmFBdAAA==", "subType": "04"}}}`
+		id, err := GetIDFromJSON(jsonString)
+		assert.NoError(t, err)
+		assert.Equal(t, testUUID, id)
+	})
+
+	t.Run("Int64", func(t *testing.T) {
+		jsonString := `{"_id": {"$numberLong": "9007199254740993"}}`
+		id, err := GetIDFromJSON(jsonString)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(9007199254740993), id)
+	})
+
+	t.Run("Compound", func(t *testing.T) {
+		jsonString := `{"_id": {"region": "eu", "seq": 7}}`
+		id, err := GetIDFromJSON(jsonString)
+		assert.NoError(t, err)
+		assert.Equal(t, primitive.M{"region": "eu", "seq": int32(7)}, id)
+	})
 }
 
 func TestGetIDFromDocument(t *testing.T) {
@@ -47,6 +74,20 @@ func TestGetIDFromDocument(t *testing.T) {
 		assert.Equal(t, "5f8f9e5f1c9d440000d1b3c5", id.(primitive.ObjectID).Hex())
 	})
 
+	t.Run("Document with int64 ID", func(t *testing.T) {
+		doc := map[string]any{"_id": int64(42)}
+		id, err := getIdFromDocument(doc)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), id)
+	})
+
+	t.Run("Document with UUID", func(t *testing.T) {
+		doc := map[string]any{"_id": testUUID}
+		id, err := getIdFromDocument(doc)
+		assert.NoError(t, err)
+		assert.Equal(t, testUUID, id)
+	})
+
 	t.Run("Document without _id", func(t *testing.T) {
 		doc := map[string]any{"name": "John"}
 		_, err := getIdFromDocument(doc)
@@ -71,4 +112,85 @@ func TestStringifyId(t *testing.T) {
 		result := StringifyId(123456)
 		assert.Equal(t, "123456", result)
 	})
+
+	t.Run("UUID", func(t *testing.T) {
+		result := StringifyId(testUUID)
+		assert.Equal(t, "123e4567-e89b-12d3-a456-426614174000", result)
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		result := StringifyId(primitive.Binary{Subtype: 0, Data: []byte{1, 2, 3, 4}})
+		assert.Equal(t, `{"$binary":{"base64":"AQIDBA==","subType":"00"}}`, result)
+	})
+
+	t.Run("Compound", func(t *testing.T) {
+		ordered := StringifyId(primitive.D{{Key: "seq", Value: int64(7)}, {Key: "region", Value: "eu"}})
+		assert.Equal(t, `{"seq":7,"region":"eu"}`, ordered)
+
+		unordered := StringifyId(primitive.M{"seq": int64(7), "region": "eu"})
+		assert.Equal(t, `{"region":"eu","seq":7}`, unordered)
+	})
+}
+
+func TestIdsEqual(t *testing.T) {
+	oid := primitive.NewObjectID()
+
+	testCases := []struct {
+		name  string
+		a, b  any
+		equal bool
+	}{
+		{"Same ObjectID", oid, oid, true},
+		{"Different ObjectID", oid, primitive.NewObjectID(), false},
+		{"Int64 and int32", int64(5), int32(5), true},
+		{"Int64 and whole float", int64(5), float64(5), true},
+		{"Different ints", int64(5), int32(6), false},
+		{"Fractional float", float64(5.5), int64(5), false},
+		{"Number and string", int64(5), "5", false},
+		{"UUID", testUUID, primitive.Binary{Subtype: testUUID.Subtype, Data: append([]byte{}, testUUID.Data...)}, true},
+		{"UUID and other subtype", testUUID, primitive.Binary{Subtype: 0, Data: testUUID.Data}, false},
+		{
+			"Compound D and M",
+			primitive.D{{Key: "region", Value: "eu"}, {Key: "seq", Value: int64(7)}},
+			primitive.M{"seq": int32(7), "region": "eu"},
+			true,
+		},
+		{
+			"Compound different values",
+			primitive.D{{Key: "region", Value: "eu"}, {Key: "seq", Value: int64(7)}},
+			primitive.M{"region": "us", "seq": int64(7)},
+			false,
+		},
+		{"Nil", nil, nil, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.equal, IdsEqual(tc.a, tc.b))
+		})
+	}
+}
+
+func TestDecodeDocument(t *testing.T) {
+	t.Run("Compound _id keeps key order", func(t *testing.T) {
+		id := primitive.D{{Key: "seq", Value: int64(7)}, {Key: "region", Value: "eu"}}
+		raw, err := bson.Marshal(primitive.D{{Key: "_id", Value: id}, {Key: "name", Value: "order"}})
+		assert.NoError(t, err)
+
+		doc, err := decodeDocument(raw)
+		assert.NoError(t, err)
+		assert.Equal(t, id, doc["_id"])
+		assert.Equal(t, "order", doc["name"])
+	})
+
+	t.Run("Scalar _id is kept as is", func(t *testing.T) {
+		for _, id := range []any{primitive.NewObjectID(), int64(9007199254740993), testUUID, "key"} {
+			raw, err := bson.Marshal(primitive.M{"_id": id})
+			assert.NoError(t, err)
+
+			doc, err := decodeDocument(raw)
+			assert.NoError(t, err)
+			assert.Equal(t, id, doc["_id"])
+		}
+	})
 }
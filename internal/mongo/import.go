@@ -0,0 +1,339 @@
+package mongo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ImportFormats lists all formats that can be imported
+var ImportFormats = []FileFormat{FormatJSON, FormatNDJSON, FormatCSV}
+
+const (
+	defaultImportBatchSize = 1000
+	// maxImportErrors limits how many error messages are kept in the report
+	maxImportErrors = 10
+	// maxNdjsonLineSize is the maximum size of a BSON document
+	maxNdjsonLineSize = 16 * 1024 * 1024
+)
+
+// ImportOptions controls how documents are read from the file and written to the collection
+type ImportOptions struct {
+	Format FileFormat
+	// Ordered stops the import on the first failed document
+	Ordered bool
+	// UpsertById replaces documents with the same _id instead of failing on duplicates
+	UpsertById bool
+	// InferTypes converts CSV values to numbers, booleans, dates, ObjectIDs etc.
+	// when disabled every CSV value is imported as a string
+	InferTypes bool
+	BatchSize  int
+}
+
+// ImportResult is a summary of the import
+type ImportResult struct {
+	Read     int64
+	Inserted int64
+	Updated  int64
+	Failed   int64
+	Errors   []string
+}
+
+func (r *ImportResult) addFailure(count int64, msg string) {
+	r.Failed += count
+	r.addError(msg)
+}
+
+func (r *ImportResult) addError(msg string) {
+	if len(r.Errors) < maxImportErrors {
+		r.Errors = append(r.Errors, msg)
+	}
+}
+
+// rowError is returned by the documentReader when a single row is invalid,
+// but reading can be continued
+type rowError struct {
+	row int
+	err error
+}
+
+func (e *rowError) Error() string {
+	return fmt.Sprintf("row %d: %v", e.row, e.err)
+}
+
+func (e *rowError) Unwrap() error {
+	return e.err
+}
+
+// documentReader reads documents one by one, Next returns io.EOF when there
+// is nothing left and *rowError when only the current document is invalid
+type documentReader interface {
+	Next() (primitive.M, error)
+	// Row returns the number of the row (or document in JSON array) that was read last
+	Row() int
+}
+
+func newDocumentReader(r io.Reader, opts ImportOptions) (documentReader, error) {
+	switch opts.Format {
+	case FormatJSON:
+		return newJsonReader(r)
+	case FormatNDJSON:
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 0, 64*1024), maxNdjsonLineSize)
+		return &ndjsonReader{scanner: scanner}, nil
+	case FormatCSV:
+		return newCsvReader(r, opts.InferTypes)
+	default:
+		return nil, fmt.Errorf("unsupported import format: %s", opts.Format)
+	}
+}
+
+// jsonReader reads JSON array of documents or documents written one after another
+type jsonReader struct {
+	dec  *json.Decoder
+	done bool
+	row  int
+}
+
+func newJsonReader(r io.Reader) (*jsonReader, error) {
+	br := bufio.NewReader(r)
+	first, err := peekFirstNonSpace(br)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	j := &jsonReader{dec: json.NewDecoder(br)}
+	if first == '[' {
+		if _, err := j.dec.Token(); err != nil {
+			return nil, fmt.Errorf("invalid JSON array: %w", err)
+		}
+	}
+	return j, nil
+}
+
+func peekFirstNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\n' && b != '\r' && b != '\t' {
+			return b, br.UnreadByte()
+		}
+	}
+}
+
+func (j *jsonReader) Next() (primitive.M, error) {
+	if j.done || !j.dec.More() {
+		j.done = true
+		return nil, io.EOF
+	}
+
+	var raw json.RawMessage
+	if err := j.dec.Decode(&raw); err != nil {
+		// syntax errors can't be recovered, decoder would not know where next document starts
+		return nil, fmt.Errorf("invalid JSON after document %d: %w", j.row, err)
+	}
+	j.row++
+
+	doc, err := ParseJsonToBson(string(raw))
+	if err != nil {
+		return nil, &rowError{row: j.row, err: err}
+	}
+	return doc, nil
+}
+
+func (j *jsonReader) Row() int {
+	return j.row
+}
+
+// ndjsonReader reads one document per line, empty lines are skipped
+type ndjsonReader struct {
+	scanner *bufio.Scanner
+	row     int
+}
+
+func (n *ndjsonReader) Next() (primitive.M, error) {
+	for n.scanner.Scan() {
+		n.row++
+		line := bytes.TrimSpace(n.scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		doc, err := ParseJsonToBson(string(line))
+		if err != nil {
+			return nil, &rowError{row: n.row, err: err}
+		}
+		return doc, nil
+	}
+	if err := n.scanner.Err(); err != nil {
+		return nil, err
+	}
+	return nil, io.EOF
+}
+
+func (n *ndjsonReader) Row() int {
+	return n.row
+}
+
+// csvReader reads documents from CSV file, header columns with dotted
+// paths (as produced by the export) are converted back to nested documents
+type csvReader struct {
+	reader     *csv.Reader
+	header     []string
+	inferTypes bool
+	row        int
+}
+
+func newCsvReader(r io.Reader, inferTypes bool) (*csvReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("CSV file is empty, header row is required")
+		}
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	for i, column := range header {
+		header[i] = strings.TrimSpace(column)
+	}
+
+	return &csvReader{reader: reader, header: header, inferTypes: inferTypes, row: 1}, nil
+}
+
+func (c *csvReader) Next() (primitive.M, error) {
+	record, err := c.reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		c.row++
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, &rowError{row: c.row, err: err}
+		}
+		return nil, err
+	}
+	c.row++
+
+	if len(record) != len(c.header) {
+		return nil, &rowError{row: c.row, err: fmt.Errorf("expected %d columns, got %d", len(c.header), len(record))}
+	}
+
+	doc := primitive.M{}
+	for i, column := range c.header {
+		if column == "" {
+			continue
+		}
+		var value any = record[i]
+		if c.inferTypes {
+			inferred, ok := inferCsvValue(record[i])
+			if !ok {
+				continue
+			}
+			value = inferred
+		}
+		if err := setDottedField(doc, column, value); err != nil {
+			return nil, &rowError{row: c.row, err: err}
+		}
+	}
+	return doc, nil
+}
+
+func (c *csvReader) Row() int {
+	return c.row
+}
+
+// inferCsvValue converts CSV value to the most fitting BSON type,
+// it returns false for empty values, which should be omitted from the document
+func inferCsvValue(value string) (any, bool) {
+	if value == "" {
+		return nil, false
+	}
+
+	switch value {
+	case "null":
+		return nil, true
+	case "true":
+		return true, true
+	case "false":
+		return false, true
+	}
+
+	if len(value) == 24 {
+		if oid, err := primitive.ObjectIDFromHex(value); err == nil {
+			return oid, true
+		}
+	}
+	// values like 007 or +5 are kept as strings, as they would not be the same after conversion
+	if !isPaddedNumber(value) {
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			if i >= -1<<31 && i <= 1<<31-1 {
+				return int32(i), true
+			}
+			return i, true
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil && strings.ContainsAny(value, "0123456789") {
+			return f, true
+		}
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return primitive.NewDateTimeFromTime(t), true
+	}
+	if strings.HasPrefix(value, "{") || strings.HasPrefix(value, "[") {
+		var wrapped primitive.M
+		if err := bson.UnmarshalExtJSON([]byte(`{"v":`+value+`}`), false, &wrapped); err == nil {
+			return wrapped["v"], true
+		}
+	}
+
+	return value, true
+}
+
+func isPaddedNumber(value string) bool {
+	if strings.HasPrefix(value, "+") {
+		return true
+	}
+	value = strings.TrimPrefix(value, "-")
+	return len(value) > 1 && value[0] == '0' && value[1] >= '0' && value[1] <= '9'
+}
+
+// setDottedField sets value in the document under the dotted path,
+// creating nested documents when needed
+func setDottedField(doc primitive.M, path string, value any) error {
+	fields := strings.Split(path, ".")
+	current := doc
+	for i, field := range fields[:len(fields)-1] {
+		existing, ok := current[field]
+		if !ok {
+			nested := primitive.M{}
+			current[field] = nested
+			current = nested
+			continue
+		}
+		nested, ok := existing.(primitive.M)
+		if !ok {
+			return fmt.Errorf("field %s conflicts with %s", path, strings.Join(fields[:i+1], "."))
+		}
+		current = nested
+	}
+
+	last := fields[len(fields)-1]
+	if _, ok := current[last].(primitive.M); ok {
+		return fmt.Errorf("field %s conflicts with nested fields", path)
+	}
+	current[last] = value
+	return nil
+}
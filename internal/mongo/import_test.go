@@ -0,0 +1,229 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type readResult struct {
+	docs      []primitive.M
+	failedRow []int
+}
+
+func readAll(t *testing.T, input string, opts ImportOptions) readResult {
+	t.Helper()
+	reader, err := newDocumentReader(strings.NewReader(input), opts)
+	require.NoError(t, err)
+
+	var result readResult
+	for {
+		doc, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return result
+		}
+		var rowErr *rowError
+		if errors.As(err, &rowErr) {
+			result.failedRow = append(result.failedRow, rowErr.row)
+			continue
+		}
+		require.NoError(t, err)
+		result.docs = append(result.docs, doc)
+	}
+}
+
+func TestJsonReader(t *testing.T) {
+	oid, err := primitive.ObjectIDFromHex("507f1f77bcf86cd799439011")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []primitive.M
+		failed   []int
+	}{
+		{
+			name:  "array with extended json",
+			input: `[{"_id": {"$oid": "507f1f77bcf86cd799439011"}, "n": 1}, {"n": {"$numberLong": "2"}}]`,
+			expected: []primitive.M{
+				{"_id": oid, "n": int32(1)},
+				{"n": int64(2)},
+			},
+		},
+		{
+			name:     "documents one after another",
+			input:    "{\"a\": 1}\n{\"a\": 2}",
+			expected: []primitive.M{{"a": int32(1)}, {"a": int32(2)}},
+		},
+		{
+			name:     "empty array",
+			input:    "  []  ",
+			expected: nil,
+		},
+		{
+			name:     "invalid extended json is reported as failed row",
+			input:    `[{"a": 1}, {"_id": {"$oid": "invalid"}}, {"a": 3}]`,
+			expected: []primitive.M{{"a": int32(1)}, {"a": int32(3)}},
+			failed:   []int{2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := readAll(t, tt.input, ImportOptions{Format: FormatJSON})
+			assert.Equal(t, tt.expected, result.docs)
+			assert.Equal(t, tt.failed, result.failedRow)
+		})
+	}
+}
+
+func TestJsonReaderSyntaxError(t *testing.T) {
+	reader, err := newDocumentReader(strings.NewReader(`[{"a": 1}, {"a": ]`), ImportOptions{Format: FormatJSON})
+	require.NoError(t, err)
+
+	_, err = reader.Next()
+	require.NoError(t, err)
+
+	_, err = reader.Next()
+	var rowErr *rowError
+	assert.Error(t, err)
+	assert.False(t, errors.As(err, &rowErr), "syntax error should stop reading")
+}
+
+func TestNdjsonReader(t *testing.T) {
+	input := "{\"a\": 1}\n\n{not json}\n{\"a\": 2}\n"
+
+	result := readAll(t, input, ImportOptions{Format: FormatNDJSON})
+
+	assert.Equal(t, []primitive.M{{"a": int32(1)}, {"a": int32(2)}}, result.docs)
+	assert.Equal(t, []int{3}, result.failedRow)
+}
+
+func TestCsvReader(t *testing.T) {
+	input := "_id,name,address.city,address.zip,tags\n" +
+		"507f1f77bcf86cd799439011,John,Paris,007,\"[\"\"a\"\"]\"\n" +
+		"2,,Berlin,10115,\n" +
+		"3,too,few\n"
+
+	t.Run("with type inference", func(t *testing.T) {
+		oid, err := primitive.ObjectIDFromHex("507f1f77bcf86cd799439011")
+		require.NoError(t, err)
+
+		result := readAll(t, input, ImportOptions{Format: FormatCSV, InferTypes: true})
+
+		expected := []primitive.M{
+			{
+				"_id":     oid,
+				"name":    "John",
+				"address": primitive.M{"city": "Paris", "zip": "007"},
+				"tags":    primitive.A{"a"},
+			},
+			{
+				"_id":     int32(2),
+				"address": primitive.M{"city": "Berlin", "zip": int32(10115)},
+			},
+		}
+		assert.Equal(t, expected, result.docs)
+		assert.Equal(t, []int{4}, result.failedRow)
+	})
+
+	t.Run("without type inference", func(t *testing.T) {
+		result := readAll(t, input, ImportOptions{Format: FormatCSV})
+
+		require.Len(t, result.docs, 2)
+		assert.Equal(t, primitive.M{
+			"_id":     "2",
+			"name":    "",
+			"address": primitive.M{"city": "Berlin", "zip": "10115"},
+			"tags":    "",
+		}, result.docs[1])
+	})
+}
+
+func TestCsvReaderEmptyFile(t *testing.T) {
+	_, err := newDocumentReader(strings.NewReader(""), ImportOptions{Format: FormatCSV})
+	assert.Error(t, err)
+}
+
+func TestInferCsvValue(t *testing.T) {
+	date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		input    string
+		expected any
+		ok       bool
+	}{
+		{"", nil, false},
+		{"null", nil, true},
+		{"true", true, true},
+		{"false", false, true},
+		{"42", int32(42), true},
+		{"-7", int32(-7), true},
+		{"3000000000", int64(3000000000), true},
+		{"007", "007", true},
+		{"+5", "+5", true},
+		{"1.5", 1.5, true},
+		{"NaN", "NaN", true},
+		{"2024-01-02T03:04:05.000Z", primitive.NewDateTimeFromTime(date), true},
+		{`{"a": 1}`, primitive.M{"a": int32(1)}, true},
+		{"{broken", "{broken", true},
+		{"hello", "hello", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			value, ok := inferCsvValue(tt.input)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestSetDottedField(t *testing.T) {
+	doc := primitive.M{}
+
+	require.NoError(t, setDottedField(doc, "a.b.c", 1))
+	require.NoError(t, setDottedField(doc, "a.d", 2))
+	assert.Equal(t, primitive.M{"a": primitive.M{"b": primitive.M{"c": 1}, "d": 2}}, doc)
+
+	assert.Error(t, setDottedField(doc, "a.d.e", 3))
+	assert.Error(t, setDottedField(doc, "a.b", 4))
+}
+
+func TestFileFormatFromPath(t *testing.T) {
+	tests := map[string]FileFormat{
+		"users.json":   FormatJSON,
+		"users.NDJSON": FormatNDJSON,
+		"users.csv":    FormatCSV,
+		"dump.bson":    FormatBSON,
+	}
+	for path, expected := range tests {
+		format, ok := FileFormatFromPath(path)
+		assert.True(t, ok, path)
+		assert.Equal(t, expected, format, path)
+	}
+
+	_, ok := FileFormatFromPath("users.txt")
+	assert.False(t, ok)
+}
+
+func TestInsertedCount(t *testing.T) {
+	bulkErr := mongo.BulkWriteException{WriteErrors: []mongo.BulkWriteError{
+		{WriteError: mongo.WriteError{Index: 3, Code: 11000}},
+		{WriteError: mongo.WriteError{Index: 7, Code: 11000}},
+	}}
+
+	assert.Equal(t, int64(10), insertedCount(10, true, nil))
+	assert.Equal(t, int64(8), insertedCount(10, false, bulkErr))
+	assert.Equal(t, int64(3), insertedCount(10, true, bulkErr))
+	assert.Equal(t, int64(0), insertedCount(10, false, context.Canceled))
+	assert.Equal(t, int64(0), insertedCount(10, true, errors.New("connection reset")))
+}
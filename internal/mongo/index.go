@@ -0,0 +1,303 @@
+package mongo
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Index key types accepted by IndexDefinition
+const (
+	IndexKeyAscending  = "1"
+	IndexKeyDescending = "-1"
+	IndexKeyText       = "text"
+	IndexKey2dsphere   = "2dsphere"
+	IndexKey2d         = "2d"
+	IndexKeyHashed     = "hashed"
+	// IndexKeyWildcard indexes all fields under the path, or all fields when path is empty
+	IndexKeyWildcard = "wildcard"
+)
+
+// IndexKeyTypes are all supported index key types
+var IndexKeyTypes = []string{
+	IndexKeyAscending,
+	IndexKeyDescending,
+	IndexKeyText,
+	IndexKey2dsphere,
+	IndexKey2d,
+	IndexKeyHashed,
+	IndexKeyWildcard,
+}
+
+const wildcardSuffix = "$**"
+
+// IndexKey is a single field of the index
+type IndexKey struct {
+	Field string
+	Type  string
+}
+
+// IndexDefinition describes an index to create
+type IndexDefinition struct {
+	Keys   []IndexKey
+	Name   string
+	Unique bool
+	Sparse bool
+	Hidden bool
+	// ExpireAfterSeconds is set for TTL indexes, nil means no expiration
+	ExpireAfterSeconds *int32
+	// PartialFilter, WildcardProjection and Weights are JSON documents
+	PartialFilter      string
+	WildcardProjection string
+	Weights            string
+	CollationLocale    string
+	DefaultLanguage    string
+}
+
+// ToIndexModel validates the definition and converts it into the index model
+func (d IndexDefinition) ToIndexModel() (mongo.IndexModel, error) {
+	keys, err := d.keys()
+	if err != nil {
+		return mongo.IndexModel{}, err
+	}
+
+	opts := options.Index()
+	if d.Name != "" {
+		opts.SetName(d.Name)
+	}
+	if d.Unique {
+		if d.hasKeyType(IndexKeyHashed) || d.hasKeyType(IndexKeyWildcard) {
+			return mongo.IndexModel{}, fmt.Errorf("hashed and wildcard indexes can't be unique")
+		}
+		opts.SetUnique(true)
+	}
+	if d.Sparse {
+		if strings.TrimSpace(d.PartialFilter) != "" {
+			return mongo.IndexModel{}, fmt.Errorf("index can't be both sparse and partial")
+		}
+		opts.SetSparse(true)
+	}
+	if d.Hidden {
+		opts.SetHidden(true)
+	}
+	if d.ExpireAfterSeconds != nil {
+		if len(d.Keys) != 1 || !d.hasKeyType(IndexKeyAscending, IndexKeyDescending) {
+			return mongo.IndexModel{}, fmt.Errorf("TTL can be set only for a single field ascending or descending index")
+		}
+		if *d.ExpireAfterSeconds < 0 {
+			return mongo.IndexModel{}, fmt.Errorf("TTL can't be negative")
+		}
+		opts.SetExpireAfterSeconds(*d.ExpireAfterSeconds)
+	}
+	if locale := strings.TrimSpace(d.CollationLocale); locale != "" {
+		opts.SetCollation(&options.Collation{Locale: locale})
+	}
+
+	if filter, err := parseIndexOption("partial filter", d.PartialFilter); err != nil {
+		return mongo.IndexModel{}, err
+	} else if filter != nil {
+		opts.SetPartialFilterExpression(filter)
+	}
+
+	if projection, err := parseIndexOption("wildcard projection", d.WildcardProjection); err != nil {
+		return mongo.IndexModel{}, err
+	} else if projection != nil {
+		if len(keys) != 1 || keys[0].Key != wildcardSuffix {
+			return mongo.IndexModel{}, fmt.Errorf("wildcard projection can be set only for a wildcard index on all fields")
+		}
+		opts.SetWildcardProjection(projection)
+	}
+
+	weights, err := parseIndexOption("weights", d.Weights)
+	if err != nil {
+		return mongo.IndexModel{}, err
+	}
+	language := strings.TrimSpace(d.DefaultLanguage)
+	if (weights != nil || language != "") && !d.hasKeyType(IndexKeyText) {
+		return mongo.IndexModel{}, fmt.Errorf("weights and default language can be set only for text index")
+	}
+	if weights != nil {
+		opts.SetWeights(weights)
+	}
+	if language != "" {
+		opts.SetDefaultLanguage(language)
+	}
+
+	return mongo.IndexModel{Keys: keys, Options: opts}, nil
+}
+
+func (d IndexDefinition) keys() (bson.D, error) {
+	if len(d.Keys) == 0 {
+		return nil, fmt.Errorf("index requires at least one field")
+	}
+
+	keys := bson.D{}
+	hashed := 0
+	for _, key := range d.Keys {
+		field := strings.TrimSpace(key.Field)
+		var value any
+		switch key.Type {
+		case IndexKeyAscending:
+			value = 1
+		case IndexKeyDescending:
+			value = -1
+		case IndexKeyText, IndexKey2dsphere, IndexKey2d:
+			value = key.Type
+		case IndexKeyHashed:
+			hashed++
+			value = key.Type
+		case IndexKeyWildcard:
+			value = 1
+			field = wildcardField(field)
+		default:
+			return nil, fmt.Errorf("unknown index key type %q", key.Type)
+		}
+		if field == "" {
+			return nil, fmt.Errorf("field name is required for %s index key", key.Type)
+		}
+		keys = append(keys, bson.E{Key: field, Value: value})
+	}
+	if hashed > 1 {
+		return nil, fmt.Errorf("index can contain only one hashed field")
+	}
+	return keys, nil
+}
+
+func (d IndexDefinition) hasKeyType(types ...string) bool {
+	for _, key := range d.Keys {
+		for _, t := range types {
+			if key.Type == t {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// wildcardField returns the wildcard path for the field,
+// empty field means all fields of the document
+func wildcardField(field string) string {
+	field = strings.TrimSuffix(strings.TrimSuffix(field, wildcardSuffix), ".")
+	if field == "" {
+		return wildcardSuffix
+	}
+	return field + "." + wildcardSuffix
+}
+
+func parseIndexOption(name, text string) (primitive.M, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	doc, err := ParseJsonToBson(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return doc, nil
+}
+
+// ParseIndexInfo converts the listIndexes document into IndexInfo,
+// size and usage are filled later from index statistics
+func ParseIndexInfo(idx primitive.M) IndexInfo {
+	indexInfo := IndexInfo{
+		Type:       "REGULAR",
+		Size:       "N/A",
+		Usage:      "N/A",
+		Properties: []string{},
+		Hidden:     idx["hidden"] == true,
+	}
+	indexInfo.Name, _ = idx["name"].(string)
+	indexInfo.Definition, _ = idx["key"].(primitive.M)
+
+	for key, value := range indexInfo.Definition {
+		switch {
+		case strings.HasSuffix(key, wildcardSuffix):
+			indexInfo.Type = "WILDCARD"
+		case value == IndexKeyText:
+			indexInfo.Type = "TEXT"
+		case value == IndexKey2dsphere:
+			indexInfo.Type = "2DSPHERE"
+		case value == IndexKey2d:
+			indexInfo.Type = "2D"
+		case value == IndexKeyHashed:
+			indexInfo.Type = "HASHED"
+		}
+	}
+
+	if idx["unique"] == true || indexInfo.Name == "_id_" {
+		indexInfo.Properties = append(indexInfo.Properties, "UNIQUE")
+	}
+	if idx["sparse"] == true {
+		indexInfo.Properties = append(indexInfo.Properties, "SPARSE")
+	}
+	if _, ok := idx["partialFilterExpression"]; ok {
+		indexInfo.Properties = append(indexInfo.Properties, "PARTIAL")
+	}
+	if _, ok := idx["expireAfterSeconds"]; ok {
+		indexInfo.Properties = append(indexInfo.Properties, "TTL")
+		indexInfo.Type = "TTL"
+	}
+	if _, ok := idx["collation"]; ok {
+		indexInfo.Properties = append(indexInfo.Properties, "COLLATION")
+	}
+	if len(indexInfo.Definition) > 1 && indexInfo.Type != "TEXT" {
+		indexInfo.Properties = append(indexInfo.Properties, "COMPOUND")
+	}
+	if indexInfo.Hidden {
+		indexInfo.Properties = append(indexInfo.Properties, "HIDDEN")
+	}
+	return indexInfo
+}
+
+// IndexBuild is an index build in progress reported by currentOp
+type IndexBuild struct {
+	Indexes []string
+	Message string
+	Done    int64
+	Total   int64
+}
+
+// Percent returns the progress of the current build phase,
+// -1 is returned when the progress is unknown
+func (b IndexBuild) Percent() float64 {
+	if b.Total <= 0 {
+		return -1
+	}
+	return float64(b.Done) / float64(b.Total) * 100
+}
+
+// ParseIndexBuilds returns index builds on the namespace from current operations,
+// builds are reported either as createIndexes command or internal index build thread
+func ParseIndexBuilds(operations []Operation, namespace string) []IndexBuild {
+	builds := []IndexBuild{}
+	for _, op := range operations {
+		if op.Namespace != namespace {
+			continue
+		}
+		msg, _ := op.Raw["msg"].(string)
+		command, _ := op.Raw["command"].(primitive.M)
+		_, isCreateIndexes := command["createIndexes"]
+		if !strings.HasPrefix(msg, "Index Build") && !isCreateIndexes {
+			continue
+		}
+
+		build := IndexBuild{Message: msg}
+		if indexes, ok := command["indexes"].(primitive.A); ok {
+			for _, index := range indexes {
+				if index, ok := index.(primitive.M); ok {
+					if name, ok := index["name"].(string); ok {
+						build.Indexes = append(build.Indexes, name)
+					}
+				}
+			}
+		}
+		progress := nestedDoc(op.Raw, "progress")
+		build.Done = toInt64(progress["done"])
+		build.Total = toInt64(progress["total"])
+		builds = append(builds, build)
+	}
+	return builds
+}
@@ -0,0 +1,178 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIndexDefinitionToIndexModel(t *testing.T) {
+	ttl := int32(3600)
+	model, err := IndexDefinition{
+		Keys:               []IndexKey{{Field: "createdAt", Type: IndexKeyDescending}},
+		Name:               "created_ttl",
+		Hidden:             true,
+		ExpireAfterSeconds: &ttl,
+		PartialFilter:      `{"status": "active"}`,
+		CollationLocale:    "en",
+	}.ToIndexModel()
+	require.NoError(t, err)
+
+	assert.Equal(t, bson.D{{Key: "createdAt", Value: -1}}, model.Keys)
+	assert.Equal(t, "created_ttl", *model.Options.Name)
+	assert.Equal(t, true, *model.Options.Hidden)
+	assert.Equal(t, int32(3600), *model.Options.ExpireAfterSeconds)
+	assert.Equal(t, primitive.M{"status": "active"}, model.Options.PartialFilterExpression)
+	assert.Equal(t, "en", model.Options.Collation.Locale)
+	assert.Nil(t, model.Options.Unique)
+	assert.Nil(t, model.Options.Sparse)
+}
+
+func TestIndexDefinitionKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     []IndexKey
+		expected bson.D
+	}{
+		{
+			name:     "compound",
+			keys:     []IndexKey{{Field: "a", Type: IndexKeyAscending}, {Field: "b", Type: IndexKeyDescending}},
+			expected: bson.D{{Key: "a", Value: 1}, {Key: "b", Value: -1}},
+		},
+		{
+			name:     "geo and hashed",
+			keys:     []IndexKey{{Field: "loc", Type: IndexKey2d}, {Field: "user", Type: IndexKeyHashed}},
+			expected: bson.D{{Key: "loc", Value: "2d"}, {Key: "user", Value: "hashed"}},
+		},
+		{
+			name:     "wildcard on all fields",
+			keys:     []IndexKey{{Field: "", Type: IndexKeyWildcard}},
+			expected: bson.D{{Key: "$**", Value: 1}},
+		},
+		{
+			name:     "wildcard on path",
+			keys:     []IndexKey{{Field: "attributes", Type: IndexKeyWildcard}},
+			expected: bson.D{{Key: "attributes.$**", Value: 1}},
+		},
+		{
+			name:     "wildcard path already with suffix",
+			keys:     []IndexKey{{Field: "attributes.$**", Type: IndexKeyWildcard}},
+			expected: bson.D{{Key: "attributes.$**", Value: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			model, err := IndexDefinition{Keys: tt.keys}.ToIndexModel()
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, model.Keys)
+		})
+	}
+}
+
+func TestIndexDefinitionTextOptions(t *testing.T) {
+	model, err := IndexDefinition{
+		Keys:            []IndexKey{{Field: "title", Type: IndexKeyText}, {Field: "body", Type: IndexKeyText}},
+		Weights:         `{"title": 10}`,
+		DefaultLanguage: "german",
+	}.ToIndexModel()
+	require.NoError(t, err)
+	assert.Equal(t, primitive.M{"title": int32(10)}, model.Options.Weights)
+	assert.Equal(t, "german", *model.Options.DefaultLanguage)
+
+	model, err = IndexDefinition{
+		Keys:               []IndexKey{{Type: IndexKeyWildcard}},
+		WildcardProjection: `{"secret": 0}`,
+	}.ToIndexModel()
+	require.NoError(t, err)
+	assert.Equal(t, primitive.M{"secret": int32(0)}, model.Options.WildcardProjection)
+}
+
+func TestIndexDefinitionInvalid(t *testing.T) {
+	ttl := int32(60)
+	negativeTtl := int32(-1)
+	tests := []struct {
+		name string
+		def  IndexDefinition
+	}{
+		{name: "no keys", def: IndexDefinition{}},
+		{name: "missing field", def: IndexDefinition{Keys: []IndexKey{{Type: IndexKeyAscending}}}},
+		{name: "unknown type", def: IndexDefinition{Keys: []IndexKey{{Field: "a", Type: "geoHaystack"}}}},
+		{name: "two hashed fields", def: IndexDefinition{Keys: []IndexKey{{Field: "a", Type: IndexKeyHashed}, {Field: "b", Type: IndexKeyHashed}}}},
+		{name: "unique hashed", def: IndexDefinition{Keys: []IndexKey{{Field: "a", Type: IndexKeyHashed}}, Unique: true}},
+		{name: "sparse and partial", def: IndexDefinition{Keys: []IndexKey{{Field: "a", Type: IndexKeyAscending}}, Sparse: true, PartialFilter: `{"a": 1}`}},
+		{name: "compound TTL", def: IndexDefinition{Keys: []IndexKey{{Field: "a", Type: IndexKeyAscending}, {Field: "b", Type: IndexKeyAscending}}, ExpireAfterSeconds: &ttl}},
+		{name: "negative TTL", def: IndexDefinition{Keys: []IndexKey{{Field: "a", Type: IndexKeyAscending}}, ExpireAfterSeconds: &negativeTtl}},
+		{name: "invalid partial filter", def: IndexDefinition{Keys: []IndexKey{{Field: "a", Type: IndexKeyAscending}}, PartialFilter: `{"a": `}},
+		{name: "projection without wildcard", def: IndexDefinition{Keys: []IndexKey{{Field: "a", Type: IndexKeyAscending}}, WildcardProjection: `{"a": 1}`}},
+		{name: "projection on wildcard path", def: IndexDefinition{Keys: []IndexKey{{Field: "a", Type: IndexKeyWildcard}}, WildcardProjection: `{"a.b": 1}`}},
+		{name: "weights without text", def: IndexDefinition{Keys: []IndexKey{{Field: "a", Type: IndexKeyAscending}}, Weights: `{"a": 2}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.def.ToIndexModel()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseIndexInfo(t *testing.T) {
+	info := ParseIndexInfo(primitive.M{
+		"name":                    "status_1_createdAt_-1",
+		"key":                     primitive.M{"status": int32(1), "createdAt": int32(-1)},
+		"unique":                  true,
+		"hidden":                  true,
+		"partialFilterExpression": primitive.M{"status": "active"},
+	})
+	assert.Equal(t, "status_1_createdAt_-1", info.Name)
+	assert.Equal(t, "REGULAR", info.Type)
+	assert.True(t, info.Hidden)
+	assert.Equal(t, []string{"UNIQUE", "PARTIAL", "COMPOUND", "HIDDEN"}, info.Properties)
+
+	info = ParseIndexInfo(primitive.M{
+		"name":               "expires_1",
+		"key":                primitive.M{"expires": int32(1)},
+		"expireAfterSeconds": int32(0),
+	})
+	assert.Equal(t, "TTL", info.Type)
+	assert.Equal(t, []string{"TTL"}, info.Properties)
+
+	assert.Equal(t, "WILDCARD", ParseIndexInfo(primitive.M{"name": "$**_1", "key": primitive.M{"$**": int32(1)}}).Type)
+	assert.Equal(t, "HASHED", ParseIndexInfo(primitive.M{"name": "a_hashed", "key": primitive.M{"a": "hashed"}}).Type)
+	assert.Equal(t, "TEXT", ParseIndexInfo(primitive.M{"name": "t", "key": primitive.M{"_fts": "text", "_ftsx": int32(1)}}).Type)
+	assert.Equal(t, []string{"UNIQUE"}, ParseIndexInfo(primitive.M{"name": "_id_", "key": primitive.M{"_id": int32(1)}}).Properties)
+}
+
+func TestParseIndexBuilds(t *testing.T) {
+	operations := []Operation{
+		{
+			Namespace: "shop.orders",
+			Raw: primitive.M{
+				"msg":      "Index Build: scanning collection Index Build: scanning collection: 250/1000 25%",
+				"progress": primitive.M{"done": int64(250), "total": int64(1000)},
+			},
+		},
+		{
+			Namespace: "shop.orders",
+			Raw: primitive.M{
+				"command": primitive.M{
+					"createIndexes": "orders",
+					"indexes":       primitive.A{primitive.M{"name": "status_1", "key": primitive.M{"status": 1}}},
+				},
+			},
+		},
+		{Namespace: "shop.orders", Raw: primitive.M{"command": primitive.M{"find": "orders"}}},
+		{Namespace: "shop.users", Raw: primitive.M{"msg": "Index Build: draining writes"}},
+	}
+
+	builds := ParseIndexBuilds(operations, "shop.orders")
+	require.Len(t, builds, 2)
+	assert.Equal(t, int64(250), builds[0].Done)
+	assert.Equal(t, 25.0, builds[0].Percent())
+	assert.Equal(t, []string{"status_1"}, builds[1].Indexes)
+	assert.Equal(t, -1.0, builds[1].Percent())
+}
@@ -0,0 +1,43 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// maxTimeExpiredCode is returned by the server when an operation
+// runs longer than its maxTimeMS
+const maxTimeExpiredCode = 50
+
+// ErrMaxTimeExpired is returned when the server stopped the query
+// because it exceeded maxTimeMS set for the connection
+var ErrMaxTimeExpired = errors.New("query exceeded the time limit")
+
+// maxTime returns the time limit of queries run on the server,
+// nil when maxTimeMS is not set for the connection
+func (d *Dao) maxTime() *time.Duration {
+	if d.Config == nil || d.Config.Options.MaxTimeMS == nil || *d.Config.Options.MaxTimeMS <= 0 {
+		return nil
+	}
+	limit := time.Duration(*d.Config.Options.MaxTimeMS) * time.Millisecond
+	return &limit
+}
+
+// wrapMaxTimeError replaces the server error of an expired query with ErrMaxTimeExpired,
+// so it's clear that the query was stopped by the limit and not by the failure
+func (d *Dao) wrapMaxTimeError(err error) error {
+	limit := d.maxTime()
+	if limit == nil || !isMaxTimeExpired(err) {
+		return err
+	}
+	return fmt.Errorf("%w of %s (maxTimeMS), narrow the query or raise the limit in the connection options",
+		ErrMaxTimeExpired, *limit)
+}
+
+func isMaxTimeExpired(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(maxTimeExpiredCode)
+}
@@ -0,0 +1,43 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMaxTime(t *testing.T) {
+	limit := int64(1500)
+	zero := int64(0)
+
+	assert.Nil(t, (&Dao{}).maxTime())
+	assert.Nil(t, (&Dao{Config: &config.MongoConfig{}}).maxTime())
+	assert.Nil(t, (&Dao{Config: &config.MongoConfig{Options: config.MongoOptions{MaxTimeMS: &zero}}}).maxTime())
+
+	maxTime := (&Dao{Config: &config.MongoConfig{Options: config.MongoOptions{MaxTimeMS: &limit}}}).maxTime()
+	if assert.NotNil(t, maxTime) {
+		assert.Equal(t, 1500*time.Millisecond, *maxTime)
+	}
+}
+
+func TestWrapMaxTimeError(t *testing.T) {
+	limit := int64(1500)
+	dao := &Dao{Config: &config.MongoConfig{Options: config.MongoOptions{MaxTimeMS: &limit}}}
+	expired := fmt.Errorf("failed to find documents: %w",
+		mongo.CommandError{Code: maxTimeExpiredCode, Message: "operation exceeded time limit"})
+
+	err := dao.wrapMaxTimeError(expired)
+	assert.ErrorIs(t, err, ErrMaxTimeExpired)
+	assert.Contains(t, err.Error(), "1.5s")
+
+	other := mongo.CommandError{Code: 2, Message: "bad value"}
+	assert.Equal(t, error(other), dao.wrapMaxTimeError(other))
+
+	// without the limit set the server error is kept as it is
+	assert.False(t, errors.Is((&Dao{}).wrapMaxTimeError(expired), ErrMaxTimeExpired))
+}
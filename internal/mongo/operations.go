@@ -0,0 +1,81 @@
+package mongo
+
+import (
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Operation is an in-progress operation reported by currentOp
+type Operation struct {
+	// OpId is a number on mongod and "shard:number" string on mongos
+	OpId        any
+	Namespace   string
+	Type        string
+	SecsRunning int64
+	Client      string
+	Description string
+	PlanSummary string
+	// Command is the compact JSON of the command which is being run
+	Command string
+	Raw     primitive.M
+}
+
+// ParseOperations converts inprog array of currentOp into operations
+// sorted from the longest running ones
+func ParseOperations(inprog primitive.A) []Operation {
+	operations := make([]Operation, 0, len(inprog))
+	for _, item := range inprog {
+		raw, ok := item.(primitive.M)
+		if !ok {
+			continue
+		}
+		operations = append(operations, parseOperation(raw))
+	}
+
+	sort.SliceStable(operations, func(i, j int) bool {
+		return operations[i].SecsRunning > operations[j].SecsRunning
+	})
+	return operations
+}
+
+func parseOperation(raw primitive.M) Operation {
+	op := Operation{
+		OpId: raw["opid"],
+		Raw:  raw,
+	}
+	op.Namespace, _ = raw["ns"].(string)
+	op.Type, _ = raw["op"].(string)
+	op.Description, _ = raw["desc"].(string)
+	op.PlanSummary, _ = raw["planSummary"].(string)
+	op.SecsRunning = toInt64(raw["secs_running"])
+
+	// client is missing for internal operations, mongos reports client_s
+	if client, ok := raw["client"].(string); ok {
+		op.Client = client
+	} else if client, ok := raw["client_s"].(string); ok {
+		op.Client = client
+	}
+
+	if command, ok := raw["command"].(primitive.M); ok {
+		op.Command = compactJson(command)
+	}
+	return op
+}
+
+// FilterOperations returns operations with namespace containing the given
+// text that are running at least minSeconds
+func FilterOperations(operations []Operation, namespace string, minSeconds int64) []Operation {
+	filtered := make([]Operation, 0, len(operations))
+	for _, op := range operations {
+		if namespace != "" && !strings.Contains(op.Namespace, namespace) {
+			continue
+		}
+		if op.SecsRunning < minSeconds {
+			continue
+		}
+		filtered = append(filtered, op)
+	}
+	return filtered
+}
@@ -0,0 +1,66 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseOperations(t *testing.T) {
+	inprog := primitive.A{
+		primitive.M{
+			"opid":         int32(12),
+			"op":           "query",
+			"ns":           "shop.orders",
+			"secs_running": int64(3),
+			"client":       "127.0.0.1:51234",
+			"desc":         "conn42",
+			"planSummary":  "COLLSCAN",
+			"command":      primitive.M{"find": "orders", "filter": primitive.M{"status": "new"}},
+		},
+		primitive.M{
+			"opid":         "shard01:77",
+			"op":           "update",
+			"ns":           "shop.users",
+			"secs_running": int32(40),
+			"client_s":     "10.0.0.5:40000",
+		},
+		primitive.M{
+			"opid": int32(1),
+			"op":   "none",
+			"desc": "WTCheckpointThread",
+		},
+	}
+
+	ops := ParseOperations(inprog)
+	require.Len(t, ops, 3)
+
+	assert.Equal(t, "shard01:77", ops[0].OpId)
+	assert.Equal(t, int64(40), ops[0].SecsRunning)
+	assert.Equal(t, "10.0.0.5:40000", ops[0].Client)
+
+	assert.Equal(t, int32(12), ops[1].OpId)
+	assert.Equal(t, "query", ops[1].Type)
+	assert.Equal(t, "shop.orders", ops[1].Namespace)
+	assert.Equal(t, "127.0.0.1:51234", ops[1].Client)
+	assert.Equal(t, "COLLSCAN", ops[1].PlanSummary)
+	assert.Equal(t, `{"filter":{"status":"new"},"find":"orders"}`, ops[1].Command)
+
+	assert.Equal(t, "WTCheckpointThread", ops[2].Description)
+	assert.Equal(t, int64(0), ops[2].SecsRunning)
+}
+
+func TestFilterOperations(t *testing.T) {
+	ops := []Operation{
+		{OpId: 1, Namespace: "shop.orders", SecsRunning: 10},
+		{OpId: 2, Namespace: "shop.users", SecsRunning: 1},
+		{OpId: 3, Namespace: "admin.$cmd", SecsRunning: 30},
+	}
+
+	assert.Len(t, FilterOperations(ops, "", 0), 3)
+	assert.Equal(t, []Operation{ops[0], ops[1]}, FilterOperations(ops, "shop", 0))
+	assert.Equal(t, []Operation{ops[0], ops[2]}, FilterOperations(ops, "", 5))
+	assert.Equal(t, []Operation{ops[0]}, FilterOperations(ops, "orders", 5))
+}
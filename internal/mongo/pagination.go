@@ -0,0 +1,368 @@
+package mongo
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PaginationMode is the way pages of documents are fetched
+type PaginationMode string
+
+const (
+	// PaginationSkip fetches pages by skipping documents of all previous pages
+	PaginationSkip PaginationMode = "skip"
+	// PaginationKeyset fetches pages with a range condition on the sort keys
+	// of the page boundary, so it doesn't get slower deeper in the collection
+	PaginationKeyset PaginationMode = "keyset"
+)
+
+// PaginationModes lists all modes in the order they are shown in the query options
+var PaginationModes = []PaginationMode{PaginationSkip, PaginationKeyset}
+
+// pageBound positions the current page relative to the sort key values of a document
+type pageBound struct {
+	// values are the sort key values of the boundary document,
+	// they are empty for the last page
+	values primitive.D
+	// before means the page ends right before values, such page
+	// is fetched with reversed sort
+	before bool
+}
+
+// PageQuery is the find query of the current page
+type PageQuery struct {
+	Filter primitive.M
+	Sort   primitive.D
+	Skip   int64
+	Limit  int64
+	// Reversed means documents are fetched in reversed sort order
+	// and have to be reversed back before they are shown
+	Reversed bool
+	// ResultLimit caps the count of matching documents, 0 means no limit
+	ResultLimit int64
+}
+
+// SetPagination changes the pagination mode and moves to the first page
+func (c *CollectionState) SetPagination(mode PaginationMode) {
+	c.Pagination = mode
+	c.SetSkip(0)
+}
+
+// NextPage moves to the next page, in keyset mode the page starts after the last
+// loaded document, skip is used when the sort can't be expressed with a range condition
+func (c *CollectionState) NextPage(sort primitive.D) {
+	c.movePage(sort, c.Skip+c.Limit, false)
+}
+
+// PreviousPage moves to the previous page, in keyset mode the page ends before the first
+// loaded document, skip is used when the sort can't be expressed with a range condition
+func (c *CollectionState) PreviousPage(sort primitive.D) {
+	c.movePage(sort, c.Skip-c.Limit, true)
+}
+
+// FirstPage moves to the first page
+func (c *CollectionState) FirstPage() {
+	c.SetSkip(0)
+}
+
+// LastPage moves to the last page, in keyset mode it's fetched by reversing
+// the sort, so none of the documents has to be skipped
+func (c *CollectionState) LastPage(sort primitive.D) {
+	c.SetSkip((c.GetTotalPages() - 1) * c.Limit)
+	// reversed sort would fetch the last documents of all, not the ones before the result limit
+	if c.Pagination != PaginationKeyset || c.Skip == 0 || c.ResultLimit > 0 {
+		return
+	}
+	if _, ok := KeysetSort(sort); ok {
+		c.pageBound = &pageBound{before: true}
+	}
+}
+
+func (c *CollectionState) movePage(sort primitive.D, skip int64, before bool) {
+	c.SetSkip(skip)
+	if c.Pagination != PaginationKeyset || c.Skip == 0 || len(c.docs) == 0 {
+		return
+	}
+	keys, ok := KeysetSort(sort)
+	if !ok {
+		return
+	}
+
+	boundary := c.docs[len(c.docs)-1]
+	if before {
+		boundary = c.docs[0]
+	}
+	values, ok := c.keyValues(boundary, keys)
+	if !ok {
+		return
+	}
+	c.pageBound = &pageBound{values: values, before: before}
+}
+
+// PageQuery returns the query of the current page for the given filter and sort
+func (c *CollectionState) PageQuery(filter primitive.M, sort primitive.D) PageQuery {
+	limit := c.Limit
+	if rest := c.ResultLimit - c.Skip; c.ResultLimit > 0 && rest > 0 && rest < limit {
+		// page before the result limit holds only documents left up to the limit
+		limit = rest
+	}
+	query := PageQuery{Filter: filter, Sort: sort, Skip: c.Skip, Limit: limit, ResultLimit: c.ResultLimit}
+	if c.Pagination != PaginationKeyset {
+		return query
+	}
+	keys, ok := KeysetSort(sort)
+	if !ok {
+		return query
+	}
+	// every page is sorted by the keys, so documents with equal sort
+	// values are in the same order no matter how the page was reached
+	query.Sort = keys
+	if c.pageBound == nil {
+		return query
+	}
+
+	query.Skip = 0
+	if len(c.pageBound.values) > 0 {
+		query.Filter = andFilter(filter, rangeFilter(keys, c.pageBound.values, c.pageBound.before))
+	} else if rest := c.Count - c.Skip; c.CountKind == CountExact && rest > 0 && rest < c.Limit {
+		// last page holds only documents left after all full pages
+		query.Limit = rest
+	}
+	if c.pageBound.before {
+		query.Sort = reverseSort(keys)
+		query.Reversed = true
+	}
+	return query
+}
+
+// KeysetSort returns the sort used by keyset pagination, supported are the default sort,
+// a sort by _id and a sort by a single field, _id is added after the field so every
+// document has a unique position. Sorts with values other than 1 or -1
+// (e.g. {$meta: "textScore"}), by $natural or by more fields fall back to skip.
+// Arrays are compared by their min or max element, so documents holding arrays
+// in the sorted field can show up on more than one page
+func KeysetSort(sort primitive.D) (primitive.D, bool) {
+	switch {
+	case len(sort) > 2:
+		return nil, false
+	case len(sort) == 2 && (sort[0].Key == "_id" || sort[1].Key != "_id"):
+		// _id can only follow a single field as the tie-breaker
+		return nil, false
+	}
+	keys := make(primitive.D, 0, 2)
+	for _, elem := range sort {
+		direction, ok := sortDirection(elem.Value)
+		if !ok || elem.Key == "$natural" {
+			return nil, false
+		}
+		keys = append(keys, primitive.E{Key: elem.Key, Value: direction})
+	}
+	if len(keys) == 0 || keys[len(keys)-1].Key != "_id" {
+		keys = append(keys, primitive.E{Key: "_id", Value: int32(1)})
+	}
+	return keys, true
+}
+
+func sortDirection(value any) (int32, bool) {
+	direction, ok := anyToFloat64(value)
+	if !ok {
+		return 0, false
+	}
+	switch direction {
+	case 1:
+		return 1, true
+	case -1:
+		return -1, true
+	}
+	return 0, false
+}
+
+// keyValues returns values of the keys from the document, missing fields are null
+// as they are sorted the same. Documents with array, regex or unordered embedded
+// document values, or with arrays on the path, can't be used as a boundary
+func (c *CollectionState) keyValues(doc primitive.M, keys primitive.D) (primitive.D, bool) {
+	values := make(primitive.D, 0, len(keys))
+	for _, key := range keys {
+		value, ok := sortKeyValue(doc, key.Key)
+		if !ok {
+			return nil, false
+		}
+		switch v := value.(type) {
+		case primitive.Null:
+			value = nil
+		case primitive.A, []any, primitive.Regex:
+			return nil, false
+		case primitive.M:
+			if len(v) > 1 {
+				return nil, false
+			}
+		}
+		if _, ok := bsonTypeRank(value); !ok {
+			return nil, false
+		}
+		values = append(values, primitive.E{Key: key.Key, Value: value})
+	}
+	return values, true
+}
+
+// sortKeyValue returns the value at the dotted path of the document, nil when it's missing,
+// false is returned when the path goes through an array, as the server sorts by its elements
+func sortKeyValue(doc primitive.M, path string) (any, bool) {
+	current := doc
+	fields := strings.Split(path, ".")
+	for i, field := range fields {
+		value, exists := current[field]
+		if !exists || i == len(fields)-1 {
+			return value, true
+		}
+		switch nested := value.(type) {
+		case primitive.M:
+			current = nested
+		case primitive.A, []any:
+			return nil, false
+		default:
+			return nil, true
+		}
+	}
+	return nil, true
+}
+
+// rangeFilter builds the condition matching documents positioned after the values
+// in the sort order of the keys, or before them when before is set, e.g. for
+// keys {a: 1, _id: 1} it's {$or: [{a: {$gt: va}}, {a: {$type: [...]}}, {a: va, _id: {$gt: vid}}, ...]}
+func rangeFilter(keys, values primitive.D, before bool) primitive.M {
+	conditions := primitive.A{}
+	for i, key := range keys {
+		for _, condition := range boundaryConditions(key, values[i].Value, before) {
+			// {a: null} matches documents where a is missing too, like the sort
+			for _, equal := range values[:i] {
+				condition[equal.Key] = equal.Value
+			}
+			conditions = append(conditions, condition)
+		}
+	}
+
+	if len(conditions) == 1 {
+		return conditions[0].(primitive.M)
+	}
+	return primitive.M{"$or": conditions}
+}
+
+// boundaryConditions returns conditions matching values of the key positioned after
+// the value, or before it when before is set. Range operators only compare values
+// of the same BSON type, so values of types sorted after or before are matched by
+// $type. Null and missing values are sorted the same and are matched by {key: null}
+func boundaryConditions(key primitive.E, value any, before bool) []primitive.M {
+	operator, later := "$gt", true
+	if (key.Value.(int32) < 0) != before {
+		operator, later = "$lt", false
+	}
+
+	var conditions []primitive.M
+	if value != nil {
+		conditions = append(conditions, primitive.M{key.Key: primitive.M{operator: value}})
+	}
+	types, withNull := otherBsonTypes(value, later)
+	if len(types) > 0 {
+		conditions = append(conditions, primitive.M{key.Key: primitive.M{"$type": types}})
+	}
+	if withNull {
+		conditions = append(conditions, primitive.M{key.Key: nil})
+	}
+	return conditions
+}
+
+// nullTypeRank is the position of null in bsonTypeOrder
+const nullTypeRank = 1
+
+// bsonTypeOrder groups BSON type aliases by the order in which they are compared
+// by the server, types within a group are compared by their values
+var bsonTypeOrder = [][]string{
+	{"minKey"},
+	{"null"},
+	{"double", "int", "long", "decimal"},
+	{"symbol", "string"},
+	{"object"},
+	{"array"},
+	{"binData"},
+	{"objectId"},
+	{"bool"},
+	{"date"},
+	{"timestamp"},
+	{"regex"},
+	{"maxKey"},
+}
+
+// otherBsonTypes returns the types compared after the type of the value, or before it
+// when later isn't set. Null is reported separately, as $type doesn't match missing fields
+func otherBsonTypes(value any, later bool) (primitive.A, bool) {
+	rank, ok := bsonTypeRank(value)
+	if !ok {
+		return nil, false
+	}
+	start, end := 0, rank
+	if later {
+		start, end = rank+1, len(bsonTypeOrder)
+	}
+	types := primitive.A{}
+	withNull := false
+	for i := start; i < end; i++ {
+		if i == nullTypeRank {
+			withNull = true
+			continue
+		}
+		for _, name := range bsonTypeOrder[i] {
+			types = append(types, name)
+		}
+	}
+	return types, withNull
+}
+
+func bsonTypeRank(value any) (int, bool) {
+	switch value.(type) {
+	case primitive.MinKey:
+		return 0, true
+	case nil, primitive.Null:
+		return nullTypeRank, true
+	case int, int32, int64, float64, primitive.Decimal128:
+		return 2, true
+	case string, primitive.Symbol:
+		return 3, true
+	case primitive.M, primitive.D, map[string]any:
+		return 4, true
+	case primitive.A, []any:
+		return 5, true
+	case primitive.Binary:
+		return 6, true
+	case primitive.ObjectID:
+		return 7, true
+	case bool:
+		return 8, true
+	case primitive.DateTime, time.Time:
+		return 9, true
+	case primitive.Timestamp:
+		return 10, true
+	case primitive.Regex:
+		return 11, true
+	case primitive.MaxKey:
+		return 12, true
+	}
+	return 0, false
+}
+
+func andFilter(filter, condition primitive.M) primitive.M {
+	if len(filter) == 0 {
+		return condition
+	}
+	return primitive.M{"$and": primitive.A{filter, condition}}
+}
+
+func reverseSort(keys primitive.D) primitive.D {
+	reversed := make(primitive.D, len(keys))
+	for i, key := range keys {
+		reversed[i] = primitive.E{Key: key.Key, Value: -key.Value.(int32)}
+	}
+	return reversed
+}
@@ -0,0 +1,341 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newKeysetState(docs ...primitive.M) *CollectionState {
+	state := NewCollectionState("db", "coll")
+	state.Limit = 2
+	state.Count = 5
+	state.SetPagination(PaginationKeyset)
+	state.PopulateDocs(docs)
+	return state
+}
+
+func TestKeysetSort(t *testing.T) {
+	t.Run("Default sort is by _id", func(t *testing.T) {
+		keys, ok := KeysetSort(primitive.D{})
+		assert.True(t, ok)
+		assert.Equal(t, primitive.D{{Key: "_id", Value: int32(1)}}, keys)
+	})
+
+	t.Run("Keeps _id direction", func(t *testing.T) {
+		keys, ok := KeysetSort(primitive.D{{Key: "_id", Value: int64(-1)}})
+		assert.True(t, ok)
+		assert.Equal(t, primitive.D{{Key: "_id", Value: int32(-1)}}, keys)
+	})
+
+	t.Run("Adds _id after a single field", func(t *testing.T) {
+		keys, ok := KeysetSort(primitive.D{{Key: "createdAt", Value: float64(-1)}})
+		assert.True(t, ok)
+		assert.Equal(t, primitive.D{{Key: "createdAt", Value: int32(-1)}, {Key: "_id", Value: int32(1)}}, keys)
+	})
+
+	t.Run("Keeps _id tie-breaker", func(t *testing.T) {
+		keys, ok := KeysetSort(primitive.D{{Key: "createdAt", Value: int32(-1)}, {Key: "_id", Value: int32(-1)}})
+		assert.True(t, ok)
+		assert.Equal(t, primitive.D{{Key: "createdAt", Value: int32(-1)}, {Key: "_id", Value: int32(-1)}}, keys)
+	})
+
+	t.Run("Unsupported sorts", func(t *testing.T) {
+		_, ok := KeysetSort(primitive.D{{Key: "age", Value: int32(1)}, {Key: "name", Value: int32(1)}})
+		assert.False(t, ok)
+		_, ok = KeysetSort(primitive.D{{Key: "_id", Value: int32(1)}, {Key: "age", Value: int32(1)}})
+		assert.False(t, ok)
+		_, ok = KeysetSort(primitive.D{{Key: "a", Value: int32(1)}, {Key: "b", Value: int32(1)}, {Key: "_id", Value: int32(1)}})
+		assert.False(t, ok)
+		_, ok = KeysetSort(primitive.D{{Key: "score", Value: primitive.M{"$meta": "textScore"}}})
+		assert.False(t, ok)
+		_, ok = KeysetSort(primitive.D{{Key: "$natural", Value: int32(1)}})
+		assert.False(t, ok)
+		_, ok = KeysetSort(primitive.D{{Key: "_id", Value: int32(2)}})
+		assert.False(t, ok)
+	})
+}
+
+func TestKeyValues(t *testing.T) {
+	state := NewCollectionState("db", "coll")
+	keys := primitive.D{{Key: "profile.age", Value: int32(1)}, {Key: "_id", Value: int32(1)}}
+
+	values, ok := state.keyValues(primitive.M{"_id": int32(1), "profile": primitive.M{"age": int32(30)}}, keys)
+	assert.True(t, ok)
+	assert.Equal(t, primitive.D{{Key: "profile.age", Value: int32(30)}, {Key: "_id", Value: int32(1)}}, values)
+
+	// missing fields are sorted as null
+	values, ok = state.keyValues(primitive.M{"_id": int32(2)}, keys)
+	assert.True(t, ok)
+	assert.Equal(t, primitive.D{{Key: "profile.age", Value: nil}, {Key: "_id", Value: int32(2)}}, values)
+
+	values, ok = state.keyValues(primitive.M{"_id": int32(3), "profile": "hidden"}, keys)
+	assert.True(t, ok)
+	assert.Equal(t, primitive.D{{Key: "profile.age", Value: nil}, {Key: "_id", Value: int32(3)}}, values)
+
+	values, ok = state.keyValues(primitive.M{"_id": int32(4), "profile": primitive.M{"age": primitive.Null{}}}, keys)
+	assert.True(t, ok)
+	assert.Equal(t, primitive.D{{Key: "profile.age", Value: nil}, {Key: "_id", Value: int32(4)}}, values)
+
+	// arrays are sorted by their elements
+	_, ok = state.keyValues(primitive.M{"_id": int32(5), "profile": primitive.M{"age": primitive.A{int32(1), int32(2)}}}, keys)
+	assert.False(t, ok)
+	_, ok = state.keyValues(primitive.M{"_id": int32(6), "profile": primitive.A{primitive.M{"age": int32(1)}}}, keys)
+	assert.False(t, ok)
+	_, ok = state.keyValues(primitive.M{"_id": int32(7), "profile": primitive.M{"age": primitive.M{"a": 1, "b": 2}}}, keys)
+	assert.False(t, ok)
+}
+
+func TestRangeFilter(t *testing.T) {
+	asc := primitive.D{{Key: "_id", Value: int32(1)}}
+	desc := primitive.D{{Key: "_id", Value: int32(-1)}}
+	oid := primitive.NewObjectID()
+	values := primitive.D{{Key: "_id", Value: oid}}
+
+	t.Run("Other _id types", func(t *testing.T) {
+		after := rangeFilter(asc, values, false)
+		assert.Equal(t, primitive.M{"$or": primitive.A{
+			primitive.M{"_id": primitive.M{"$gt": oid}},
+			primitive.M{"_id": primitive.M{"$type": primitive.A{"bool", "date", "timestamp", "regex", "maxKey"}}},
+		}}, after)
+
+		before := rangeFilter(asc, values, true)
+		assert.Equal(t, primitive.M{"$or": primitive.A{
+			primitive.M{"_id": primitive.M{"$lt": oid}},
+			primitive.M{"_id": primitive.M{"$type": primitive.A{
+				"minKey", "double", "int", "long", "decimal", "symbol", "string", "object", "array", "binData",
+			}}},
+			primitive.M{"_id": nil},
+		}}, before)
+
+		// descending order puts documents with _id of types sorted before after the value
+		numbers := rangeFilter(desc, primitive.D{{Key: "_id", Value: int32(7)}}, false)
+		assert.Equal(t, primitive.M{"$or": primitive.A{
+			primitive.M{"_id": primitive.M{"$lt": int32(7)}},
+			primitive.M{"_id": primitive.M{"$type": primitive.A{"minKey"}}},
+			primitive.M{"_id": nil},
+		}}, numbers)
+
+		maxKey := rangeFilter(asc, primitive.D{{Key: "_id", Value: primitive.MaxKey{}}}, false)
+		assert.Equal(t, primitive.M{"_id": primitive.M{"$gt": primitive.MaxKey{}}}, maxKey)
+	})
+
+	t.Run("Field with _id tie-breaker", func(t *testing.T) {
+		keys := primitive.D{{Key: "createdAt", Value: int32(-1)}, {Key: "_id", Value: int32(1)}}
+		date := primitive.DateTime(1700000000000)
+		filter := rangeFilter(keys, primitive.D{{Key: "createdAt", Value: date}, {Key: "_id", Value: int32(7)}}, false)
+
+		// documents without createdAt are sorted as null, so they come last in descending order
+		assert.Equal(t, primitive.M{"$or": primitive.A{
+			primitive.M{"createdAt": primitive.M{"$lt": date}},
+			primitive.M{"createdAt": primitive.M{"$type": primitive.A{
+				"minKey", "double", "int", "long", "decimal", "symbol", "string", "object", "array", "binData", "objectId", "bool",
+			}}},
+			primitive.M{"createdAt": nil},
+			primitive.M{"createdAt": date, "_id": primitive.M{"$gt": int32(7)}},
+			primitive.M{"createdAt": date, "_id": primitive.M{"$type": otherTypes(int32(7), true)}},
+		}}, filter)
+	})
+
+	t.Run("Missing field boundary", func(t *testing.T) {
+		keys := primitive.D{{Key: "age", Value: int32(1)}, {Key: "_id", Value: int32(1)}}
+		boundary := primitive.D{{Key: "age", Value: nil}, {Key: "_id", Value: int32(3)}}
+
+		after := rangeFilter(keys, boundary, false)
+		assert.Equal(t, primitive.M{"$or": primitive.A{
+			primitive.M{"age": primitive.M{"$type": otherTypes(nil, true)}},
+			primitive.M{"age": nil, "_id": primitive.M{"$gt": int32(3)}},
+			primitive.M{"age": nil, "_id": primitive.M{"$type": otherTypes(int32(3), true)}},
+		}}, after)
+
+		before := rangeFilter(keys, boundary, true)
+		assert.Equal(t, primitive.M{"$or": primitive.A{
+			primitive.M{"age": primitive.M{"$type": primitive.A{"minKey"}}},
+			primitive.M{"age": nil, "_id": primitive.M{"$lt": int32(3)}},
+			primitive.M{"age": nil, "_id": primitive.M{"$type": primitive.A{"minKey"}}},
+			primitive.M{"age": nil, "_id": nil},
+		}}, before)
+	})
+}
+
+func otherTypes(value any, later bool) primitive.A {
+	types, _ := otherBsonTypes(value, later)
+	return types
+}
+
+func TestCollectionState_PageQuery(t *testing.T) {
+	sort := primitive.D{{Key: "_id", Value: int32(1)}}
+	filter := primitive.M{"active": true}
+
+	t.Run("Skip mode", func(t *testing.T) {
+		state := NewCollectionState("db", "coll")
+		state.Limit = 2
+		state.SetSkip(4)
+
+		query := state.PageQuery(filter, sort)
+		assert.Equal(t, PageQuery{Filter: filter, Sort: sort, Skip: 4, Limit: 2}, query)
+	})
+
+	t.Run("Result limit shrinks the page before it", func(t *testing.T) {
+		state := NewCollectionState("db", "coll")
+		state.Limit = 10
+		state.ResultLimit = 25
+		state.SetSkip(20)
+
+		query := state.PageQuery(filter, sort)
+		assert.Equal(t, PageQuery{Filter: filter, Sort: sort, Skip: 20, Limit: 5, ResultLimit: 25}, query)
+		// page size stays the same
+		assert.Equal(t, int64(10), state.Limit)
+	})
+
+	t.Run("First page", func(t *testing.T) {
+		state := newKeysetState()
+
+		query := state.PageQuery(filter, primitive.D{})
+		assert.Equal(t, PageQuery{Filter: filter, Sort: sort, Skip: 0, Limit: 2}, query)
+	})
+
+	t.Run("Next page starts after the last document", func(t *testing.T) {
+		state := newKeysetState(primitive.M{"_id": int32(1)}, primitive.M{"_id": int32(2)})
+		state.NextPage(sort)
+
+		query := state.PageQuery(filter, sort)
+		assert.Equal(t, int64(2), state.Skip)
+		assert.Equal(t, int64(0), query.Skip)
+		assert.Equal(t, sort, query.Sort)
+		assert.False(t, query.Reversed)
+		assert.Equal(t, primitive.M{"$and": primitive.A{filter, primitive.M{"$or": primitive.A{
+			primitive.M{"_id": primitive.M{"$gt": int32(2)}},
+			primitive.M{"_id": primitive.M{"$type": otherTypes(int32(2), true)}},
+		}}}}, query.Filter)
+	})
+
+	t.Run("Previous page ends before the first document", func(t *testing.T) {
+		state := newKeysetState(primitive.M{"_id": int32(5)}, primitive.M{"_id": int32(6)})
+		state.Skip = 4
+		state.PreviousPage(sort)
+
+		query := state.PageQuery(primitive.M{}, sort)
+		assert.Equal(t, int64(2), state.Skip)
+		assert.True(t, query.Reversed)
+		assert.Equal(t, primitive.D{{Key: "_id", Value: int32(-1)}}, query.Sort)
+		assert.Equal(t, primitive.M{"$or": primitive.A{
+			primitive.M{"_id": primitive.M{"$lt": int32(5)}},
+			primitive.M{"_id": primitive.M{"$type": primitive.A{"minKey"}}},
+			primitive.M{"_id": nil},
+		}}, query.Filter)
+	})
+
+	t.Run("Previous page to the first page", func(t *testing.T) {
+		state := newKeysetState(primitive.M{"_id": int32(3)})
+		state.Skip = 2
+		state.PreviousPage(sort)
+
+		query := state.PageQuery(filter, sort)
+		assert.Equal(t, PageQuery{Filter: filter, Sort: sort, Skip: 0, Limit: 2}, query)
+	})
+
+	t.Run("Last page reverses the sort", func(t *testing.T) {
+		state := newKeysetState(primitive.M{"_id": int32(1)})
+		state.LastPage(sort)
+
+		query := state.PageQuery(filter, sort)
+		assert.Equal(t, int64(4), state.Skip)
+		assert.Equal(t, int64(3), state.GetCurrentPage())
+		assert.Equal(t, PageQuery{
+			Filter:   filter,
+			Sort:     primitive.D{{Key: "_id", Value: int32(-1)}},
+			Skip:     0,
+			Limit:    1,
+			Reversed: true,
+		}, query)
+	})
+
+	t.Run("Next page by a field", func(t *testing.T) {
+		ageSort := primitive.D{{Key: "age", Value: int32(-1)}}
+		ageKeys := primitive.D{{Key: "age", Value: int32(-1)}, {Key: "_id", Value: int32(1)}}
+		state := newKeysetState(primitive.M{"_id": int32(1), "age": int32(40)}, primitive.M{"_id": int32(2), "age": int32(35)})
+		state.NextPage(ageSort)
+
+		query := state.PageQuery(primitive.M{}, ageSort)
+		assert.Equal(t, ageKeys, query.Sort)
+		assert.Equal(t, int64(0), query.Skip)
+		assert.Equal(t, rangeFilter(ageKeys, primitive.D{{Key: "age", Value: int32(35)}, {Key: "_id", Value: int32(2)}}, false), query.Filter)
+		// documents without age or with age of other types are on the next pages
+		assert.Contains(t, query.Filter["$or"], primitive.M{"age": nil})
+		assert.Contains(t, query.Filter["$or"], primitive.M{"age": primitive.M{"$type": primitive.A{"minKey"}}})
+	})
+
+	t.Run("Next page after documents without the sort field", func(t *testing.T) {
+		ageSort := primitive.D{{Key: "age", Value: int32(1)}}
+		ageKeys := primitive.D{{Key: "age", Value: int32(1)}, {Key: "_id", Value: int32(1)}}
+		state := newKeysetState(primitive.M{"_id": int32(1)}, primitive.M{"_id": int32(2), "age": nil})
+		state.NextPage(ageSort)
+
+		query := state.PageQuery(primitive.M{}, ageSort)
+		assert.Equal(t, int64(0), query.Skip)
+		assert.Equal(t, primitive.M{"$or": primitive.A{
+			primitive.M{"age": primitive.M{"$type": otherTypes(nil, true)}},
+			primitive.M{"age": nil, "_id": primitive.M{"$gt": int32(2)}},
+			primitive.M{"age": nil, "_id": primitive.M{"$type": otherTypes(int32(2), true)}},
+		}}, query.Filter)
+		assert.Equal(t, ageKeys, query.Sort)
+	})
+
+	t.Run("Previous page before documents without the sort field", func(t *testing.T) {
+		ageSort := primitive.D{{Key: "age", Value: int32(-1)}}
+		state := newKeysetState(primitive.M{"_id": int32(5)}, primitive.M{"_id": int32(6)})
+		state.Skip = 4
+		state.PreviousPage(ageSort)
+
+		query := state.PageQuery(primitive.M{}, ageSort)
+		assert.True(t, query.Reversed)
+		assert.Equal(t, primitive.D{{Key: "age", Value: int32(1)}, {Key: "_id", Value: int32(-1)}}, query.Sort)
+		// documents with any age are sorted before missing ones in descending order
+		assert.Equal(t, primitive.M{"$or": primitive.A{
+			primitive.M{"age": primitive.M{"$type": otherTypes(nil, true)}},
+			primitive.M{"age": nil, "_id": primitive.M{"$lt": int32(5)}},
+			primitive.M{"age": nil, "_id": primitive.M{"$type": primitive.A{"minKey"}}},
+			primitive.M{"age": nil, "_id": nil},
+		}}, query.Filter)
+	})
+
+	t.Run("Falls back to skip for array values", func(t *testing.T) {
+		tagSort := primitive.D{{Key: "tags", Value: int32(1)}}
+		state := newKeysetState(primitive.M{"_id": int32(1)}, primitive.M{"_id": int32(2), "tags": primitive.A{"a", "b"}})
+		state.NextPage(tagSort)
+
+		query := state.PageQuery(filter, tagSort)
+		assert.Equal(t, PageQuery{Filter: filter, Sort: primitive.D{{Key: "tags", Value: int32(1)}, {Key: "_id", Value: int32(1)}}, Skip: 2, Limit: 2}, query)
+	})
+
+	t.Run("Falls back to skip for sort by more fields", func(t *testing.T) {
+		multiSort := primitive.D{{Key: "age", Value: int32(1)}, {Key: "name", Value: int32(1)}}
+		state := newKeysetState(primitive.M{"_id": int32(1), "age": int32(20)}, primitive.M{"_id": int32(2), "age": int32(25)})
+		state.NextPage(multiSort)
+
+		query := state.PageQuery(filter, multiSort)
+		assert.Equal(t, PageQuery{Filter: filter, Sort: multiSort, Skip: 2, Limit: 2}, query)
+	})
+
+	t.Run("Falls back to skip for unsupported sort", func(t *testing.T) {
+		metaSort := primitive.D{{Key: "score", Value: primitive.M{"$meta": "textScore"}}}
+		state := newKeysetState(primitive.M{"_id": int32(1)}, primitive.M{"_id": int32(2)})
+		state.NextPage(metaSort)
+
+		query := state.PageQuery(filter, metaSort)
+		assert.Equal(t, PageQuery{Filter: filter, Sort: metaSort, Skip: 2, Limit: 2}, query)
+	})
+
+	t.Run("Changing sort resets the keyset position", func(t *testing.T) {
+		state := newKeysetState(primitive.M{"_id": int32(1)}, primitive.M{"_id": int32(2)})
+		state.NextPage(sort)
+		state.SetSort(`{"_id": -1}`)
+
+		descSort := primitive.D{{Key: "_id", Value: int32(-1)}}
+		query := state.PageQuery(filter, descSort)
+		assert.Equal(t, int64(2), query.Skip)
+		assert.Equal(t, filter, query.Filter)
+	})
+}
@@ -133,6 +133,25 @@ func ParseSortOptions(sortOptions string) (map[string]any, error) {
 	return sort, nil
 }
 
+// ParseSortKeys parses a sort options string into primitive.D,
+// so the order of the sort keys is kept
+func ParseSortKeys(sortOptions string) (primitive.D, error) {
+	if sortOptions == "" {
+		return primitive.D{}, nil
+	}
+
+	sortOptions = util.QuoteUnquotedKeys(sortOptions)
+
+	var sort primitive.D
+	err := bson.UnmarshalExtJSON([]byte(sortOptions), false, &sort)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error parsing sort options %s", sortOptions)
+		return nil, fmt.Errorf("error parsing sort options %s: %w", sortOptions, err)
+	}
+
+	return sort, nil
+}
+
 // IndentJson indents a JSON string and returns a a buffer
 func IndentJson(jsonString string) (bytes.Buffer, error) {
 	var prettyJson bytes.Buffer
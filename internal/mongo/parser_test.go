@@ -831,3 +831,16 @@ func TestParseValueByType(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSortKeys(t *testing.T) {
+	sort, err := ParseSortKeys(`{ zeta: -1, alpha: 1 }`)
+	assert.NoError(t, err)
+	assert.Equal(t, primitive.D{{Key: "zeta", Value: int32(-1)}, {Key: "alpha", Value: int32(1)}}, sort)
+
+	sort, err = ParseSortKeys("")
+	assert.NoError(t, err)
+	assert.Empty(t, sort)
+
+	_, err = ParseSortKeys(`{ zeta: `)
+	assert.Error(t, err)
+}
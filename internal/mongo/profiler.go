@@ -0,0 +1,90 @@
+package mongo
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProfilingLevels are the levels accepted by the profile command,
+// 0 is off, 1 collects operations slower than slowms and 2 collects all operations
+var ProfilingLevels = []int32{0, 1, 2}
+
+// ProfilingStatus is the profiler configuration of a database
+type ProfilingStatus struct {
+	Level  int32
+	SlowMs int64
+}
+
+// ProfileEntry is a single operation stored in system.profile
+type ProfileEntry struct {
+	Timestamp    time.Time
+	Namespace    string
+	Op           string
+	Millis       int64
+	PlanSummary  string
+	DocsExamined int64
+	KeysExamined int64
+	NReturned    int64
+	// Command is the compact JSON of the profiled command
+	Command string
+	Raw     primitive.M
+	// Sort is the sort of the profiled command, unlike in Raw the order of its fields is kept
+	Sort primitive.D
+}
+
+// ParseProfileEntry converts the system.profile document into ProfileEntry
+func ParseProfileEntry(raw primitive.M) ProfileEntry {
+	entry := ProfileEntry{
+		Millis:       toInt64(raw["millis"]),
+		DocsExamined: toInt64(raw["docsExamined"]),
+		KeysExamined: toInt64(raw["keysExamined"]),
+		NReturned:    toInt64(raw["nreturned"]),
+		Raw:          raw,
+	}
+	entry.Namespace, _ = raw["ns"].(string)
+	entry.Op, _ = raw["op"].(string)
+	entry.PlanSummary, _ = raw["planSummary"].(string)
+
+	if ts, ok := raw["ts"].(primitive.DateTime); ok {
+		entry.Timestamp = ts.Time()
+	}
+	if command, ok := raw["command"].(primitive.M); ok {
+		entry.Command = compactJson(command)
+	}
+	return entry
+}
+
+// ParseProfiledSort returns the sort of the profiled command
+// from the system.profile document with the order of fields kept
+func ParseProfiledSort(doc bson.Raw) primitive.D {
+	value, err := doc.LookupErr("command", "sort")
+	if err != nil {
+		return nil
+	}
+	var sort primitive.D
+	if err := value.Unmarshal(&sort); err != nil {
+		return nil
+	}
+	return sort
+}
+
+// ExaminedRatio is the number of examined documents per returned document,
+// high ratio usually means that the query is missing an index
+func (e ProfileEntry) ExaminedRatio() float64 {
+	if e.NReturned == 0 {
+		return float64(e.DocsExamined)
+	}
+	return float64(e.DocsExamined) / float64(e.NReturned)
+}
+
+// Collection returns the collection part of the namespace
+func (e ProfileEntry) Collection() string {
+	_, coll, found := strings.Cut(e.Namespace, ".")
+	if !found {
+		return ""
+	}
+	return coll
+}
@@ -0,0 +1,62 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseProfileEntry(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	raw := primitive.M{
+		"op":           "query",
+		"ns":           "shop.orders.archive",
+		"millis":       int32(250),
+		"planSummary":  "COLLSCAN",
+		"docsExamined": int32(1000),
+		"keysExamined": int32(0),
+		"nreturned":    int32(4),
+		"ts":           primitive.NewDateTimeFromTime(ts),
+		"command":      primitive.M{"find": "orders.archive", "filter": primitive.M{"status": "new"}},
+	}
+
+	entry := ParseProfileEntry(raw)
+
+	assert.Equal(t, "query", entry.Op)
+	assert.Equal(t, "shop.orders.archive", entry.Namespace)
+	assert.Equal(t, "orders.archive", entry.Collection())
+	assert.Equal(t, int64(250), entry.Millis)
+	assert.Equal(t, "COLLSCAN", entry.PlanSummary)
+	assert.Equal(t, int64(1000), entry.DocsExamined)
+	assert.Equal(t, int64(4), entry.NReturned)
+	assert.Equal(t, ts, entry.Timestamp.UTC())
+	assert.Equal(t, `{"filter":{"status":"new"},"find":"orders.archive"}`, entry.Command)
+	assert.Equal(t, 250.0, entry.ExaminedRatio())
+}
+
+func TestProfileEntryExaminedRatioWithoutResults(t *testing.T) {
+	entry := ProfileEntry{DocsExamined: 30}
+	assert.Equal(t, 30.0, entry.ExaminedRatio())
+	assert.Equal(t, "", entry.Collection())
+}
+
+func TestParseProfiledSort(t *testing.T) {
+	doc, err := bson.Marshal(primitive.D{
+		{Key: "op", Value: "query"},
+		{Key: "command", Value: primitive.D{
+			{Key: "find", Value: "orders"},
+			{Key: "sort", Value: primitive.D{{Key: "status", Value: int32(1)}, {Key: "createdAt", Value: int32(-1)}}},
+		}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, primitive.D{{Key: "status", Value: int32(1)}, {Key: "createdAt", Value: int32(-1)}}, ParseProfiledSort(doc))
+
+	doc, err = bson.Marshal(primitive.D{{Key: "command", Value: primitive.D{{Key: "find", Value: "orders"}}}})
+	require.NoError(t, err)
+	assert.Nil(t, ParseProfiledSort(doc))
+}
@@ -0,0 +1,367 @@
+package mongo
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Kinds of index recommendations
+const (
+	RecommendationUnused    = "unused"
+	RecommendationRedundant = "redundant"
+	RecommendationCandidate = "candidate"
+)
+
+// Sources of query shapes used for candidate indexes
+const (
+	QuerySourceProfiler = "profiler"
+	QuerySourceQueryBar = "query bar"
+)
+
+const (
+	// maxQueryShapesPerNamespace limits query shapes remembered for a collection
+	maxQueryShapesPerNamespace = 20
+	// recommendationProfileEntries is the number of the slowest profiled operations checked
+	recommendationProfileEntries = 200
+)
+
+// IndexRecommendation is a single finding about indexes of a collection
+type IndexRecommendation struct {
+	Kind string
+	// Index is the name of the existing index, empty for candidates
+	Index string
+	// Keys is the key pattern, for candidates it's the suggested index
+	Keys   string
+	Reason string
+}
+
+// QueryShape is the filter and sort of a query
+type QueryShape struct {
+	Filter primitive.M
+	// Sort keeps the order of the fields, as it's the order of index keys
+	Sort   primitive.D
+	Source string
+}
+
+// key returns the shape with values removed, queries differing only
+// in values have the same key
+func (s QueryShape) key() string {
+	return fmt.Sprint(shapeOf(s.Filter), shapeOf(s.Sort))
+}
+
+func shapeOf(value any) any {
+	switch v := value.(type) {
+	case primitive.M:
+		shape := primitive.M{}
+		for key, item := range v {
+			shape[key] = shapeOf(item)
+		}
+		return shape
+	case primitive.D:
+		shape := make(primitive.D, 0, len(v))
+		for _, item := range v {
+			shape = append(shape, primitive.E{Key: item.Key, Value: shapeOf(item.Value)})
+		}
+		return shape
+	case primitive.A:
+		shape := make(primitive.A, 0, len(v))
+		for _, item := range v {
+			if _, isDoc := item.(primitive.M); isDoc {
+				shape = append(shape, shapeOf(item))
+			}
+		}
+		return shape
+	default:
+		return 1
+	}
+}
+
+// QueryShapeLog remembers shapes of queries run during the session
+type QueryShapeLog struct {
+	mu     sync.Mutex
+	shapes map[string][]QueryShape
+}
+
+func NewQueryShapeLog() *QueryShapeLog {
+	return &QueryShapeLog{shapes: map[string][]QueryShape{}}
+}
+
+// Record adds the query to the namespace, queries without filter and sort
+// are skipped and only the most recent shapes are kept
+func (l *QueryShapeLog) Record(namespace string, filter primitive.M, sort primitive.D) {
+	if len(filter) == 0 && len(sort) == 0 {
+		return
+	}
+	shape := QueryShape{Filter: filter, Sort: sort, Source: QuerySourceQueryBar}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	shapes := slices.DeleteFunc(l.shapes[namespace], func(s QueryShape) bool {
+		return s.key() == shape.key()
+	})
+	shapes = append(shapes, shape)
+	if len(shapes) > maxQueryShapesPerNamespace {
+		shapes = shapes[len(shapes)-maxQueryShapesPerNamespace:]
+	}
+	l.shapes[namespace] = shapes
+}
+
+// Get returns recorded shapes of the namespace
+func (l *QueryShapeLog) Get(namespace string) []QueryShape {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Clone(l.shapes[namespace])
+}
+
+// QueryShapeFromProfile returns the shape of a profiled find on the namespace
+// which scanned the whole collection
+func QueryShapeFromProfile(entry ProfileEntry, namespace string) (QueryShape, bool) {
+	if entry.Namespace != namespace || entry.PlanSummary != "COLLSCAN" {
+		return QueryShape{}, false
+	}
+	command := nestedDoc(entry.Raw, "command")
+	if _, isFind := command["find"]; !isFind {
+		return QueryShape{}, false
+	}
+	shape := QueryShape{
+		Filter: nestedDoc(command, "filter"),
+		Sort:   entry.Sort,
+		Source: QuerySourceProfiler,
+	}
+	if len(shape.Filter) == 0 && len(shape.Sort) == 0 {
+		return QueryShape{}, false
+	}
+	return shape, true
+}
+
+// UnusedIndexes returns indexes with no operations since the server restart,
+// _id, unique and TTL indexes are skipped as they are used by the server itself
+func UnusedIndexes(indexes []IndexInfo) []IndexRecommendation {
+	recommendations := []IndexRecommendation{}
+	for _, index := range indexes {
+		if !index.HasStats || index.Ops > 0 || index.Name == "_id_" {
+			continue
+		}
+		if slices.Contains(index.Properties, "UNIQUE") || slices.Contains(index.Properties, "TTL") {
+			continue
+		}
+		recommendations = append(recommendations, IndexRecommendation{
+			Kind:   RecommendationUnused,
+			Index:  index.Name,
+			Keys:   FormatShardKey(index.Keys),
+			Reason: "no operations since the server restart, consider hiding it before dropping",
+		})
+	}
+	return recommendations
+}
+
+// RedundantIndexes returns indexes whose keys are a prefix of another index,
+// the longer index serves the same queries
+func RedundantIndexes(indexes []IndexInfo) []IndexRecommendation {
+	recommendations := []IndexRecommendation{}
+	for _, index := range indexes {
+		if index.Name == "_id_" || hasAnyProperty(index, "UNIQUE", "TTL", "PARTIAL", "SPARSE") {
+			continue
+		}
+		for _, other := range indexes {
+			if other.Name == index.Name || other.Hidden || hasAnyProperty(other, "PARTIAL", "SPARSE") {
+				continue
+			}
+			if isKeyPrefix(index.Keys, other.Keys) {
+				recommendations = append(recommendations, IndexRecommendation{
+					Kind:   RecommendationRedundant,
+					Index:  index.Name,
+					Keys:   FormatShardKey(index.Keys),
+					Reason: fmt.Sprintf("prefix of %s %s", other.Name, FormatShardKey(other.Keys)),
+				})
+				break
+			}
+		}
+	}
+	return recommendations
+}
+
+func hasAnyProperty(index IndexInfo, properties ...string) bool {
+	for _, property := range properties {
+		if slices.Contains(index.Properties, property) {
+			return true
+		}
+	}
+	return false
+}
+
+// isKeyPrefix checks if keys are a strict prefix of other keys
+func isKeyPrefix(keys, other primitive.D) bool {
+	if len(keys) == 0 || len(keys) >= len(other) {
+		return false
+	}
+	for i, key := range keys {
+		if key.Key != other[i].Key || !keyValueEqual(key.Value, other[i].Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func keyValueEqual(a, b any) bool {
+	af, aNumeric := anyToFloat64(a)
+	bf, bNumeric := anyToFloat64(b)
+	if aNumeric && bNumeric {
+		return (af < 0) == (bf < 0)
+	}
+	return a == b
+}
+
+// SuggestIndex returns the index for the query following the
+// equality, sort, range rule, sort fields keep the order of the sort,
+// equality and range fields are sorted by name
+func SuggestIndex(shape QueryShape) primitive.D {
+	equality, ranges := map[string]bool{}, map[string]bool{}
+	collectFilterFields(shape.Filter, equality, ranges)
+
+	keys := primitive.D{}
+	added := map[string]bool{}
+	add := func(field string, direction int) {
+		if !added[field] {
+			added[field] = true
+			keys = append(keys, primitive.E{Key: field, Value: direction})
+		}
+	}
+
+	for _, field := range sortedKeys(equality) {
+		add(field, 1)
+	}
+	for _, field := range shape.Sort {
+		direction := 1
+		if value, ok := anyToFloat64(field.Value); ok && value < 0 {
+			direction = -1
+		}
+		add(field.Key, direction)
+	}
+	for _, field := range sortedKeys(ranges) {
+		add(field, 1)
+	}
+	return keys
+}
+
+// collectFilterFields splits filter fields into equality and range matches,
+// $or, $expr and other top level operators can't be served by a single index
+// and are skipped
+func collectFilterFields(filter primitive.M, equality, ranges map[string]bool) {
+	for field, value := range filter {
+		if field == "$and" {
+			if items, ok := value.(primitive.A); ok {
+				for _, item := range items {
+					if doc, ok := item.(primitive.M); ok {
+						collectFilterFields(doc, equality, ranges)
+					}
+				}
+			}
+			continue
+		}
+		if strings.HasPrefix(field, "$") {
+			continue
+		}
+		if isEqualityMatch(value) {
+			equality[field] = true
+			delete(ranges, field)
+		} else if !equality[field] {
+			ranges[field] = true
+		}
+	}
+}
+
+func isEqualityMatch(value any) bool {
+	switch v := value.(type) {
+	case primitive.Regex:
+		return false
+	case primitive.M:
+		for operator := range v {
+			if !strings.HasPrefix(operator, "$") {
+				// embedded document equality
+				return true
+			}
+			if operator != "$eq" && operator != "$in" {
+				return false
+			}
+		}
+		return true
+	default:
+		return true
+	}
+}
+
+func sortedKeys(fields map[string]bool) []string {
+	keys := make([]string, 0, len(fields))
+	for field := range fields {
+		keys = append(keys, field)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CandidateIndexes suggests indexes for shapes of queries which scanned
+// the whole collection, suggestions already served by an index are skipped
+func CandidateIndexes(shapes []QueryShape, indexes []IndexInfo) []IndexRecommendation {
+	type candidate struct {
+		keys    primitive.D
+		count   int
+		sources map[string]bool
+		example string
+	}
+	candidates := map[string]*candidate{}
+	order := []string{}
+
+	for _, shape := range shapes {
+		keys := SuggestIndex(shape)
+		if len(keys) == 0 || isServedByIndex(keys, indexes) {
+			continue
+		}
+		pattern := FormatShardKey(keys)
+		c, ok := candidates[pattern]
+		if !ok {
+			c = &candidate{keys: keys, sources: map[string]bool{}, example: compactJson(shape.Filter)}
+			candidates[pattern] = c
+			order = append(order, pattern)
+		}
+		c.count++
+		c.sources[shape.Source] = true
+	}
+
+	recommendations := make([]IndexRecommendation, 0, len(order))
+	for _, pattern := range order {
+		c := candidates[pattern]
+		recommendations = append(recommendations, IndexRecommendation{
+			Kind: RecommendationCandidate,
+			Keys: pattern,
+			Reason: fmt.Sprintf("%d COLLSCAN query shape(s) from %s, e.g. %s",
+				c.count, strings.Join(sortedKeys(c.sources), ", "), c.example),
+		})
+	}
+	return recommendations
+}
+
+// isServedByIndex checks if a visible index starts with the suggested fields
+func isServedByIndex(keys primitive.D, indexes []IndexInfo) bool {
+	for _, index := range indexes {
+		if index.Hidden || len(index.Keys) < len(keys) {
+			continue
+		}
+		served := true
+		for i, key := range keys {
+			if index.Keys[i].Key != key.Key {
+				served = false
+				break
+			}
+		}
+		if served {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func keys(pairs ...any) primitive.D {
+	d := primitive.D{}
+	for i := 0; i < len(pairs); i += 2 {
+		d = append(d, primitive.E{Key: pairs[i].(string), Value: pairs[i+1]})
+	}
+	return d
+}
+
+func TestUnusedIndexes(t *testing.T) {
+	indexes := []IndexInfo{
+		{Name: "_id_", Keys: keys("_id", int32(1)), HasStats: true},
+		{Name: "status_1", Keys: keys("status", int32(1)), HasStats: true},
+		{Name: "email_1", Keys: keys("email", int32(1)), HasStats: true, Properties: []string{"UNIQUE"}},
+		{Name: "name_1", Keys: keys("name", int32(1)), HasStats: true, Ops: 12},
+		{Name: "age_1", Keys: keys("age", int32(1))},
+	}
+
+	recommendations := UnusedIndexes(indexes)
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, RecommendationUnused, recommendations[0].Kind)
+	assert.Equal(t, "status_1", recommendations[0].Index)
+	assert.Equal(t, `{"status":1}`, recommendations[0].Keys)
+}
+
+func TestRedundantIndexes(t *testing.T) {
+	indexes := []IndexInfo{
+		{Name: "_id_", Keys: keys("_id", int32(1))},
+		{Name: "a_1", Keys: keys("a", int32(1))},
+		{Name: "a_1_b_1", Keys: keys("a", int32(1), "b", int32(1))},
+		{Name: "a_-1", Keys: keys("a", int32(-1))},
+		{Name: "c_1", Keys: keys("c", int32(1)), Properties: []string{"UNIQUE"}},
+		{Name: "c_1_d_1", Keys: keys("c", int32(1), "d", int32(1))},
+		{Name: "e_1", Keys: keys("e", int32(1))},
+		{Name: "e_1_f_1", Keys: keys("e", int32(1), "f", int32(1)), Properties: []string{"PARTIAL"}},
+	}
+
+	recommendations := RedundantIndexes(indexes)
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, "a_1", recommendations[0].Index)
+	assert.Equal(t, `prefix of a_1_b_1 {"a":1,"b":1}`, recommendations[0].Reason)
+}
+
+func TestSuggestIndex(t *testing.T) {
+	tests := []struct {
+		name     string
+		shape    QueryShape
+		expected primitive.D
+	}{
+		{
+			name: "equality, sort, range",
+			shape: QueryShape{
+				Filter: primitive.M{"age": primitive.M{"$gt": 18}, "status": "active"},
+				Sort:   primitive.D{{Key: "createdAt", Value: int32(-1)}},
+			},
+			expected: keys("status", 1, "createdAt", -1, "age", 1),
+		},
+		{
+			name: "sort fields keep the order of the sort",
+			shape: QueryShape{
+				Sort: primitive.D{{Key: "updatedAt", Value: int32(-1)}, {Key: "name", Value: int32(1)}, {Key: "age", Value: int32(1)}},
+			},
+			expected: keys("updatedAt", -1, "name", 1, "age", 1),
+		},
+		{
+			name: "$in is equality and regex is range",
+			shape: QueryShape{
+				Filter: primitive.M{"name": primitive.Regex{Pattern: "^a"}, "tags": primitive.M{"$in": primitive.A{"x"}}},
+			},
+			expected: keys("tags", 1, "name", 1),
+		},
+		{
+			name: "fields from $and, $or skipped",
+			shape: QueryShape{
+				Filter: primitive.M{
+					"$and": primitive.A{primitive.M{"a": 1}, primitive.M{"b": primitive.M{"$lt": 5}}},
+					"$or":  primitive.A{primitive.M{"c": 1}, primitive.M{"d": 1}},
+				},
+			},
+			expected: keys("a", 1, "b", 1),
+		},
+		{
+			name:     "field with equality and range is equality",
+			shape:    QueryShape{Filter: primitive.M{"$and": primitive.A{primitive.M{"a": primitive.M{"$gte": 1}}, primitive.M{"a": 3}}}},
+			expected: keys("a", 1),
+		},
+		{
+			name:     "only top level operators",
+			shape:    QueryShape{Filter: primitive.M{"$expr": primitive.M{"$eq": primitive.A{"$a", "$b"}}}},
+			expected: keys(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, SuggestIndex(tt.shape))
+		})
+	}
+}
+
+func TestCandidateIndexes(t *testing.T) {
+	shapes := []QueryShape{
+		{Filter: primitive.M{"status": "new"}, Source: QuerySourceProfiler},
+		{Filter: primitive.M{"status": "done"}, Source: QuerySourceQueryBar},
+		{Filter: primitive.M{"email": "a@b.c"}, Source: QuerySourceQueryBar},
+		{Filter: primitive.M{"$where": "true"}, Source: QuerySourceQueryBar},
+	}
+	indexes := []IndexInfo{
+		{Name: "email_1_name_1", Keys: keys("email", int32(1), "name", int32(1))},
+	}
+
+	recommendations := CandidateIndexes(shapes, indexes)
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, RecommendationCandidate, recommendations[0].Kind)
+	assert.Equal(t, `{"status":1}`, recommendations[0].Keys)
+	assert.Equal(t, `2 COLLSCAN query shape(s) from profiler, query bar, e.g. {"status":"new"}`, recommendations[0].Reason)
+
+	// hidden index doesn't serve queries
+	indexes[0].Hidden = true
+	assert.Len(t, CandidateIndexes(shapes, indexes), 2)
+}
+
+func TestQueryShapeLog(t *testing.T) {
+	log := NewQueryShapeLog()
+	log.Record("shop.orders", primitive.M{}, primitive.D{})
+	log.Record("shop.orders", primitive.M{"status": "new"}, nil)
+	log.Record("shop.orders", primitive.M{"status": "done"}, nil)
+	log.Record("shop.orders", primitive.M{"total": primitive.M{"$gt": 10}}, nil)
+
+	shapes := log.Get("shop.orders")
+	require.Len(t, shapes, 2)
+	// same shape with different value is moved to the end
+	assert.Equal(t, primitive.M{"status": "done"}, shapes[0].Filter)
+	assert.Equal(t, QuerySourceQueryBar, shapes[0].Source)
+	assert.Empty(t, log.Get("shop.users"))
+
+	for i := 0; i < maxQueryShapesPerNamespace+5; i++ {
+		log.Record("shop.users", primitive.M{string(rune('a' + i)): 1}, nil)
+	}
+	assert.Len(t, log.Get("shop.users"), maxQueryShapesPerNamespace)
+}
+
+func TestQueryShapeFromProfile(t *testing.T) {
+	entry := ProfileEntry{
+		Namespace:   "shop.orders",
+		PlanSummary: "COLLSCAN",
+		Raw: primitive.M{"command": primitive.M{
+			"find":   "orders",
+			"filter": primitive.M{"status": "new"},
+			"sort":   primitive.M{"createdAt": int32(-1)},
+		}},
+		Sort: primitive.D{{Key: "createdAt", Value: int32(-1)}},
+	}
+
+	shape, ok := QueryShapeFromProfile(entry, "shop.orders")
+	require.True(t, ok)
+	assert.Equal(t, QueryShape{
+		Filter: primitive.M{"status": "new"},
+		Sort:   primitive.D{{Key: "createdAt", Value: int32(-1)}},
+		Source: QuerySourceProfiler,
+	}, shape)
+
+	_, ok = QueryShapeFromProfile(entry, "shop.users")
+	assert.False(t, ok)
+
+	entry.PlanSummary = "IXSCAN { status: 1 }"
+	_, ok = QueryShapeFromProfile(entry, "shop.orders")
+	assert.False(t, ok)
+}
@@ -0,0 +1,149 @@
+package mongo
+
+import (
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNotReplicaSet is returned when the server is not a replica set member
+var ErrNotReplicaSet = errors.New("server is not a replica set member")
+
+// Replica set member states reported by replSetGetStatus
+const (
+	MemberStatePrimary   = "PRIMARY"
+	MemberStateSecondary = "SECONDARY"
+	MemberStateArbiter   = "ARBITER"
+)
+
+// ReplicaSetStatus is the topology of the replica set built from
+// replSetGetStatus and hello responses
+type ReplicaSetStatus struct {
+	Name string
+	// Me is the member the client is connected to
+	Me      string
+	Primary string
+	Term    int64
+	// ElectionId identifies the last election, it's reported only by primary
+	ElectionId string
+	// LastElection is the time when the current primary was elected
+	LastElection time.Time
+	// LastElectionReason is set only when connected to the primary
+	LastElectionReason string
+	Members            []ReplicaSetMember
+}
+
+type ReplicaSetMember struct {
+	Id      int64
+	Name    string
+	State   string
+	Healthy bool
+	Self    bool
+	Uptime  int64
+	Optime  time.Time
+	// Lag is the replication lag behind the primary, it's set only
+	// for secondaries when the primary is known
+	Lag           time.Duration
+	HasLag        bool
+	SyncSource    string
+	PingMs        int64
+	LastHeartbeat time.Time
+	Message       string
+}
+
+// ParseReplicaSetStatus converts replSetGetStatus and hello responses
+// into ReplicaSetStatus, hello can be nil
+func ParseReplicaSetStatus(status, hello primitive.M) ReplicaSetStatus {
+	replicaSet := ReplicaSetStatus{
+		Term: toInt64(status["term"]),
+	}
+	replicaSet.Name, _ = status["set"].(string)
+
+	if hello != nil {
+		replicaSet.Me, _ = hello["me"].(string)
+		replicaSet.Primary, _ = hello["primary"].(string)
+		if electionId, ok := hello["electionId"].(primitive.ObjectID); ok {
+			replicaSet.ElectionId = electionId.Hex()
+		}
+	}
+
+	candidate := nestedDoc(status, "electionCandidateMetrics")
+	replicaSet.LastElectionReason, _ = candidate["lastElectionReason"].(string)
+
+	members, _ := status["members"].(primitive.A)
+	var primaryOptime time.Time
+	for _, item := range members {
+		raw, ok := item.(primitive.M)
+		if !ok {
+			continue
+		}
+		member := parseReplicaSetMember(raw)
+		if member.State == MemberStatePrimary {
+			primaryOptime = member.Optime
+			if replicaSet.Primary == "" {
+				replicaSet.Primary = member.Name
+			}
+			if electionDate, ok := raw["electionDate"].(primitive.DateTime); ok {
+				replicaSet.LastElection = electionDate.Time()
+			}
+		}
+		replicaSet.Members = append(replicaSet.Members, member)
+	}
+
+	if !primaryOptime.IsZero() {
+		for i, member := range replicaSet.Members {
+			if member.State != MemberStateSecondary || member.Optime.IsZero() {
+				continue
+			}
+			replicaSet.Members[i].Lag = primaryOptime.Sub(member.Optime)
+			replicaSet.Members[i].HasLag = true
+		}
+	}
+
+	return replicaSet
+}
+
+func parseReplicaSetMember(raw primitive.M) ReplicaSetMember {
+	member := ReplicaSetMember{
+		Id:      toInt64(raw["_id"]),
+		Healthy: toInt64(raw["health"]) == 1,
+		Uptime:  toInt64(raw["uptime"]),
+		PingMs:  toInt64(raw["pingMs"]),
+	}
+	member.Name, _ = raw["name"].(string)
+	member.State, _ = raw["stateStr"].(string)
+	member.Self, _ = raw["self"].(bool)
+	member.Message, _ = raw["lastHeartbeatMessage"].(string)
+
+	// syncingTo was replaced by syncSourceHost in MongoDB 4.4
+	if syncSource, ok := raw["syncSourceHost"].(string); ok {
+		member.SyncSource = syncSource
+	} else if syncSource, ok := raw["syncingTo"].(string); ok {
+		member.SyncSource = syncSource
+	}
+
+	if optime, ok := raw["optimeDate"].(primitive.DateTime); ok {
+		member.Optime = optime.Time()
+	}
+	if heartbeat, ok := raw["lastHeartbeat"].(primitive.DateTime); ok {
+		member.LastHeartbeat = heartbeat.Time()
+	}
+	return member
+}
+
+// MaxLag returns the lag of the most delayed secondary,
+// false is returned if there is no primary
+func (r *ReplicaSetStatus) MaxLag() (time.Duration, bool) {
+	var lag time.Duration
+	found := false
+	for _, member := range r.Members {
+		if member.State == MemberStatePrimary {
+			found = true
+		}
+		if member.HasLag && member.Lag > lag {
+			lag = member.Lag
+		}
+	}
+	return lag, found
+}
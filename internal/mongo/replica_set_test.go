@@ -0,0 +1,122 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseReplicaSetStatus(t *testing.T) {
+	primaryOptime := time.Date(2024, 1, 1, 12, 0, 10, 0, time.UTC)
+	electionDate := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	electionId := primitive.NewObjectID()
+
+	status := primitive.M{
+		"set":  "rs0",
+		"term": int64(3),
+		"electionCandidateMetrics": primitive.M{
+			"lastElectionReason": "stepUpRequestSkipDryRun",
+		},
+		"members": primitive.A{
+			primitive.M{
+				"_id":          int32(0),
+				"name":         "mongo-1:27017",
+				"health":       float64(1),
+				"stateStr":     "PRIMARY",
+				"uptime":       int32(500),
+				"optimeDate":   primitive.NewDateTimeFromTime(primaryOptime),
+				"electionDate": primitive.NewDateTimeFromTime(electionDate),
+				"self":         true,
+			},
+			primitive.M{
+				"_id":            int32(1),
+				"name":           "mongo-2:27017",
+				"health":         float64(1),
+				"stateStr":       "SECONDARY",
+				"optimeDate":     primitive.NewDateTimeFromTime(primaryOptime.Add(-3 * time.Second)),
+				"syncSourceHost": "mongo-1:27017",
+				"pingMs":         int64(2),
+			},
+			primitive.M{
+				"_id":                  int32(2),
+				"name":                 "mongo-3:27017",
+				"health":               float64(0),
+				"stateStr":             "(not reachable/healthy)",
+				"lastHeartbeatMessage": "Couldn't get a connection within the time limit",
+			},
+			primitive.M{
+				"_id":        int32(3),
+				"name":       "mongo-4:27017",
+				"health":     float64(1),
+				"stateStr":   "SECONDARY",
+				"syncingTo":  "mongo-2:27017",
+				"optimeDate": primitive.NewDateTimeFromTime(primaryOptime.Add(-10 * time.Second)),
+			},
+		},
+	}
+	hello := primitive.M{
+		"setName":    "rs0",
+		"me":         "mongo-1:27017",
+		"primary":    "mongo-1:27017",
+		"electionId": electionId,
+	}
+
+	replicaSet := ParseReplicaSetStatus(status, hello)
+
+	assert.Equal(t, "rs0", replicaSet.Name)
+	assert.Equal(t, "mongo-1:27017", replicaSet.Me)
+	assert.Equal(t, "mongo-1:27017", replicaSet.Primary)
+	assert.Equal(t, int64(3), replicaSet.Term)
+	assert.Equal(t, electionId.Hex(), replicaSet.ElectionId)
+	assert.Equal(t, electionDate, replicaSet.LastElection.UTC())
+	assert.Equal(t, "stepUpRequestSkipDryRun", replicaSet.LastElectionReason)
+	require.Len(t, replicaSet.Members, 4)
+
+	primary := replicaSet.Members[0]
+	assert.Equal(t, MemberStatePrimary, primary.State)
+	assert.True(t, primary.Healthy)
+	assert.True(t, primary.Self)
+	assert.Equal(t, int64(500), primary.Uptime)
+	assert.False(t, primary.HasLag)
+
+	secondary := replicaSet.Members[1]
+	assert.Equal(t, int64(1), secondary.Id)
+	assert.Equal(t, "mongo-1:27017", secondary.SyncSource)
+	assert.Equal(t, int64(2), secondary.PingMs)
+	assert.True(t, secondary.HasLag)
+	assert.Equal(t, 3*time.Second, secondary.Lag)
+
+	unreachable := replicaSet.Members[2]
+	assert.False(t, unreachable.Healthy)
+	assert.False(t, unreachable.HasLag)
+	assert.Equal(t, "Couldn't get a connection within the time limit", unreachable.Message)
+
+	assert.Equal(t, "mongo-2:27017", replicaSet.Members[3].SyncSource)
+
+	lag, ok := replicaSet.MaxLag()
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, lag)
+}
+
+func TestParseReplicaSetStatusWithoutPrimary(t *testing.T) {
+	status := primitive.M{
+		"set": "rs0",
+		"members": primitive.A{
+			primitive.M{
+				"name":       "mongo-2:27017",
+				"stateStr":   "SECONDARY",
+				"optimeDate": primitive.NewDateTimeFromTime(time.Now()),
+			},
+		},
+	}
+
+	replicaSet := ParseReplicaSetStatus(status, nil)
+
+	assert.Empty(t, replicaSet.Primary)
+	assert.False(t, replicaSet.Members[0].HasLag)
+	_, ok := replicaSet.MaxLag()
+	assert.False(t, ok)
+}
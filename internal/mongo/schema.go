@@ -0,0 +1,216 @@
+package mongo
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// ArrayElementSuffix is appended to the path of an array to describe its elements
+	ArrayElementSuffix = "[]"
+	// schemaTopValues is the number of most frequent values kept for every field
+	schemaTopValues = 5
+	// schemaMaxDistinct limits the number of distinct values tracked for a field,
+	// fields with more distinct values (like _id) don't report top values
+	schemaMaxDistinct = 1000
+)
+
+// TypeStat is a BSON type observed in a field
+type TypeStat struct {
+	Type    string
+	Count   int
+	Percent float64
+}
+
+// ValueStat is a value observed in a field with number of occurrences
+type ValueStat struct {
+	Value string
+	Count int
+}
+
+// FieldSchema describes a single field path inferred from sampled documents
+type FieldSchema struct {
+	Path string
+	// Presence is the ratio of sampled documents which contain the path
+	Presence float64
+	// Types are sorted from the most common one, percentages are calculated
+	// from all values observed in the path
+	Types []TypeStat
+	// Min and Max are set only for numbers and dates, when a field
+	// holds both of them numbers are used
+	Min any
+	Max any
+	// TopValues are the most frequent scalar values, empty when
+	// every value in the sample is unique
+	TopValues []ValueStat
+}
+
+type fieldStats struct {
+	documents  int
+	values     int
+	types      map[string]int
+	minNumber  any
+	maxNumber  any
+	minDate    *primitive.DateTime
+	maxDate    *primitive.DateTime
+	distinct   map[string]int
+	overflowed bool
+}
+
+// AnalyzeSchema infers the schema of the collection from sampled documents,
+// nested documents are described with dot notation and elements of
+// arrays with ArrayElementSuffix, e.g. "tags.[]" or "items.[].price"
+func AnalyzeSchema(docs []primitive.M) []FieldSchema {
+	stats := map[string]*fieldStats{}
+
+	for _, doc := range docs {
+		seen := map[string]struct{}{}
+		for key, value := range doc {
+			collectFieldStats(stats, seen, key, value)
+		}
+		for path := range seen {
+			stats[path].documents++
+		}
+	}
+
+	fields := make([]FieldSchema, 0, len(stats))
+	for path, field := range stats {
+		fields = append(fields, field.toSchema(path, len(docs)))
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		return fields[i].Path < fields[j].Path
+	})
+
+	return fields
+}
+
+func collectFieldStats(stats map[string]*fieldStats, seen map[string]struct{}, path string, value any) {
+	field, ok := stats[path]
+	if !ok {
+		field = &fieldStats{types: map[string]int{}, distinct: map[string]int{}}
+		stats[path] = field
+	}
+	seen[path] = struct{}{}
+	field.observe(value)
+
+	switch v := value.(type) {
+	case primitive.M:
+		for key, nested := range v {
+			collectFieldStats(stats, seen, path+"."+key, nested)
+		}
+	case primitive.D:
+		for _, elem := range v {
+			collectFieldStats(stats, seen, path+"."+elem.Key, elem.Value)
+		}
+	case primitive.A:
+		for _, elem := range v {
+			collectFieldStats(stats, seen, path+"."+ArrayElementSuffix, elem)
+		}
+	}
+}
+
+func (f *fieldStats) observe(value any) {
+	f.values++
+	mongoType := util.GetMongoType(value)
+	f.types[mongoType]++
+
+	switch v := value.(type) {
+	case int32, int64, float32, float64:
+		number, _ := anyToFloat64(v)
+		if f.minNumber == nil || number < numberValue(f.minNumber) {
+			f.minNumber = v
+		}
+		if f.maxNumber == nil || number > numberValue(f.maxNumber) {
+			f.maxNumber = v
+		}
+	case primitive.DateTime:
+		if f.minDate == nil || v < *f.minDate {
+			f.minDate = &v
+		}
+		if f.maxDate == nil || v > *f.maxDate {
+			f.maxDate = &v
+		}
+	}
+
+	if mongoType == util.TypeArray || mongoType == util.TypeObject || f.overflowed {
+		return
+	}
+	key := util.StringifyMongoValueByType(value)
+	if _, ok := f.distinct[key]; !ok && len(f.distinct) >= schemaMaxDistinct {
+		f.overflowed = true
+		f.distinct = nil
+		return
+	}
+	f.distinct[key]++
+}
+
+func (f *fieldStats) toSchema(path string, total int) FieldSchema {
+	schema := FieldSchema{Path: path}
+	if total > 0 {
+		schema.Presence = float64(f.documents) / float64(total)
+	}
+
+	for mongoType, count := range f.types {
+		schema.Types = append(schema.Types, TypeStat{
+			Type:    mongoType,
+			Count:   count,
+			Percent: float64(count) / float64(f.values) * 100,
+		})
+	}
+	sort.Slice(schema.Types, func(i, j int) bool {
+		if schema.Types[i].Count != schema.Types[j].Count {
+			return schema.Types[i].Count > schema.Types[j].Count
+		}
+		return schema.Types[i].Type < schema.Types[j].Type
+	})
+
+	if f.minNumber != nil {
+		schema.Min, schema.Max = f.minNumber, f.maxNumber
+	} else if f.minDate != nil {
+		schema.Min, schema.Max = *f.minDate, *f.maxDate
+	}
+
+	for value, count := range f.distinct {
+		// values seen only once don't tell anything about the distribution
+		if count > 1 {
+			schema.TopValues = append(schema.TopValues, ValueStat{Value: value, Count: count})
+		}
+	}
+	sort.Slice(schema.TopValues, func(i, j int) bool {
+		if schema.TopValues[i].Count != schema.TopValues[j].Count {
+			return schema.TopValues[i].Count > schema.TopValues[j].Count
+		}
+		return schema.TopValues[i].Value < schema.TopValues[j].Value
+	})
+	if len(schema.TopValues) > schemaTopValues {
+		schema.TopValues = schema.TopValues[:schemaTopValues]
+	}
+
+	return schema
+}
+
+func numberValue(value any) float64 {
+	number, _ := anyToFloat64(value)
+	return number
+}
+
+// SchemaPaths returns paths of all fields that can be used in queries,
+// paths of array elements are returned without ArrayElementSuffix
+func SchemaPaths(fields []FieldSchema) []string {
+	unique := map[string]struct{}{}
+	for _, field := range fields {
+		// "items.[].price" becomes "items.price" and "tags.[]" becomes "tags"
+		path := strings.ReplaceAll(field.Path, "."+ArrayElementSuffix, "")
+		unique[path] = struct{}{}
+	}
+
+	paths := make([]string, 0, len(unique))
+	for path := range unique {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,117 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAnalyzeSchema(t *testing.T) {
+	older := primitive.NewDateTimeFromTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := primitive.NewDateTimeFromTime(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	docs := []primitive.M{
+		{
+			"name":      "John",
+			"age":       int32(30),
+			"createdAt": older,
+			"tags":      primitive.A{"admin", "dev"},
+			"address":   primitive.M{"city": "Warsaw"},
+		},
+		{
+			"name":      "Jane",
+			"age":       int64(25),
+			"createdAt": newer,
+			"tags":      primitive.A{"dev"},
+			"address":   primitive.D{{Key: "city", Value: "Warsaw"}, {Key: "zip", Value: "00-001"}},
+		},
+		{
+			"name": "John",
+			"age":  "unknown",
+			"items": primitive.A{
+				primitive.M{"price": 9.5},
+				primitive.M{"price": int32(3)},
+			},
+		},
+		{
+			"name": nil,
+		},
+	}
+
+	fields := AnalyzeSchema(docs)
+
+	byPath := map[string]FieldSchema{}
+	paths := []string{}
+	for _, field := range fields {
+		byPath[field.Path] = field
+		paths = append(paths, field.Path)
+	}
+
+	assert.Equal(t, []string{
+		"address", "address.city", "address.zip", "age", "createdAt",
+		"items", "items.[]", "items.[].price", "name", "tags", "tags.[]",
+	}, paths)
+
+	name := byPath["name"]
+	assert.Equal(t, 1.0, name.Presence)
+	assert.Equal(t, []TypeStat{
+		{Type: util.TypeString, Count: 3, Percent: 75},
+		{Type: util.TypeNull, Count: 1, Percent: 25},
+	}, name.Types)
+	assert.Equal(t, []ValueStat{{Value: "John", Count: 2}}, name.TopValues)
+	assert.Nil(t, name.Min)
+
+	age := byPath["age"]
+	assert.Equal(t, 0.75, age.Presence)
+	assert.Len(t, age.Types, 3)
+	assert.Equal(t, int64(25), age.Min)
+	assert.Equal(t, int32(30), age.Max)
+
+	createdAt := byPath["createdAt"]
+	assert.Equal(t, 0.5, createdAt.Presence)
+	assert.Equal(t, older, createdAt.Min)
+	assert.Equal(t, newer, createdAt.Max)
+
+	tags := byPath["tags.[]"]
+	assert.Equal(t, 0.5, tags.Presence)
+	assert.Equal(t, []TypeStat{{Type: util.TypeString, Count: 3, Percent: 100}}, tags.Types)
+	assert.Equal(t, []ValueStat{{Value: "dev", Count: 2}}, tags.TopValues)
+
+	assert.Equal(t, 0.5, byPath["address.city"].Presence)
+	assert.Equal(t, 0.25, byPath["address.zip"].Presence)
+
+	price := byPath["items.[].price"]
+	assert.Equal(t, 0.25, price.Presence)
+	assert.Equal(t, int32(3), price.Min)
+	assert.Equal(t, 9.5, price.Max)
+}
+
+func TestAnalyzeSchemaSkipsTopValuesOfUniqueFields(t *testing.T) {
+	docs := make([]primitive.M, 0, schemaMaxDistinct+1)
+	for i := 0; i <= schemaMaxDistinct; i++ {
+		docs = append(docs, primitive.M{"_id": primitive.NewObjectID(), "status": "active"})
+	}
+
+	fields := AnalyzeSchema(docs)
+	require.Len(t, fields, 2)
+
+	assert.Equal(t, "_id", fields[0].Path)
+	assert.Empty(t, fields[0].TopValues)
+	assert.Equal(t, []ValueStat{{Value: "active", Count: schemaMaxDistinct + 1}}, fields[1].TopValues)
+}
+
+func TestSchemaPaths(t *testing.T) {
+	fields := []FieldSchema{
+		{Path: "items"},
+		{Path: "items.[]"},
+		{Path: "items.[].price"},
+		{Path: "matrix.[].[]"},
+		{Path: "name"},
+	}
+
+	assert.Equal(t, []string{"items", "items.price", "matrix", "name"}, SchemaPaths(fields))
+}
@@ -0,0 +1,203 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Roles of the server in the deployment
+const (
+	RolePrimary    = "primary"
+	RoleSecondary  = "secondary"
+	RoleStandalone = "standalone"
+	RoleMongos     = "mongos"
+)
+
+// ServerMetrics are the values chosen from the serverStatus command
+// at the given point in time
+type ServerMetrics struct {
+	Time    time.Time
+	Host    string
+	Version string
+	Uptime  int64
+	// Role is one of the Role constants, it's taken from hello
+	Role string
+
+	// OpCounters are cumulative since the server start
+	OpCounters OpCounters
+	// BytesIn and BytesOut are cumulative since the server start
+	BytesIn  int64
+	BytesOut int64
+
+	CurrentConns   int64
+	AvailableConns int64
+	ActiveConns    int64
+
+	ResidentMb int64
+	VirtualMb  int64
+
+	CacheUsedBytes  int64
+	CacheDirtyBytes int64
+	CacheMaxBytes   int64
+
+	// ReplicaSet is nil when the server is not a replica set member
+	ReplicaSet *ReplicaSetStatus
+}
+
+type OpCounters struct {
+	Insert  int64
+	Query   int64
+	Update  int64
+	Delete  int64
+	GetMore int64
+	Command int64
+}
+
+// ServerRates are per second changes of the cumulative server counters
+type ServerRates struct {
+	Insert   float64
+	Query    float64
+	Update   float64
+	Delete   float64
+	GetMore  float64
+	Command  float64
+	BytesIn  float64
+	BytesOut float64
+}
+
+// ParseServerMetrics converts the serverStatus response into ServerMetrics
+func ParseServerMetrics(status primitive.M, at time.Time) ServerMetrics {
+	metrics := ServerMetrics{
+		Time:   at,
+		Uptime: toInt64(status["uptime"]),
+	}
+	metrics.Host, _ = status["host"].(string)
+	metrics.Version, _ = status["version"].(string)
+
+	opcounters := nestedDoc(status, "opcounters")
+	metrics.OpCounters = OpCounters{
+		Insert:  toInt64(opcounters["insert"]),
+		Query:   toInt64(opcounters["query"]),
+		Update:  toInt64(opcounters["update"]),
+		Delete:  toInt64(opcounters["delete"]),
+		GetMore: toInt64(opcounters["getmore"]),
+		Command: toInt64(opcounters["command"]),
+	}
+
+	network := nestedDoc(status, "network")
+	metrics.BytesIn = toInt64(network["bytesIn"])
+	metrics.BytesOut = toInt64(network["bytesOut"])
+
+	connections := nestedDoc(status, "connections")
+	metrics.CurrentConns = toInt64(connections["current"])
+	metrics.AvailableConns = toInt64(connections["available"])
+	metrics.ActiveConns = toInt64(connections["active"])
+
+	mem := nestedDoc(status, "mem")
+	metrics.ResidentMb = toInt64(mem["resident"])
+	metrics.VirtualMb = toInt64(mem["virtual"])
+
+	cache := nestedDoc(status, "wiredTiger", "cache")
+	metrics.CacheUsedBytes = toInt64(cache["bytes currently in the cache"])
+	metrics.CacheDirtyBytes = toInt64(cache["tracked dirty bytes in the cache"])
+	metrics.CacheMaxBytes = toInt64(cache["maximum bytes configured"])
+
+	return metrics
+}
+
+// ServerRole returns the role of the server which returned the hello response
+func ServerRole(hello primitive.M) string {
+	if IsMongosHello(hello) {
+		return RoleMongos
+	}
+	if setName, _ := hello["setName"].(string); setName == "" {
+		return RoleStandalone
+	}
+	isPrimary, ok := hello["isWritablePrimary"].(bool)
+	if !ok {
+		// isMaster is the legacy name used before MongoDB 4.4.2
+		isPrimary, _ = hello["ismaster"].(bool)
+	}
+	if isPrimary {
+		return RolePrimary
+	}
+	return RoleSecondary
+}
+
+// nestedDoc returns the sub document under the given path,
+// missing documents are returned as empty ones
+func nestedDoc(doc primitive.M, path ...string) primitive.M {
+	current := doc
+	for _, key := range path {
+		next, ok := current[key].(primitive.M)
+		if !ok {
+			return primitive.M{}
+		}
+		current = next
+	}
+	return current
+}
+
+// ComputeRates returns per second changes of counters between two metrics,
+// counters that went down (e.g. after server restart) are reported as 0
+func ComputeRates(prev, curr ServerMetrics) ServerRates {
+	seconds := curr.Time.Sub(prev.Time).Seconds()
+	if seconds <= 0 {
+		return ServerRates{}
+	}
+
+	rate := func(prev, curr int64) float64 {
+		if curr < prev {
+			return 0
+		}
+		return float64(curr-prev) / seconds
+	}
+
+	return ServerRates{
+		Insert:   rate(prev.OpCounters.Insert, curr.OpCounters.Insert),
+		Query:    rate(prev.OpCounters.Query, curr.OpCounters.Query),
+		Update:   rate(prev.OpCounters.Update, curr.OpCounters.Update),
+		Delete:   rate(prev.OpCounters.Delete, curr.OpCounters.Delete),
+		GetMore:  rate(prev.OpCounters.GetMore, curr.OpCounters.GetMore),
+		Command:  rate(prev.OpCounters.Command, curr.OpCounters.Command),
+		BytesIn:  rate(prev.BytesIn, curr.BytesIn),
+		BytesOut: rate(prev.BytesOut, curr.BytesOut),
+	}
+}
+
+// MetricHistory keeps the last values of a metric, the oldest values
+// are dropped when the history is full
+type MetricHistory struct {
+	size   int
+	values []float64
+}
+
+func NewMetricHistory(size int) *MetricHistory {
+	return &MetricHistory{
+		size:   size,
+		values: make([]float64, 0, size),
+	}
+}
+
+func (h *MetricHistory) Add(value float64) {
+	if len(h.values) == h.size {
+		h.values = h.values[1:]
+	}
+	h.values = append(h.values, value)
+}
+
+// Values returns the copy of values from the oldest one
+func (h *MetricHistory) Values() []float64 {
+	values := make([]float64, len(h.values))
+	copy(values, h.values)
+	return values
+}
+
+// Last returns the most recent value or 0 if history is empty
+func (h *MetricHistory) Last() float64 {
+	if len(h.values) == 0 {
+		return 0
+	}
+	return h.values[len(h.values)-1]
+}
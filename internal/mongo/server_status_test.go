@@ -0,0 +1,120 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseServerMetrics(t *testing.T) {
+	now := time.Now()
+	status := primitive.M{
+		"host":    "mongo-1:27017",
+		"version": "7.0.2",
+		"uptime":  float64(3600),
+		"opcounters": primitive.M{
+			"insert":  int64(10),
+			"query":   int64(20),
+			"update":  int64(30),
+			"delete":  int64(40),
+			"getmore": int64(50),
+			"command": int64(60),
+		},
+		"network":     primitive.M{"bytesIn": int64(1024), "bytesOut": int64(2048)},
+		"connections": primitive.M{"current": int32(5), "available": int32(995), "active": int32(2)},
+		"mem":         primitive.M{"resident": int32(120), "virtual": int32(2000)},
+		"wiredTiger": primitive.M{
+			"cache": primitive.M{
+				"bytes currently in the cache":     int64(300),
+				"tracked dirty bytes in the cache": int64(30),
+				"maximum bytes configured":         int64(1000),
+			},
+		},
+	}
+
+	metrics := ParseServerMetrics(status, now)
+
+	assert.Equal(t, now, metrics.Time)
+	assert.Equal(t, "mongo-1:27017", metrics.Host)
+	assert.Equal(t, "7.0.2", metrics.Version)
+	assert.Equal(t, int64(3600), metrics.Uptime)
+	assert.Equal(t, OpCounters{Insert: 10, Query: 20, Update: 30, Delete: 40, GetMore: 50, Command: 60}, metrics.OpCounters)
+	assert.Equal(t, int64(1024), metrics.BytesIn)
+	assert.Equal(t, int64(2048), metrics.BytesOut)
+	assert.Equal(t, int64(5), metrics.CurrentConns)
+	assert.Equal(t, int64(995), metrics.AvailableConns)
+	assert.Equal(t, int64(2), metrics.ActiveConns)
+	assert.Equal(t, int64(120), metrics.ResidentMb)
+	assert.Equal(t, int64(300), metrics.CacheUsedBytes)
+	assert.Equal(t, int64(30), metrics.CacheDirtyBytes)
+	assert.Equal(t, int64(1000), metrics.CacheMaxBytes)
+}
+
+func TestParseServerMetricsMissingSections(t *testing.T) {
+	metrics := ParseServerMetrics(primitive.M{"version": "4.4.0"}, time.Now())
+
+	assert.Equal(t, "4.4.0", metrics.Version)
+	assert.Equal(t, OpCounters{}, metrics.OpCounters)
+	assert.Zero(t, metrics.CacheMaxBytes)
+}
+
+func TestServerRole(t *testing.T) {
+	tests := []struct {
+		name  string
+		hello primitive.M
+		want  string
+	}{
+		{"standalone", primitive.M{"isWritablePrimary": true}, RoleStandalone},
+		{"legacy standalone", primitive.M{"ismaster": true}, RoleStandalone},
+		{"mongos", primitive.M{"isWritablePrimary": true, "msg": "isdbgrid"}, RoleMongos},
+		{"primary", primitive.M{"setName": "rs0", "isWritablePrimary": true}, RolePrimary},
+		{"legacy primary", primitive.M{"setName": "rs0", "ismaster": true}, RolePrimary},
+		{"secondary", primitive.M{"setName": "rs0", "isWritablePrimary": false, "secondary": true}, RoleSecondary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ServerRole(tt.hello))
+		})
+	}
+}
+
+func TestComputeRates(t *testing.T) {
+	start := time.Now()
+	prev := ServerMetrics{
+		Time:       start,
+		OpCounters: OpCounters{Insert: 100, Query: 50, Command: 10},
+		BytesIn:    1000,
+		BytesOut:   5000,
+	}
+	curr := ServerMetrics{
+		Time:       start.Add(2 * time.Second),
+		OpCounters: OpCounters{Insert: 120, Query: 50, Command: 4},
+		BytesIn:    3000,
+		BytesOut:   5000,
+	}
+
+	rates := ComputeRates(prev, curr)
+
+	assert.Equal(t, 10.0, rates.Insert)
+	assert.Equal(t, 0.0, rates.Query)
+	assert.Equal(t, 0.0, rates.Command, "counter reset should not produce negative rate")
+	assert.Equal(t, 1000.0, rates.BytesIn)
+	assert.Equal(t, 0.0, rates.BytesOut)
+
+	assert.Equal(t, ServerRates{}, ComputeRates(curr, curr))
+}
+
+func TestMetricHistory(t *testing.T) {
+	history := NewMetricHistory(3)
+	assert.Equal(t, 0.0, history.Last())
+
+	for _, v := range []float64{1, 2, 3, 4} {
+		history.Add(v)
+	}
+
+	assert.Equal(t, []float64{2, 3, 4}, history.Values())
+	assert.Equal(t, 4.0, history.Last())
+}
@@ -0,0 +1,183 @@
+package mongo
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrNotMongos is returned when sharding information is requested
+// from a server which is not a mongos router
+var ErrNotMongos = errors.New("server is not a mongos router")
+
+// mongosHelloMsg is the value of msg field returned by hello on mongos
+const mongosHelloMsg = "isdbgrid"
+
+// ShardingStatus describes the sharded cluster as seen from mongos
+type ShardingStatus struct {
+	Shards      []Shard
+	Collections []ShardedCollection
+	Balancer    BalancerStatus
+}
+
+type Shard struct {
+	Id       string
+	Host     string
+	Draining bool
+	Tags     []string
+}
+
+// ShardedCollection is a collection from config.collections
+// with its chunks counted per shard
+type ShardedCollection struct {
+	Namespace string
+	// Key is the shard key as compact JSON
+	Key       string
+	Unique    bool
+	NoBalance bool
+	// Chunks is the number of chunks per shard id
+	Chunks map[string]int64
+}
+
+type BalancerStatus struct {
+	// Mode is "full" when the balancer is enabled and "off" otherwise
+	Mode    string
+	InRound bool
+	Rounds  int64
+}
+
+// configCollection is a document from config.collections
+type configCollection struct {
+	Id        string           `bson:"_id"`
+	Key       primitive.D      `bson:"key"`
+	Unique    bool             `bson:"unique"`
+	NoBalance bool             `bson:"noBalance"`
+	Dropped   bool             `bson:"dropped"`
+	Uuid      primitive.Binary `bson:"uuid"`
+}
+
+// chunkCount is the number of chunks of a collection on a shard,
+// chunks are identified by ns before MongoDB 5.0 and by collection uuid since
+type chunkCount struct {
+	Id struct {
+		Ns    string           `bson:"ns"`
+		Uuid  primitive.Binary `bson:"uuid"`
+		Shard string           `bson:"shard"`
+	} `bson:"_id"`
+	Count int64 `bson:"count"`
+}
+
+// chunkCountPipeline groups chunks from config.chunks by collection and shard
+var chunkCountPipeline = primitive.A{
+	primitive.M{"$group": primitive.M{
+		"_id":   primitive.M{"ns": "$ns", "uuid": "$uuid", "shard": "$shard"},
+		"count": primitive.M{"$sum": 1},
+	}},
+}
+
+// IsMongosHello checks if the hello response was returned by mongos
+func IsMongosHello(hello primitive.M) bool {
+	msg, _ := hello["msg"].(string)
+	return msg == mongosHelloMsg
+}
+
+// ParseShard converts the config.shards document into Shard
+func ParseShard(raw primitive.M) Shard {
+	shard := Shard{
+		Draining: raw["draining"] == true,
+	}
+	shard.Id, _ = raw["_id"].(string)
+	shard.Host, _ = raw["host"].(string)
+	if tags, ok := raw["tags"].(primitive.A); ok {
+		for _, tag := range tags {
+			if tag, ok := tag.(string); ok {
+				shard.Tags = append(shard.Tags, tag)
+			}
+		}
+	}
+	return shard
+}
+
+// ParseBalancerStatus converts the balancerStatus response into BalancerStatus
+func ParseBalancerStatus(raw primitive.M) BalancerStatus {
+	status := BalancerStatus{
+		InRound: raw["inBalancerRound"] == true,
+		Rounds:  toInt64(raw["numBalancerRounds"]),
+	}
+	status.Mode, _ = raw["mode"].(string)
+	return status
+}
+
+// FormatShardKey returns the shard key as compact JSON keeping the order of fields
+func FormatShardKey(key primitive.D) string {
+	bytes, err := bson.MarshalExtJSON(key, false, false)
+	if err != nil {
+		return ""
+	}
+	return string(bytes)
+}
+
+// buildShardedCollections merges config.collections with chunk counts,
+// dropped collections are skipped and the result is sorted by namespace
+func buildShardedCollections(colls []configCollection, counts []chunkCount) []ShardedCollection {
+	byNamespace := map[string]*ShardedCollection{}
+	namespaceByUuid := map[string]string{}
+	for _, coll := range colls {
+		if coll.Dropped {
+			continue
+		}
+		byNamespace[coll.Id] = &ShardedCollection{
+			Namespace: coll.Id,
+			Key:       FormatShardKey(coll.Key),
+			Unique:    coll.Unique,
+			NoBalance: coll.NoBalance,
+			Chunks:    map[string]int64{},
+		}
+		if len(coll.Uuid.Data) > 0 {
+			namespaceByUuid[string(coll.Uuid.Data)] = coll.Id
+		}
+	}
+
+	for _, count := range counts {
+		ns := count.Id.Ns
+		if ns == "" {
+			ns = namespaceByUuid[string(count.Id.Uuid.Data)]
+		}
+		if coll, ok := byNamespace[ns]; ok {
+			coll.Chunks[count.Id.Shard] += count.Count
+		}
+	}
+
+	collections := make([]ShardedCollection, 0, len(byNamespace))
+	for _, coll := range byNamespace {
+		collections = append(collections, *coll)
+	}
+	sort.Slice(collections, func(i, j int) bool {
+		return collections[i].Namespace < collections[j].Namespace
+	})
+	return collections
+}
+
+// TotalChunks returns the number of chunks on all shards
+func (c ShardedCollection) TotalChunks() int64 {
+	var total int64
+	for _, count := range c.Chunks {
+		total += count
+	}
+	return total
+}
+
+// Database returns the database part of the namespace
+func (c ShardedCollection) Database() string {
+	db, _, _ := strings.Cut(c.Namespace, ".")
+	return db
+}
+
+// Collection returns the collection part of the namespace
+func (c ShardedCollection) Collection() string {
+	_, coll, _ := strings.Cut(c.Namespace, ".")
+	return coll
+}
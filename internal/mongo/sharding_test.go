@@ -0,0 +1,90 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestIsMongosHello(t *testing.T) {
+	assert.True(t, IsMongosHello(primitive.M{"msg": "isdbgrid"}))
+	assert.False(t, IsMongosHello(primitive.M{"setName": "rs0"}))
+}
+
+func TestParseShard(t *testing.T) {
+	shard := ParseShard(primitive.M{
+		"_id":      "shard01",
+		"host":     "shard01/mongo-1:27018,mongo-2:27018",
+		"state":    int32(1),
+		"draining": true,
+		"tags":     primitive.A{"EU", "hot"},
+	})
+
+	assert.Equal(t, "shard01", shard.Id)
+	assert.Equal(t, "shard01/mongo-1:27018,mongo-2:27018", shard.Host)
+	assert.True(t, shard.Draining)
+	assert.Equal(t, []string{"EU", "hot"}, shard.Tags)
+}
+
+func TestParseBalancerStatus(t *testing.T) {
+	status := ParseBalancerStatus(primitive.M{
+		"mode":              "full",
+		"inBalancerRound":   false,
+		"numBalancerRounds": int64(42),
+	})
+
+	assert.Equal(t, BalancerStatus{Mode: "full", Rounds: 42}, status)
+}
+
+func TestFormatShardKey(t *testing.T) {
+	key := primitive.D{{Key: "region", Value: int32(1)}, {Key: "_id", Value: "hashed"}}
+	assert.Equal(t, `{"region":1,"_id":"hashed"}`, FormatShardKey(key))
+}
+
+func TestBuildShardedCollections(t *testing.T) {
+	ordersUuid := primitive.Binary{Subtype: 4, Data: []byte("orders-uuid-0001")}
+
+	colls := []configCollection{
+		{Id: "shop.users", Key: primitive.D{{Key: "_id", Value: "hashed"}}, Unique: false},
+		{Id: "shop.orders", Key: primitive.D{{Key: "customerId", Value: int32(1)}}, Unique: true, Uuid: ordersUuid},
+		{Id: "shop.old", Key: primitive.D{{Key: "_id", Value: int32(1)}}, Dropped: true},
+	}
+
+	count := func(ns string, uuid primitive.Binary, shard string, n int64) chunkCount {
+		c := chunkCount{Count: n}
+		c.Id.Ns = ns
+		c.Id.Uuid = uuid
+		c.Id.Shard = shard
+		return c
+	}
+	counts := []chunkCount{
+		// chunks before MongoDB 5.0 are identified by namespace
+		count("shop.users", primitive.Binary{}, "shard01", 3),
+		count("shop.users", primitive.Binary{}, "shard02", 2),
+		// chunks since MongoDB 5.0 are identified by collection uuid
+		count("", ordersUuid, "shard02", 7),
+		count("shop.old", primitive.Binary{}, "shard01", 1),
+		count("", primitive.Binary{Subtype: 4, Data: []byte("unknown")}, "shard01", 4),
+	}
+
+	collections := buildShardedCollections(colls, counts)
+
+	require.Len(t, collections, 2)
+
+	orders := collections[0]
+	assert.Equal(t, "shop.orders", orders.Namespace)
+	assert.Equal(t, "shop", orders.Database())
+	assert.Equal(t, "orders", orders.Collection())
+	assert.Equal(t, `{"customerId":1}`, orders.Key)
+	assert.True(t, orders.Unique)
+	assert.Equal(t, map[string]int64{"shard02": 7}, orders.Chunks)
+	assert.Equal(t, int64(7), orders.TotalChunks())
+
+	users := collections[1]
+	assert.Equal(t, "shop.users", users.Namespace)
+	assert.Equal(t, `{"_id":"hashed"}`, users.Key)
+	assert.Equal(t, map[string]int64{"shard01": 3, "shard02": 2}, users.Chunks)
+	assert.Equal(t, int64(5), users.TotalChunks())
+}
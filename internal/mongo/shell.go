@@ -0,0 +1,320 @@
+package mongo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ShellQuery is a find or aggregate command in the mongosh syntax, e.g.
+// db.orders.find({status: "A"}, {total: 1}).sort({date: -1}).limit(20).skip(40)
+type ShellQuery struct {
+	Collection string
+	Filter     string
+	Projection string
+	Sort       string
+	// Limit and Skip are nil when they are not set in the command
+	Limit *int64
+	Skip  *int64
+	// Pipeline are the stages of db.<collection>.aggregate([...]), nil for find commands
+	Pipeline []string
+}
+
+// IsAggregation returns true for aggregate commands
+func (q *ShellQuery) IsAggregation() bool {
+	return q.Pipeline != nil
+}
+
+type shellCall struct {
+	name string
+	args []string
+}
+
+// IsShellQuery returns true when the query is a mongosh command starting with db.
+func IsShellQuery(query string) bool {
+	return strings.HasPrefix(strings.TrimSpace(query), "db.")
+}
+
+// ParseShellQuery parses find, findOne and aggregate commands in the mongosh syntax,
+// the collection can be given as db.<name> or db.getCollection("<name>").
+// Arguments are kept as they are written, so mongosh helpers like ISODate
+// or ObjectId are transformed later by ParseStringQuery
+func ParseShellQuery(query string) (*ShellQuery, error) {
+	command := strings.TrimSpace(query)
+	command = strings.TrimSpace(strings.TrimSuffix(command, ";"))
+	command, ok := strings.CutPrefix(command, "db.")
+	if !ok {
+		return nil, fmt.Errorf("missing db. prefix of the command")
+	}
+
+	collection, command, err := parseShellCollection(command)
+	if err != nil {
+		return nil, err
+	}
+	calls, err := parseShellCalls(command)
+	if err != nil {
+		return nil, err
+	}
+
+	shellQuery := &ShellQuery{Collection: collection}
+	switch calls[0].name {
+	case "find", "findOne":
+		err = shellQuery.applyFind(calls[0])
+	case "aggregate":
+		err = shellQuery.applyAggregate(calls[0])
+	default:
+		return nil, fmt.Errorf("unsupported command %s, only find, findOne and aggregate are supported", calls[0].name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, call := range calls[1:] {
+		if err := shellQuery.applyCursorCall(call); err != nil {
+			return nil, err
+		}
+	}
+	return shellQuery, nil
+}
+
+// parseShellCollection splits the command into the collection name and the method calls
+func parseShellCollection(command string) (string, string, error) {
+	if strings.HasPrefix(command, "getCollection(") {
+		calls, err := parseShellCalls(command)
+		if err != nil {
+			return "", "", err
+		}
+		if len(calls[0].args) != 1 {
+			return "", "", fmt.Errorf("getCollection expects the collection name")
+		}
+		name, err := strconv.Unquote(toDoubleQuoted(calls[0].args[0]))
+		if err != nil {
+			return "", "", fmt.Errorf("invalid collection name %s", calls[0].args[0])
+		}
+		end, _ := matchingParen(command, strings.IndexByte(command, '('))
+		return name, strings.TrimPrefix(strings.TrimSpace(command[end+1:]), "."), nil
+	}
+
+	// collection names may contain dots, so the name ends before the first method
+	open := strings.IndexByte(command, '(')
+	if open < 0 {
+		return "", "", fmt.Errorf("missing command after the collection name")
+	}
+	dot := strings.LastIndexByte(command[:open], '.')
+	if dot <= 0 {
+		return "", "", fmt.Errorf("missing collection name")
+	}
+	return command[:dot], command[dot+1:], nil
+}
+
+// parseShellCalls parses chained method calls like find({}).sort({a: 1})
+func parseShellCalls(command string) ([]shellCall, error) {
+	var calls []shellCall
+	for rest := strings.TrimSpace(command); rest != ""; rest = strings.TrimSpace(rest) {
+		if len(calls) > 0 {
+			var ok bool
+			if rest, ok = strings.CutPrefix(rest, "."); !ok {
+				return nil, fmt.Errorf("unexpected %q after %s()", rest, calls[len(calls)-1].name)
+			}
+		}
+		open := strings.IndexByte(rest, '(')
+		if open < 0 {
+			return nil, fmt.Errorf("expected method call in %q", rest)
+		}
+		end, err := matchingParen(rest, open)
+		if err != nil {
+			return nil, err
+		}
+		args, err := splitShellArgs(rest[open+1 : end])
+		if err != nil {
+			return nil, err
+		}
+		calls = append(calls, shellCall{name: strings.TrimSpace(rest[:open]), args: args})
+		rest = rest[end+1:]
+	}
+	if len(calls) == 0 {
+		return nil, fmt.Errorf("missing command")
+	}
+	return calls, nil
+}
+
+func (q *ShellQuery) applyFind(call shellCall) error {
+	if len(call.args) > 2 {
+		return fmt.Errorf("%s expects filter and projection only", call.name)
+	}
+	if len(call.args) > 0 {
+		if _, err := ParseStringQuery(call.args[0]); err != nil {
+			return err
+		}
+		q.Filter = call.args[0]
+	}
+	if len(call.args) > 1 {
+		if _, err := ParseStringQuery(call.args[1]); err != nil {
+			return err
+		}
+		q.Projection = call.args[1]
+	}
+	if call.name == "findOne" {
+		limit := int64(1)
+		q.Limit = &limit
+	}
+	return nil
+}
+
+func (q *ShellQuery) applyAggregate(call shellCall) error {
+	if len(call.args) != 1 {
+		return fmt.Errorf("aggregate expects the pipeline only")
+	}
+	pipeline := call.args[0]
+	if !strings.HasPrefix(pipeline, "[") || !strings.HasSuffix(pipeline, "]") {
+		return fmt.Errorf("aggregate pipeline has to be an array of stages")
+	}
+	stages, err := splitShellArgs(pipeline[1 : len(pipeline)-1])
+	if err != nil {
+		return err
+	}
+	if _, err := ParsePipeline(stages); err != nil {
+		return err
+	}
+	q.Pipeline = append([]string{}, stages...)
+	return nil
+}
+
+// applyCursorCall applies the method chained after the command
+func (q *ShellQuery) applyCursorCall(call shellCall) error {
+	switch call.name {
+	case "pretty", "toArray":
+		return nil
+	}
+	if q.IsAggregation() {
+		return fmt.Errorf("unsupported method %s after aggregate", call.name)
+	}
+	if len(call.args) != 1 {
+		return fmt.Errorf("%s expects one argument", call.name)
+	}
+
+	arg := call.args[0]
+	switch call.name {
+	case "sort":
+		if _, err := ParseSortOptions(arg); err != nil {
+			return err
+		}
+		q.Sort = arg
+	case "projection":
+		if _, err := ParseStringQuery(arg); err != nil {
+			return err
+		}
+		q.Projection = arg
+	case "limit", "skip":
+		value, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || value < 0 {
+			return fmt.Errorf("%s expects a non-negative number, got %s", call.name, arg)
+		}
+		if call.name == "limit" {
+			q.Limit = &value
+		} else {
+			q.Skip = &value
+		}
+	default:
+		return fmt.Errorf("unsupported method %s", call.name)
+	}
+	return nil
+}
+
+// matchingParen returns the index of the bracket closing the one at open
+func matchingParen(s string, open int) (int, error) {
+	depth, end := 0, -1
+	err := walkShell(s[open:], func(i int, ch byte) bool {
+		switch ch {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+			if depth == 0 {
+				end = open + i
+				return true
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return -1, err
+	}
+	if end < 0 {
+		return -1, fmt.Errorf("missing closing bracket in %q", s[open:])
+	}
+	return end, nil
+}
+
+// splitShellArgs splits arguments by commas which are not nested in brackets
+func splitShellArgs(s string) ([]string, error) {
+	var args []string
+	depth, start := 0, 0
+	err := walkShell(s, func(i int, ch byte) bool {
+		switch ch {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return nil, err
+	}
+	// trailing comma is allowed like in JavaScript
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		args = append(args, last)
+	}
+	return args, nil
+}
+
+// walkShell calls visit for every character outside of string and regex literals
+// until it returns true, regex literal starts with / placed where a value is expected
+func walkShell(s string, visit func(i int, ch byte) bool) error {
+	var prev byte
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ch == '"' || ch == '\'' || (ch == '/' && strings.IndexByte(":,[(", prev) >= 0) {
+			end := literalEnd(s, i)
+			if end < 0 {
+				return fmt.Errorf("unterminated literal in %q", s[i:])
+			}
+			i, prev = end, ch
+			continue
+		}
+		if visit(i, ch) {
+			return nil
+		}
+		if ch != ' ' && ch != '\t' && ch != '\n' {
+			prev = ch
+		}
+	}
+	return nil
+}
+
+// literalEnd returns the index of the character closing the literal started at start
+func literalEnd(s string, start int) int {
+	quote := s[start]
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func toDoubleQuoted(s string) string {
+	if strings.HasPrefix(s, "'") && strings.HasSuffix(s, "'") && len(s) >= 2 {
+		return `"` + strings.ReplaceAll(s[1:len(s)-1], `"`, `\"`) + `"`
+	}
+	return s
+}
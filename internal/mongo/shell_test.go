@@ -0,0 +1,102 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsShellQuery(t *testing.T) {
+	assert.True(t, IsShellQuery(`  db.orders.find({})`))
+	assert.False(t, IsShellQuery(`{status: "A"}`))
+	assert.False(t, IsShellQuery(`{db: 1}`))
+}
+
+func TestParseShellQuery(t *testing.T) {
+	limit, skip, one := int64(20), int64(40), int64(1)
+
+	cases := []struct {
+		name     string
+		input    string
+		expected *ShellQuery
+	}{
+		{
+			name:  "Find with cursor methods",
+			input: `db.orders.find({status:"A"}, {total:1}).sort({date:-1}).limit(20).skip(40);`,
+			expected: &ShellQuery{
+				Collection: "orders",
+				Filter:     `{status:"A"}`,
+				Projection: `{total:1}`,
+				Sort:       `{date:-1}`,
+				Limit:      &limit,
+				Skip:       &skip,
+			},
+		},
+		{
+			name:     "Find without arguments",
+			input:    `db.orders.find().pretty()`,
+			expected: &ShellQuery{Collection: "orders"},
+		},
+		{
+			name:     "FindOne",
+			input:    `db.orders.findOne({_id: ObjectId("507f1f77bcf86cd799439011")})`,
+			expected: &ShellQuery{Collection: "orders", Filter: `{_id: ObjectId("507f1f77bcf86cd799439011")}`, Limit: &one},
+		},
+		{
+			name:     "Collection with dots",
+			input:    `db.system.profile.find({millis: {$gt: 100}})`,
+			expected: &ShellQuery{Collection: "system.profile", Filter: `{millis: {$gt: 100}}`},
+		},
+		{
+			name:     "GetCollection",
+			input:    `db.getCollection('order-items').find({}).projection({sku: 1})`,
+			expected: &ShellQuery{Collection: "order-items", Filter: `{}`, Projection: `{sku: 1}`},
+		},
+		{
+			name:     "Brackets and commas in literals",
+			input:    `db.orders.find({note: "a, (b)", code: /^x[,)]/i})`,
+			expected: &ShellQuery{Collection: "orders", Filter: `{note: "a, (b)", code: /^x[,)]/i}`},
+		},
+		{
+			name:  "Aggregate",
+			input: `db.orders.aggregate([{$match: {status: "A"}}, {$group: {_id: "$cust_id", total: {$sum: "$amount"}}},])`,
+			expected: &ShellQuery{
+				Collection: "orders",
+				Pipeline:   []string{`{$match: {status: "A"}}`, `{$group: {_id: "$cust_id", total: {$sum: "$amount"}}}`},
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseShellQuery(tc.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+func TestParseShellQuery_Errors(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+	}{
+		{name: "Missing prefix", input: `orders.find({})`},
+		{name: "Missing method", input: `db.orders`},
+		{name: "Unsupported command", input: `db.orders.deleteMany({})`},
+		{name: "Unsupported cursor method", input: `db.orders.find({}).explain()`},
+		{name: "Unbalanced brackets", input: `db.orders.find({status: "A"}`},
+		{name: "Invalid filter", input: `db.orders.find({status: })`},
+		{name: "Invalid limit", input: `db.orders.find({}).limit(-1)`},
+		{name: "Pipeline is not an array", input: `db.orders.aggregate({$match: {}})`},
+		{name: "Method after aggregate", input: `db.orders.aggregate([]).sort({a: 1})`},
+		{name: "Text after the call", input: `db.orders.find({}) limit(2)`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseShellQuery(tc.input)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,7 +1,6 @@
 package mongo
 
 import (
-	"reflect"
 	"strings"
 	"sync"
 
@@ -12,17 +11,27 @@ import (
 // CollectionState is used to store the state of a collection and use it
 // while rendering doesn't require fetching from the database
 type CollectionState struct {
-	Db             string
-	Coll           string
-	Skip           int64
-	Limit          int64
-	Count          int64
+	Db    string
+	Coll  string
+	Skip  int64
+	Limit int64
+	Count int64
+	// CountKind tells if Count is exact, estimated, bounded or unknown
+	CountKind      CountKind
 	Sort           string
 	Filter         string
 	Projection     string
 	PipelineStages []string
-	docs           []primitive.M
-	aggDocs        []primitive.M
+	// Pagination is the way next and previous pages are fetched
+	Pagination PaginationMode
+	// ResultLimit is the position after which matching documents are not shown,
+	// it's set by limit() of a mongosh command, 0 means all documents are shown
+	ResultLimit int64
+	docs        []primitive.M
+	aggDocs     []primitive.M
+	// pageBound is the position of the current page in keyset pagination,
+	// nil means that the page is fetched by skip
+	pageBound *pageBound
 }
 
 func (c *CollectionState) GetAllDocs() []primitive.M {
@@ -35,10 +44,8 @@ func (c *CollectionState) GetAllDocs() []primitive.M {
 
 func (c *CollectionState) GetDocById(id any) primitive.M {
 	for _, doc := range c.docs {
-		if reflect.TypeOf(doc["_id"]) == reflect.TypeOf(id) {
-			if reflect.DeepEqual(doc["_id"], id) {
-				return util.DeepCopy(doc)
-			}
+		if IdsEqual(doc["_id"], id) {
+			return util.DeepCopy(doc)
 		}
 	}
 	return nil
@@ -49,7 +56,7 @@ func (c *CollectionState) GetJsonDocById(id any) (string, error) {
 	if doc == nil {
 		// fallback: search aggDocs (for aggregation results peeker)
 		for _, aggDoc := range c.aggDocs {
-			if reflect.DeepEqual(aggDoc["_id"], id) {
+			if IdsEqual(aggDoc["_id"], id) {
 				doc = util.DeepCopy(aggDoc)
 				break
 			}
@@ -71,7 +78,7 @@ func (c *CollectionState) GetValueByIdAndColumn(id any, column string) (string,
 	doc := c.GetDocById(id)
 	if doc == nil {
 		for _, aggDoc := range c.aggDocs {
-			if reflect.DeepEqual(aggDoc["_id"], id) {
+			if IdsEqual(aggDoc["_id"], id) {
 				doc = util.DeepCopy(aggDoc)
 				break
 			}
@@ -109,6 +116,7 @@ func (c *CollectionState) getFieldValue(doc primitive.M, field string) any {
 }
 
 func (c *CollectionState) SetSkip(skip int64) {
+	c.pageBound = nil
 	if skip < 0 {
 		c.Skip = 0
 	} else {
@@ -137,9 +145,10 @@ func (c *CollectionState) GetTotalPages() int64 {
 
 func NewCollectionState(db, coll string) *CollectionState {
 	return &CollectionState{
-		Db:   db,
-		Coll: coll,
-		Skip: 0,
+		Db:         db,
+		Coll:       coll,
+		Skip:       0,
+		Pagination: PaginationSkip,
 	}
 }
 
@@ -150,10 +159,12 @@ func (c *CollectionState) SetFilter(filter string) {
 		return
 	}
 	c.Filter = filter
-	c.Skip = 0
+	c.SetSkip(0)
 }
 
 func (c *CollectionState) SetSort(sort string) {
+	// page boundary holds values of the previous sort keys
+	c.pageBound = nil
 	sort = util.CleanJsonWhitespaces(sort)
 	if util.IsJsonEmpty(sort) {
 		c.Sort = ""
@@ -184,7 +195,9 @@ func (c *CollectionState) UpdateRawDoc(doc string) error {
 		return err
 	}
 	for i, existingDoc := range c.docs {
-		if reflect.DeepEqual(existingDoc["_id"], docMap["_id"]) {
+		if IdsEqual(existingDoc["_id"], docMap["_id"]) {
+			// keep the loaded _id, JSON doesn't preserve its exact type
+			docMap["_id"] = existingDoc["_id"]
 			c.docs[i] = docMap
 			return nil
 		}
@@ -200,7 +213,7 @@ func (c *CollectionState) AppendDoc(doc primitive.M) {
 
 func (c *CollectionState) DeleteDoc(id any) {
 	for i, doc := range c.docs {
-		if reflect.DeepEqual(doc["_id"], id) {
+		if IdsEqual(doc["_id"], id) {
 			c.docs = append(c.docs[:i], c.docs[i+1:]...)
 			c.Count--
 			return
@@ -208,6 +221,31 @@ func (c *CollectionState) DeleteDoc(id any) {
 	}
 }
 
+// ApplyChange applies the change stream event to the loaded documents,
+// changed documents which are not loaded yet are appended and documents
+// which don't match the filter anymore are removed
+func (c *CollectionState) ApplyChange(event ChangeEvent) {
+	if event.LeftFilter {
+		c.DeleteDoc(event.DocumentId)
+		return
+	}
+	switch event.OperationType {
+	case OperationInsert, OperationUpdate, OperationReplace:
+		if event.FullDocument == nil {
+			return
+		}
+		for i, doc := range c.docs {
+			if IdsEqual(doc["_id"], event.DocumentId) {
+				c.docs[i] = util.DeepCopy(event.FullDocument)
+				return
+			}
+		}
+		c.AppendDoc(util.DeepCopy(event.FullDocument))
+	case OperationDelete:
+		c.DeleteDoc(event.DocumentId)
+	}
+}
+
 func (c *CollectionState) SetPipelineStages(stages []string) {
 	c.PipelineStages = stages
 }
@@ -165,6 +165,46 @@ func TestCollectionState_UpdateRawDoc_WithBinaryId(t *testing.T) {
 	assert.Equal(t, "new_value", cs.docs[0]["value"])
 }
 
+func TestCollectionState_WithNonObjectIds(t *testing.T) {
+	compoundId := primitive.D{{Key: "seq", Value: int64(7)}, {Key: "region", Value: "eu"}}
+
+	testCases := []struct {
+		name    string
+		id      any
+		jsonDoc string
+	}{
+		{"UUID", testUUID, `{"_id": {"$binary": {"base64": "Ej5FZ+ibEtOkVkJmFBdAAA==", "subType": "04"}}, "value": "new"}`},
+		{"Int64", int64(42), `{"_id": 42, "value": "new"}`},
+		{"String", "key", `{"_id": "key", "value": "new"}`},
+		{"Compound", compoundId, `{"_id": {"seq": 7, "region": "eu"}, "value": "new"}`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cs := &CollectionState{Count: 1}
+			cs.PopulateDocs([]primitive.M{{"_id": tc.id, "value": "old"}})
+
+			assert.NotNil(t, cs.GetDocById(tc.id))
+
+			jsonDoc, err := cs.GetJsonDocById(tc.id)
+			assert.NoError(t, err)
+			jsonId, err := GetIDFromJSON(jsonDoc)
+			assert.NoError(t, err)
+			assert.True(t, IdsEqual(tc.id, jsonId))
+
+			// edited document parsed from JSON replaces the loaded one and keeps its _id
+			assert.NoError(t, cs.UpdateRawDoc(tc.jsonDoc))
+			assert.Len(t, cs.docs, 1)
+			assert.Equal(t, "new", cs.docs[0]["value"])
+			assert.Equal(t, tc.id, cs.docs[0]["_id"])
+
+			cs.DeleteDoc(jsonId)
+			assert.Len(t, cs.docs, 0)
+			assert.Equal(t, int64(0), cs.Count)
+		})
+	}
+}
+
 func TestCollectionState_GetValueByIdAndColumn(t *testing.T) {
 	cs := &CollectionState{
 		docs: []primitive.M{
@@ -294,3 +334,42 @@ func TestCollectionState_getFieldValue(t *testing.T) {
 		})
 	}
 }
+
+func TestCollectionState_ApplyChange(t *testing.T) {
+	cs := &CollectionState{
+		Count: 2,
+		docs: []primitive.M{
+			{"_id": "1", "value": 1},
+			{"_id": "2", "value": 2},
+		},
+	}
+
+	cs.ApplyChange(ChangeEvent{
+		OperationType: OperationUpdate,
+		DocumentId:    "1",
+		FullDocument:  primitive.M{"_id": "1", "value": 10},
+	})
+	cs.ApplyChange(ChangeEvent{
+		OperationType: OperationInsert,
+		DocumentId:    "3",
+		FullDocument:  primitive.M{"_id": "3", "value": 3},
+	})
+	cs.ApplyChange(ChangeEvent{OperationType: OperationDelete, DocumentId: "2"})
+	// update of a document which was deleted before the lookup
+	cs.ApplyChange(ChangeEvent{OperationType: OperationUpdate, DocumentId: "4"})
+
+	assert.Equal(t, []primitive.M{
+		{"_id": "1", "value": 10},
+		{"_id": "3", "value": 3},
+	}, cs.GetAllDocs())
+	assert.Equal(t, int64(2), cs.Count)
+
+	// update moving the document out of the filter
+	cs.ApplyChange(ChangeEvent{
+		OperationType: OperationUpdate,
+		DocumentId:    "1",
+		FullDocument:  primitive.M{"_id": "1", "value": -1},
+		LeftFilter:    true,
+	})
+	assert.Equal(t, []primitive.M{{"_id": "3", "value": 3}}, cs.GetAllDocs())
+}
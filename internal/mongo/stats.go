@@ -0,0 +1,89 @@
+package mongo
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CollectionStats is a summary of the collStats command
+type CollectionStats struct {
+	Name            string
+	Count           int64
+	AvgObjSize      int64
+	Size            int64
+	StorageSize     int64
+	FreeStorageSize int64
+	TotalIndexSize  int64
+	Indexes         int64
+	Capped          bool
+	Sharded         bool
+	// MaxSize and MaxDocuments are limits of capped collection
+	MaxSize      int64
+	MaxDocuments int64
+}
+
+// DatabaseStats is a summary of the dbStats command
+type DatabaseStats struct {
+	Name            string
+	Collections     int64
+	Views           int64
+	Objects         int64
+	AvgObjSize      int64
+	DataSize        int64
+	StorageSize     int64
+	FreeStorageSize int64
+	Indexes         int64
+	IndexSize       int64
+}
+
+// ParseCollectionStats converts the collStats response into CollectionStats
+func ParseCollectionStats(name string, raw primitive.M) CollectionStats {
+	return CollectionStats{
+		Name:            name,
+		Count:           toInt64(raw["count"]),
+		AvgObjSize:      toInt64(raw["avgObjSize"]),
+		Size:            toInt64(raw["size"]),
+		StorageSize:     toInt64(raw["storageSize"]),
+		FreeStorageSize: toInt64(raw["freeStorageSize"]),
+		TotalIndexSize:  toInt64(raw["totalIndexSize"]),
+		Indexes:         toInt64(raw["nindexes"]),
+		Capped:          raw["capped"] == true,
+		Sharded:         raw["sharded"] == true,
+		MaxSize:         toInt64(raw["maxSize"]),
+		MaxDocuments:    toInt64(raw["max"]),
+	}
+}
+
+// ParseDatabaseStats converts the dbStats response into DatabaseStats
+func ParseDatabaseStats(name string, raw primitive.M) DatabaseStats {
+	return DatabaseStats{
+		Name:            name,
+		Collections:     toInt64(raw["collections"]),
+		Views:           toInt64(raw["views"]),
+		Objects:         toInt64(raw["objects"]),
+		AvgObjSize:      toInt64(raw["avgObjSize"]),
+		DataSize:        toInt64(raw["dataSize"]),
+		StorageSize:     toInt64(raw["storageSize"]),
+		FreeStorageSize: toInt64(raw["freeStorageSize"]),
+		Indexes:         toInt64(raw["indexes"]),
+		IndexSize:       toInt64(raw["indexSize"]),
+	}
+}
+
+// CompressionRatio returns uncompressed data size divided by storage size,
+// 0 is returned when nothing is stored
+func (s CollectionStats) CompressionRatio() float64 {
+	return compressionRatio(s.Size, s.StorageSize)
+}
+
+// CompressionRatio returns uncompressed data size divided by storage size,
+// 0 is returned when nothing is stored
+func (s DatabaseStats) CompressionRatio() float64 {
+	return compressionRatio(s.DataSize, s.StorageSize)
+}
+
+func compressionRatio(size, storageSize int64) float64 {
+	if storageSize <= 0 {
+		return 0
+	}
+	return float64(size) / float64(storageSize)
+}
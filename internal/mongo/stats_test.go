@@ -0,0 +1,57 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseCollectionStats(t *testing.T) {
+	stats := ParseCollectionStats("logs", primitive.M{
+		"count":           int32(1000),
+		"avgObjSize":      int32(250),
+		"size":            int64(250000),
+		"storageSize":     int64(100000),
+		"freeStorageSize": int64(4096),
+		"totalIndexSize":  int64(36864),
+		"nindexes":        int32(2),
+		"capped":          true,
+		"maxSize":         int64(1048576),
+		"max":             int64(5000),
+	})
+
+	assert.Equal(t, CollectionStats{
+		Name:            "logs",
+		Count:           1000,
+		AvgObjSize:      250,
+		Size:            250000,
+		StorageSize:     100000,
+		FreeStorageSize: 4096,
+		TotalIndexSize:  36864,
+		Indexes:         2,
+		Capped:          true,
+		MaxSize:         1048576,
+		MaxDocuments:    5000,
+	}, stats)
+	assert.Equal(t, 2.5, stats.CompressionRatio())
+}
+
+func TestParseDatabaseStats(t *testing.T) {
+	stats := ParseDatabaseStats("shop", primitive.M{
+		"collections": int32(3),
+		"views":       int32(1),
+		"objects":     int64(1200),
+		"avgObjSize":  float64(120.5),
+		"dataSize":    float64(144600),
+		"storageSize": float64(72300),
+		"indexes":     int32(5),
+		"indexSize":   float64(40960),
+	})
+
+	assert.Equal(t, int64(3), stats.Collections)
+	assert.Equal(t, int64(120), stats.AvgObjSize)
+	assert.Equal(t, int64(0), stats.FreeStorageSize)
+	assert.Equal(t, 2.0, stats.CompressionRatio())
+	assert.Equal(t, 0.0, DatabaseStats{}.CompressionRatio())
+}
@@ -0,0 +1,159 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Kinds of writes staged in a transaction
+const (
+	PendingInsert     = "insert"
+	PendingUpdate     = "update"
+	PendingDelete     = "delete"
+	PendingUpdateMany = "update many"
+	PendingDeleteMany = "delete many"
+)
+
+var (
+	// ErrTransactionsUnsupported is returned when the server is a standalone,
+	// transactions require a replica set or a sharded cluster
+	ErrTransactionsUnsupported = errors.New("transactions require a replica set or a sharded cluster")
+	// ErrTransactionActive is returned when a transaction is started while another one is active
+	ErrTransactionActive = errors.New("transaction is already active")
+	// ErrNoTransaction is returned when there is no active transaction to commit or abort
+	ErrNoTransaction = errors.New("no active transaction")
+	// ErrTransactionBusy is returned when the session of the transaction is used by
+	// another operation, e.g. documents are still loading
+	ErrTransactionBusy = errors.New("transaction is busy with another operation, wait for it or cancel it")
+	// ErrTransactionAborted is returned when the server aborted the transaction,
+	// e.g. it exceeded transactionLifetimeLimitSeconds, its staged writes are rolled back
+	ErrTransactionAborted = errors.New("transaction was aborted by the server")
+)
+
+// PendingOperation is a write done inside the transaction,
+// it's not visible outside of the transaction until commit
+type PendingOperation struct {
+	Kind      string
+	Namespace string
+	// DocumentId is set for writes of a single document
+	DocumentId any
+	// Details describes the affected documents, like _id or the filter
+	Details string
+}
+
+func (o PendingOperation) String() string {
+	return fmt.Sprintf("%s %s %s", o.Kind, o.Namespace, o.Details)
+}
+
+// Transaction is a multi-document transaction in a client session,
+// document writes of the Dao run inside it until it's committed or aborted
+type Transaction struct {
+	session mongo.Session
+	started time.Time
+
+	// sessionLock is held for the whole operation using the session,
+	// as the session is not safe for concurrent use. It's a channel,
+	// so waiting for it can be cancelled with the context
+	sessionLock chan struct{}
+	// ended is set when the session is ended, it's guarded by sessionLock
+	ended bool
+
+	mu      sync.Mutex
+	pending []PendingOperation
+}
+
+func newTransaction(session mongo.Session) *Transaction {
+	return &Transaction{
+		session:     session,
+		started:     time.Now(),
+		sessionLock: make(chan struct{}, 1),
+	}
+}
+
+// Started returns the time when the transaction was started
+func (t *Transaction) Started() time.Time {
+	return t.started
+}
+
+// Pending returns writes done in the transaction in the order they were run
+func (t *Transaction) Pending() []PendingOperation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Clone(t.pending)
+}
+
+// noSuchTransactionCode is returned by the server for operations in a transaction it already aborted
+const noSuchTransactionCode = 251
+
+// isTransactionAborted reports whether err means the server aborted the transaction
+// and it can't be continued, its writes have to be retried in a new transaction
+func isTransactionAborted(err error) bool {
+	var labeled mongo.LabeledError
+	if errors.As(err, &labeled) && labeled.HasErrorLabel("TransientTransactionError") {
+		return true
+	}
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(noSuchTransactionCode)
+}
+
+// lockSession waits until the session is free or ctx is done
+func (t *Transaction) lockSession(ctx context.Context) error {
+	select {
+	case t.sessionLock <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tryLockSession locks the session only if it's free
+func (t *Transaction) tryLockSession() bool {
+	select {
+	case t.sessionLock <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (t *Transaction) unlockSession() {
+	<-t.sessionLock
+}
+
+// release unlocks the session locked by txContext, it's a no-op without transaction
+func (t *Transaction) release() {
+	if t != nil {
+		t.unlockSession()
+	}
+}
+
+// end ends the session locked by finishTransaction
+func (t *Transaction) end(ctx context.Context) {
+	t.session.EndSession(ctx)
+	t.ended = true
+	t.unlockSession()
+}
+
+// record adds the write to pending operations, id is nil for writes of many documents
+func (t *Transaction) record(kind, db, coll string, id any, details string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = append(t.pending, PendingOperation{Kind: kind, Namespace: db + "." + coll, DocumentId: id, Details: details})
+}
+
+// SupportsTransactions checks if the hello response was returned by a replica set
+// member or mongos, standalone servers don't support transactions
+func SupportsTransactions(hello primitive.M) bool {
+	if IsMongosHello(hello) {
+		return true
+	}
+	setName, _ := hello["setName"].(string)
+	return setName != ""
+}
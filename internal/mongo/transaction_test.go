@@ -0,0 +1,63 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestSupportsTransactions(t *testing.T) {
+	assert.True(t, SupportsTransactions(primitive.M{"setName": "rs0", "isWritablePrimary": true}))
+	assert.True(t, SupportsTransactions(primitive.M{"msg": "isdbgrid"}))
+	assert.False(t, SupportsTransactions(primitive.M{"isWritablePrimary": true}))
+}
+
+func TestTransactionPending(t *testing.T) {
+	tx := &Transaction{}
+	tx.record(PendingInsert, "shop", "orders", int32(1), "_id: 1")
+	tx.record(PendingDeleteMany, "shop", "users", nil, `{"active":false}: 3 deleted`)
+
+	pending := tx.Pending()
+	assert.Equal(t, []PendingOperation{
+		{Kind: PendingInsert, Namespace: "shop.orders", DocumentId: int32(1), Details: "_id: 1"},
+		{Kind: PendingDeleteMany, Namespace: "shop.users", Details: `{"active":false}: 3 deleted`},
+	}, pending)
+	assert.Equal(t, "insert shop.orders _id: 1", pending[0].String())
+
+	// returned operations are a copy
+	pending[0].Kind = PendingUpdate
+	assert.Equal(t, PendingInsert, tx.Pending()[0].Kind)
+}
+
+func TestIsTransactionAborted(t *testing.T) {
+	noSuchTransaction := mongo.CommandError{Code: noSuchTransactionCode, Name: "NoSuchTransaction", Message: "Transaction with { txnNumber: 1 } has been aborted."}
+	assert.True(t, isTransactionAborted(noSuchTransaction))
+	assert.True(t, isTransactionAborted(fmt.Errorf("failed to insert document: %w", noSuchTransaction)))
+
+	transient := mongo.WriteException{Labels: []string{"TransientTransactionError"}}
+	assert.True(t, isTransactionAborted(transient))
+
+	assert.False(t, isTransactionAborted(mongo.CommandError{Code: 11000, Message: "duplicate key"}))
+	assert.False(t, isTransactionAborted(fmt.Errorf("other")))
+
+	// errors outside of a transaction are kept as they are
+	assert.Equal(t, error(noSuchTransaction), (&Dao{}).txError(context.Background(), nil, noSuchTransaction))
+}
+
+func TestTransactionLockSession(t *testing.T) {
+	tx := newTransaction(nil)
+	assert.True(t, tx.tryLockSession())
+	assert.False(t, tx.tryLockSession())
+
+	// waiting for a busy session stops when the query is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, tx.lockSession(ctx), context.Canceled)
+
+	tx.release()
+	assert.NoError(t, tx.lockSession(context.Background()))
+}
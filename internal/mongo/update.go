@@ -0,0 +1,182 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrPreviewUnsupported is returned when the update can't be simulated with an aggregation
+var ErrPreviewUnsupported = errors.New("preview is not supported")
+
+// updatePipelineStages lists stages that can be used in the update pipeline
+var updatePipelineStages = map[string]bool{
+	"$addFields":   true,
+	"$set":         true,
+	"$project":     true,
+	"$unset":       true,
+	"$replaceRoot": true,
+	"$replaceWith": true,
+}
+
+// UpdatePreview holds a sample of documents before and after the update
+type UpdatePreview struct {
+	Before []primitive.M
+	After  []primitive.M
+	// Unavailable describes why After is empty, even though Before is not
+	Unavailable string
+}
+
+// ParseUpdate parses Extended JSON with update operators like {"$set": {...}}
+// or an update pipeline like [{"$set": {...}}], it returns primitive.D or primitive.A
+func ParseUpdate(rawUpdate string) (any, error) {
+	var wrapper primitive.D
+	if err := bson.UnmarshalExtJSON([]byte(`{"u":`+rawUpdate+`}`), false, &wrapper); err != nil {
+		return nil, fmt.Errorf("error parsing update: %w", err)
+	}
+	if len(wrapper) != 1 {
+		return nil, fmt.Errorf("error parsing update: expected single document or array")
+	}
+
+	switch update := wrapper[0].Value.(type) {
+	case primitive.D:
+		if len(update) == 0 {
+			return nil, fmt.Errorf("update document cannot be empty")
+		}
+		for _, elem := range update {
+			if !strings.HasPrefix(elem.Key, "$") {
+				return nil, fmt.Errorf("update document can only contain update operators, got %s", elem.Key)
+			}
+		}
+		return update, nil
+	case primitive.A:
+		if len(update) == 0 {
+			return nil, fmt.Errorf("update pipeline cannot be empty")
+		}
+		for i, stage := range update {
+			stageDoc, ok := stage.(primitive.D)
+			if !ok || len(stageDoc) != 1 {
+				return nil, fmt.Errorf("stage %d: each stage must be a document with a single operator", i)
+			}
+			if !updatePipelineStages[stageDoc[0].Key] {
+				return nil, fmt.Errorf("stage %d: %s is not allowed in update pipeline", i, stageDoc[0].Key)
+			}
+		}
+		return update, nil
+	default:
+		return nil, fmt.Errorf("update must be a document or an array of stages")
+	}
+}
+
+// UpdatePreviewStages converts the update into aggregation stages that produce
+// documents as they would look after the update, without modifying them
+func UpdatePreviewStages(update any) (primitive.A, error) {
+	switch u := update.(type) {
+	case primitive.A:
+		return u, nil
+	case primitive.D:
+		stages := primitive.A{}
+		for _, elem := range u {
+			fields, ok := elem.Value.(primitive.D)
+			if !ok {
+				return nil, fmt.Errorf("%s must be a document", elem.Key)
+			}
+			operatorStages, err := operatorPreviewStages(elem.Key, fields)
+			if err != nil {
+				return nil, err
+			}
+			stages = append(stages, operatorStages...)
+		}
+		return stages, nil
+	default:
+		return nil, fmt.Errorf("unsupported update type %T", update)
+	}
+}
+
+func operatorPreviewStages(operator string, fields primitive.D) (primitive.A, error) {
+	set := primitive.D{}
+	unset := primitive.A{}
+
+	for _, field := range fields {
+		path := "$" + field.Key
+		switch operator {
+		case "$set":
+			set = append(set, primitive.E{Key: field.Key, Value: literal(field.Value)})
+		case "$setOnInsert":
+			// existing documents are never inserted, so nothing changes
+		case "$unset":
+			unset = append(unset, field.Key)
+		case "$inc":
+			set = append(set, primitive.E{Key: field.Key, Value: primitive.D{{Key: "$add", Value: primitive.A{ifNull(path, 0), literal(field.Value)}}}})
+		case "$mul":
+			set = append(set, primitive.E{Key: field.Key, Value: primitive.D{{Key: "$multiply", Value: primitive.A{ifNull(path, 0), literal(field.Value)}}}})
+		case "$min", "$max":
+			set = append(set, primitive.E{Key: field.Key, Value: primitive.D{{Key: operator, Value: primitive.A{path, literal(field.Value)}}}})
+		case "$rename":
+			newName, ok := field.Value.(string)
+			if !ok {
+				return nil, fmt.Errorf("$rename target for %s must be a string", field.Key)
+			}
+			set = append(set, primitive.E{Key: newName, Value: path})
+			unset = append(unset, field.Key)
+		case "$currentDate":
+			set = append(set, primitive.E{Key: field.Key, Value: currentDateValue(field.Value)})
+		case "$push":
+			values, err := pushedValues(field.Value)
+			if err != nil {
+				return nil, err
+			}
+			set = append(set, primitive.E{Key: field.Key, Value: primitive.D{{Key: "$concatArrays", Value: primitive.A{ifNull(path, primitive.A{}), literal(values)}}}})
+		default:
+			return nil, fmt.Errorf("%w for %s", ErrPreviewUnsupported, operator)
+		}
+	}
+
+	stages := primitive.A{}
+	if len(set) > 0 {
+		stages = append(stages, primitive.D{{Key: "$set", Value: set}})
+	}
+	if len(unset) > 0 {
+		stages = append(stages, primitive.D{{Key: "$unset", Value: unset}})
+	}
+	return stages, nil
+}
+
+func literal(value any) primitive.D {
+	return primitive.D{{Key: "$literal", Value: value}}
+}
+
+func ifNull(path string, fallback any) primitive.D {
+	return primitive.D{{Key: "$ifNull", Value: primitive.A{path, fallback}}}
+}
+
+func currentDateValue(value any) string {
+	if spec, ok := value.(primitive.D); ok {
+		for _, elem := range spec {
+			if elem.Key == "$type" && elem.Value == "timestamp" {
+				return "$$CLUSTER_TIME"
+			}
+		}
+	}
+	return "$$NOW"
+}
+
+// pushedValues returns values appended by $push, modifiers other than $each
+// change the array in ways that are not simulated
+func pushedValues(value any) (primitive.A, error) {
+	spec, ok := value.(primitive.D)
+	if !ok || len(spec) == 0 || !strings.HasPrefix(spec[0].Key, "$") {
+		return primitive.A{value}, nil
+	}
+	if len(spec) > 1 || spec[0].Key != "$each" {
+		return nil, fmt.Errorf("%w for $push modifiers other than $each", ErrPreviewUnsupported)
+	}
+	values, ok := spec[0].Value.(primitive.A)
+	if !ok {
+		return nil, fmt.Errorf("$each must be an array")
+	}
+	return values, nil
+}
@@ -0,0 +1,114 @@
+package mongo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseUpdate(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected any
+		wantErr  bool
+	}{
+		{
+			name:  "update operators",
+			input: `{"$set": {"status": "done", "n": {"$numberLong": "1"}}, "$unset": {"tmp": ""}}`,
+			expected: primitive.D{
+				{Key: "$set", Value: primitive.D{{Key: "status", Value: "done"}, {Key: "n", Value: int64(1)}}},
+				{Key: "$unset", Value: primitive.D{{Key: "tmp", Value: ""}}},
+			},
+		},
+		{
+			name:  "update pipeline",
+			input: `[{"$set": {"total": {"$add": ["$a", "$b"]}}}, {"$unset": "a"}]`,
+			expected: primitive.A{
+				primitive.D{{Key: "$set", Value: primitive.D{{Key: "total", Value: primitive.D{{Key: "$add", Value: primitive.A{"$a", "$b"}}}}}}},
+				primitive.D{{Key: "$unset", Value: "a"}},
+			},
+		},
+		{name: "replacement document", input: `{"status": "done"}`, wantErr: true},
+		{name: "empty document", input: `{}`, wantErr: true},
+		{name: "empty pipeline", input: `[]`, wantErr: true},
+		{name: "stage not allowed in update", input: `[{"$match": {"a": 1}}]`, wantErr: true},
+		{name: "not a document", input: `"text"`, wantErr: true},
+		{name: "invalid json", input: `{"$set": }`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			update, err := ParseUpdate(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, update)
+		})
+	}
+}
+
+func TestUpdatePreviewStages(t *testing.T) {
+	t.Run("pipeline is used as it is", func(t *testing.T) {
+		pipeline := primitive.A{primitive.D{{Key: "$set", Value: primitive.D{{Key: "a", Value: 1}}}}}
+		stages, err := UpdatePreviewStages(pipeline)
+		require.NoError(t, err)
+		assert.Equal(t, pipeline, stages)
+	})
+
+	t.Run("operators are converted to stages", func(t *testing.T) {
+		update, err := ParseUpdate(`{
+			"$set": {"status": "$literal"},
+			"$inc": {"count": 2},
+			"$rename": {"old": "new"},
+			"$push": {"tags": {"$each": ["a", "b"]}},
+			"$setOnInsert": {"created": true}
+		}`)
+		require.NoError(t, err)
+
+		stages, err := UpdatePreviewStages(update)
+		require.NoError(t, err)
+
+		expected := primitive.A{
+			primitive.D{{Key: "$set", Value: primitive.D{
+				{Key: "status", Value: primitive.D{{Key: "$literal", Value: "$literal"}}},
+			}}},
+			primitive.D{{Key: "$set", Value: primitive.D{
+				{Key: "count", Value: primitive.D{{Key: "$add", Value: primitive.A{
+					primitive.D{{Key: "$ifNull", Value: primitive.A{"$count", 0}}},
+					primitive.D{{Key: "$literal", Value: int32(2)}},
+				}}}},
+			}}},
+			primitive.D{{Key: "$set", Value: primitive.D{{Key: "new", Value: "$old"}}}},
+			primitive.D{{Key: "$unset", Value: primitive.A{"old"}}},
+			primitive.D{{Key: "$set", Value: primitive.D{
+				{Key: "tags", Value: primitive.D{{Key: "$concatArrays", Value: primitive.A{
+					primitive.D{{Key: "$ifNull", Value: primitive.A{"$tags", primitive.A{}}}},
+					primitive.D{{Key: "$literal", Value: primitive.A{"a", "b"}}},
+				}}}},
+			}}},
+		}
+		assert.Equal(t, expected, stages)
+	})
+
+	t.Run("unsupported operator", func(t *testing.T) {
+		update, err := ParseUpdate(`{"$pull": {"tags": "a"}}`)
+		require.NoError(t, err)
+
+		_, err = UpdatePreviewStages(update)
+		assert.True(t, errors.Is(err, ErrPreviewUnsupported))
+	})
+
+	t.Run("unsupported push modifier", func(t *testing.T) {
+		update, err := ParseUpdate(`{"$push": {"tags": {"$each": ["a"], "$slice": 3}}}`)
+		require.NoError(t, err)
+
+		_, err = UpdatePreviewStages(update)
+		assert.True(t, errors.Is(err, ErrPreviewUnsupported))
+	})
+}
@@ -0,0 +1,173 @@
+package mongo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RoleRef is a reference to a role defined in the given database
+type RoleRef struct {
+	Role string
+	Db   string
+}
+
+// String returns the role in role@db notation
+func (r RoleRef) String() string {
+	return r.Role + "@" + r.Db
+}
+
+// User is a user returned by usersInfo
+type User struct {
+	Name       string
+	Db         string
+	Roles      []RoleRef
+	Mechanisms []string
+	// Privileges are set only when the user is fetched with privileges
+	Privileges []Privilege
+}
+
+// Role is a role returned by rolesInfo
+type Role struct {
+	Name      string
+	Db        string
+	IsBuiltin bool
+	Roles     []RoleRef
+	// Privileges are set only when the role is fetched with privileges
+	Privileges []Privilege
+}
+
+// Privilege is a set of actions allowed on a resource
+type Privilege struct {
+	Resource string
+	Actions  []string
+}
+
+// ParseUser converts the usersInfo document into User
+func ParseUser(raw primitive.M) User {
+	user := User{
+		Roles:      parseRoleRefs(raw["roles"]),
+		Privileges: parsePrivileges(raw["inheritedPrivileges"]),
+	}
+	user.Name, _ = raw["user"].(string)
+	user.Db, _ = raw["db"].(string)
+	if mechanisms, ok := raw["mechanisms"].(primitive.A); ok {
+		for _, mechanism := range mechanisms {
+			if mechanism, ok := mechanism.(string); ok {
+				user.Mechanisms = append(user.Mechanisms, mechanism)
+			}
+		}
+	}
+	return user
+}
+
+// ParseRole converts the rolesInfo document into Role
+func ParseRole(raw primitive.M) Role {
+	role := Role{
+		IsBuiltin:  raw["isBuiltin"] == true,
+		Roles:      parseRoleRefs(raw["roles"]),
+		Privileges: parsePrivileges(raw["privileges"]),
+	}
+	role.Name, _ = raw["role"].(string)
+	role.Db, _ = raw["db"].(string)
+	return role
+}
+
+func parseRoleRefs(value any) []RoleRef {
+	items, ok := value.(primitive.A)
+	if !ok {
+		return nil
+	}
+	roles := make([]RoleRef, 0, len(items))
+	for _, item := range items {
+		raw, ok := item.(primitive.M)
+		if !ok {
+			continue
+		}
+		role := RoleRef{}
+		role.Role, _ = raw["role"].(string)
+		role.Db, _ = raw["db"].(string)
+		roles = append(roles, role)
+	}
+	return roles
+}
+
+func parsePrivileges(value any) []Privilege {
+	items, ok := value.(primitive.A)
+	if !ok {
+		return nil
+	}
+	privileges := make([]Privilege, 0, len(items))
+	for _, item := range items {
+		raw, ok := item.(primitive.M)
+		if !ok {
+			continue
+		}
+		privilege := Privilege{
+			Resource: formatResource(nestedDoc(raw, "resource")),
+		}
+		if actions, ok := raw["actions"].(primitive.A); ok {
+			for _, action := range actions {
+				if action, ok := action.(string); ok {
+					privilege.Actions = append(privilege.Actions, action)
+				}
+			}
+		}
+		sort.Strings(privilege.Actions)
+		privileges = append(privileges, privilege)
+	}
+	return privileges
+}
+
+// formatResource returns the privilege resource in db.collection notation,
+// empty database or collection means any
+func formatResource(resource primitive.M) string {
+	if resource["cluster"] == true {
+		return "cluster"
+	}
+	if resource["anyResource"] == true {
+		return "anyResource"
+	}
+	db, _ := resource["db"].(string)
+	coll, _ := resource["collection"].(string)
+	if db == "" {
+		db = "*"
+	}
+	if coll == "" {
+		coll = "*"
+	}
+	return db + "." + coll
+}
+
+// ParseRoleRefs parses comma separated roles in role or role@db notation,
+// roles without database are defined in defaultDb
+func ParseRoleRefs(text, defaultDb string) ([]RoleRef, error) {
+	roles := []RoleRef{}
+	for _, item := range strings.Split(text, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		role, db, found := strings.Cut(item, "@")
+		role, db = strings.TrimSpace(role), strings.TrimSpace(db)
+		if !found {
+			db = defaultDb
+		}
+		if role == "" || db == "" {
+			return nil, fmt.Errorf("invalid role %q, expected role or role@db", item)
+		}
+		roles = append(roles, RoleRef{Role: role, Db: db})
+	}
+	return roles, nil
+}
+
+// roleRefsToBson converts roles into the format accepted by user management commands
+func roleRefsToBson(roles []RoleRef) primitive.A {
+	values := make(primitive.A, 0, len(roles))
+	for _, role := range roles {
+		values = append(values, primitive.M{"role": role.Role, "db": role.Db})
+	}
+	return values
+}
@@ -0,0 +1,89 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseUser(t *testing.T) {
+	user := ParseUser(primitive.M{
+		"_id":        "shop.alice",
+		"user":       "alice",
+		"db":         "shop",
+		"mechanisms": primitive.A{"SCRAM-SHA-1", "SCRAM-SHA-256"},
+		"roles": primitive.A{
+			primitive.M{"role": "readWrite", "db": "shop"},
+			primitive.M{"role": "read", "db": "reporting"},
+		},
+		"inheritedPrivileges": primitive.A{
+			primitive.M{
+				"resource": primitive.M{"db": "shop", "collection": ""},
+				"actions":  primitive.A{"insert", "find"},
+			},
+			primitive.M{
+				"resource": primitive.M{"cluster": true},
+				"actions":  primitive.A{"listDatabases"},
+			},
+		},
+	})
+
+	assert.Equal(t, "alice", user.Name)
+	assert.Equal(t, "shop", user.Db)
+	assert.Equal(t, []string{"SCRAM-SHA-1", "SCRAM-SHA-256"}, user.Mechanisms)
+	assert.Equal(t, []RoleRef{{Role: "readWrite", Db: "shop"}, {Role: "read", Db: "reporting"}}, user.Roles)
+	require.Len(t, user.Privileges, 2)
+	assert.Equal(t, Privilege{Resource: "shop.*", Actions: []string{"find", "insert"}}, user.Privileges[0])
+	assert.Equal(t, Privilege{Resource: "cluster", Actions: []string{"listDatabases"}}, user.Privileges[1])
+}
+
+func TestParseRole(t *testing.T) {
+	role := ParseRole(primitive.M{
+		"role":      "reportReader",
+		"db":        "shop",
+		"isBuiltin": false,
+		"roles":     primitive.A{primitive.M{"role": "read", "db": "shop"}},
+		"privileges": primitive.A{
+			primitive.M{
+				"resource": primitive.M{"db": "shop", "collection": "reports"},
+				"actions":  primitive.A{"find"},
+			},
+			primitive.M{
+				"resource": primitive.M{"anyResource": true},
+				"actions":  primitive.A{"anyAction"},
+			},
+		},
+	})
+
+	assert.Equal(t, "reportReader", role.Name)
+	assert.Equal(t, "shop", role.Db)
+	assert.False(t, role.IsBuiltin)
+	assert.Equal(t, []RoleRef{{Role: "read", Db: "shop"}}, role.Roles)
+	assert.Equal(t, []Privilege{
+		{Resource: "shop.reports", Actions: []string{"find"}},
+		{Resource: "anyResource", Actions: []string{"anyAction"}},
+	}, role.Privileges)
+}
+
+func TestParseRoleRefs(t *testing.T) {
+	roles, err := ParseRoleRefs(" readWrite, read@reporting ,,clusterMonitor@admin", "shop")
+	require.NoError(t, err)
+	assert.Equal(t, []RoleRef{
+		{Role: "readWrite", Db: "shop"},
+		{Role: "read", Db: "reporting"},
+		{Role: "clusterMonitor", Db: "admin"},
+	}, roles)
+	assert.Equal(t, "read@reporting", roles[1].String())
+
+	roles, err = ParseRoleRefs("", "shop")
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+
+	_, err = ParseRoleRefs("read@", "shop")
+	assert.Error(t, err)
+
+	_, err = ParseRoleRefs("@shop", "shop")
+	assert.Error(t, err)
+}
@@ -0,0 +1,92 @@
+package mongo
+
+import (
+	"fmt"
+	"slices"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ValidationLevels and ValidationActions are the values accepted by collMod
+var (
+	ValidationLevels  = []string{"off", "strict", "moderate"}
+	ValidationActions = []string{"error", "warn"}
+)
+
+const (
+	defaultValidationLevel  = "strict"
+	defaultValidationAction = "error"
+)
+
+// ValidationRules are the document validation options of a collection
+type ValidationRules struct {
+	Validator primitive.M
+	Level     string
+	Action    string
+}
+
+// ParseValidationRules reads validation rules from the options returned
+// by listCollections, defaults are used for missing values
+func ParseValidationRules(options primitive.M) ValidationRules {
+	rules := ValidationRules{
+		Validator: primitive.M{},
+		Level:     defaultValidationLevel,
+		Action:    defaultValidationAction,
+	}
+	if validator, ok := options["validator"].(primitive.M); ok {
+		rules.Validator = validator
+	}
+	if level, ok := options["validationLevel"].(string); ok {
+		rules.Level = level
+	}
+	if action, ok := options["validationAction"].(string); ok {
+		rules.Action = action
+	}
+	return rules
+}
+
+// ToJson returns validation rules as a document in the collMod format
+func (v ValidationRules) ToJson() (string, error) {
+	return ParseBsonDocument(primitive.M{
+		"validator":        v.Validator,
+		"validationLevel":  v.Level,
+		"validationAction": v.Action,
+	})
+}
+
+// ParseValidationRulesJson parses validation rules edited by the user,
+// missing level and action fall back to the defaults
+func ParseValidationRulesJson(text string) (ValidationRules, error) {
+	doc, err := ParseJsonToBson(text)
+	if err != nil {
+		return ValidationRules{}, err
+	}
+
+	for key := range doc {
+		switch key {
+		case "validator", "validationLevel", "validationAction":
+		default:
+			return ValidationRules{}, fmt.Errorf("unknown field %q, expected validator, validationLevel or validationAction", key)
+		}
+	}
+	if _, ok := doc["validator"]; ok {
+		if _, isDoc := doc["validator"].(primitive.M); !isDoc {
+			return ValidationRules{}, fmt.Errorf("validator must be a document")
+		}
+	}
+
+	rules := ParseValidationRules(doc)
+	if !slices.Contains(ValidationLevels, rules.Level) {
+		return ValidationRules{}, fmt.Errorf("invalid validationLevel %q, expected one of %v", rules.Level, ValidationLevels)
+	}
+	if !slices.Contains(ValidationActions, rules.Action) {
+		return ValidationRules{}, fmt.Errorf("invalid validationAction %q, expected one of %v", rules.Action, ValidationActions)
+	}
+	return rules, nil
+}
+
+// InvalidDocumentsFilter returns the filter matching documents
+// which do not pass the validator
+func InvalidDocumentsFilter(validator primitive.M) primitive.M {
+	return primitive.M{"$nor": primitive.A{validator}}
+}
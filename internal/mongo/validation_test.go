@@ -0,0 +1,81 @@
+package mongo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseValidationRules(t *testing.T) {
+	validator := primitive.M{"$jsonSchema": primitive.M{"required": primitive.A{"name"}}}
+
+	rules := ParseValidationRules(primitive.M{
+		"validator":        validator,
+		"validationLevel":  "moderate",
+		"validationAction": "warn",
+	})
+	assert.Equal(t, ValidationRules{Validator: validator, Level: "moderate", Action: "warn"}, rules)
+
+	rules = ParseValidationRules(nil)
+	assert.Equal(t, ValidationRules{Validator: primitive.M{}, Level: "strict", Action: "error"}, rules)
+}
+
+func TestValidationRulesJsonRoundTrip(t *testing.T) {
+	rules := ValidationRules{
+		Validator: primitive.M{"$jsonSchema": primitive.M{"bsonType": "object"}},
+		Level:     "strict",
+		Action:    "warn",
+	}
+
+	text, err := rules.ToJson()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"validationAction":"warn","validationLevel":"strict","validator":{"$jsonSchema":{"bsonType":"object"}}}`, text)
+
+	parsed, err := ParseValidationRulesJson(text)
+	require.NoError(t, err)
+	assert.Equal(t, rules, parsed)
+}
+
+func TestParseValidationRulesJson(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected ValidationRules
+		wantErr  bool
+	}{
+		{
+			name:     "defaults for missing level and action",
+			text:     `{"validator": {"age": {"$gte": 0}}}`,
+			expected: ValidationRules{Validator: primitive.M{"age": primitive.M{"$gte": int32(0)}}, Level: "strict", Action: "error"},
+		},
+		{
+			name:     "empty validator removes validation",
+			text:     `{"validator": {}, "validationLevel": "off"}`,
+			expected: ValidationRules{Validator: primitive.M{}, Level: "off", Action: "error"},
+		},
+		{name: "invalid level", text: `{"validationLevel": "always"}`, wantErr: true},
+		{name: "invalid action", text: `{"validationAction": "log"}`, wantErr: true},
+		{name: "unknown field", text: `{"validator": {}, "collMod": "users"}`, wantErr: true},
+		{name: "validator is not a document", text: `{"validator": []}`, wantErr: true},
+		{name: "invalid json", text: `{"validator": `, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := ParseValidationRulesJson(tt.text)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, rules)
+		})
+	}
+}
+
+func TestInvalidDocumentsFilter(t *testing.T) {
+	validator := primitive.M{"age": primitive.M{"$gte": 0}}
+	assert.Equal(t, primitive.M{"$nor": primitive.A{validator}}, InvalidDocumentsFilter(validator))
+}
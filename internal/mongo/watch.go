@@ -0,0 +1,185 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// changeStreamUnsupportedCode is returned by standalone servers
+// which don't support the $changeStream stage
+const changeStreamUnsupportedCode = 40573
+
+var (
+	// ErrChangeStreamUnsupported is returned when the server is not
+	// a replica set member or mongos, so change streams can't be opened
+	ErrChangeStreamUnsupported = errors.New("change streams are only supported on replica sets and sharded clusters")
+	// ErrWatchFilterUnsupported is returned when the filter uses an operator
+	// which can't be matched against change events
+	ErrWatchFilterUnsupported = errors.New("operator is not supported in the live mode filter")
+)
+
+// Change stream operation types that modify documents of the collection
+const (
+	OperationInsert  = "insert"
+	OperationUpdate  = "update"
+	OperationReplace = "replace"
+	OperationDelete  = "delete"
+)
+
+// ChangeEvent is a single event received from the change stream
+type ChangeEvent struct {
+	OperationType string
+	// DocumentId is the _id of the changed document
+	DocumentId any
+	// FullDocument is empty for deletes and for updates
+	// of documents that were removed before the lookup
+	FullDocument primitive.M
+	// LeftFilter is set for updates of shown documents
+	// which don't match the filter anymore
+	LeftFilter bool
+	Time       time.Time
+}
+
+// ParseChangeEvent converts raw change stream document into ChangeEvent
+func ParseChangeEvent(raw primitive.M) ChangeEvent {
+	event := ChangeEvent{Time: time.Now()}
+	event.OperationType, _ = raw["operationType"].(string)
+
+	if documentKey, ok := raw["documentKey"].(primitive.M); ok {
+		event.DocumentId = documentKey["_id"]
+	}
+	if fullDocument, ok := raw["fullDocument"].(primitive.M); ok {
+		event.FullDocument = fullDocument
+	}
+	if clusterTime, ok := raw["clusterTime"].(primitive.Timestamp); ok {
+		event.Time = time.Unix(int64(clusterTime.T), 0)
+	}
+
+	return event
+}
+
+// WatchPipeline builds the change stream pipeline for the given filter,
+// filter is matched against the fullDocument, deletes are always passed
+// as they don't contain the document. Updates and replaces of the shown
+// documents are passed too, so rows of documents that stopped matching
+// the filter can be removed
+func WatchPipeline(filter primitive.M, shownIds []any) (mongo.Pipeline, error) {
+	if len(filter) == 0 {
+		return mongo.Pipeline{}, nil
+	}
+
+	prefixed, err := prefixFilterFields(filter, "fullDocument.")
+	if err != nil {
+		return nil, err
+	}
+	conditions := primitive.A{
+		primitive.M{"operationType": OperationDelete},
+		prefixed,
+	}
+	if len(shownIds) > 0 {
+		conditions = append(conditions, primitive.M{
+			"operationType":   primitive.M{"$in": primitive.A{OperationUpdate, OperationReplace}},
+			"documentKey._id": primitive.M{"$in": shownIds},
+		})
+	}
+	return mongo.Pipeline{{{Key: "$match", Value: primitive.M{"$or": conditions}}}}, nil
+}
+
+// prefixFilterFields prefixes every field path of the filter, logical
+// operators like $and or $or are kept and their conditions are prefixed,
+// field paths in $expr are prefixed too. Operators which can't be
+// rewritten for the change event, like $where or $text, are rejected
+func prefixFilterFields(filter primitive.M, prefix string) (primitive.M, error) {
+	prefixed := primitive.M{}
+	for key, value := range filter {
+		switch {
+		case !strings.HasPrefix(key, "$"):
+			prefixed[prefix+key] = value
+		case key == "$expr":
+			prefixed[key] = prefixExprFields(value, prefix)
+		case key == "$comment":
+			prefixed[key] = value
+		case key == "$and" || key == "$or" || key == "$nor":
+			conditions, ok := value.(primitive.A)
+			if !ok {
+				return nil, fmt.Errorf("%s in the filter must be an array", key)
+			}
+			nested := make(primitive.A, 0, len(conditions))
+			for _, condition := range conditions {
+				if conditionFilter, isFilter := condition.(primitive.M); isFilter {
+					prefixedCondition, err := prefixFilterFields(conditionFilter, prefix)
+					if err != nil {
+						return nil, err
+					}
+					condition = prefixedCondition
+				}
+				nested = append(nested, condition)
+			}
+			prefixed[key] = nested
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrWatchFilterUnsupported, key)
+		}
+	}
+	return prefixed, nil
+}
+
+// prefixExprFields prefixes field paths of the aggregation expression, e.g. "$age"
+// becomes "$fullDocument.age". $$ROOT and $$CURRENT point to the change event,
+// so they are replaced by the document, other variables and $literal are kept
+func prefixExprFields(expr any, prefix string) any {
+	switch e := expr.(type) {
+	case string:
+		root := "$" + strings.TrimSuffix(prefix, ".")
+		for _, variable := range []string{"$$ROOT", "$$CURRENT"} {
+			if e == variable {
+				return root
+			}
+			if strings.HasPrefix(e, variable+".") {
+				return root + strings.TrimPrefix(e, variable)
+			}
+		}
+		if strings.HasPrefix(e, "$") && !strings.HasPrefix(e, "$$") {
+			return "$" + prefix + e[1:]
+		}
+		return e
+	case primitive.M:
+		prefixed := make(primitive.M, len(e))
+		for key, value := range e {
+			if key == "$literal" {
+				prefixed[key] = value
+				continue
+			}
+			prefixed[key] = prefixExprFields(value, prefix)
+		}
+		return prefixed
+	case primitive.D:
+		prefixed := make(primitive.D, 0, len(e))
+		for _, elem := range e {
+			if elem.Key != "$literal" {
+				elem.Value = prefixExprFields(elem.Value, prefix)
+			}
+			prefixed = append(prefixed, elem)
+		}
+		return prefixed
+	case primitive.A:
+		prefixed := make(primitive.A, 0, len(e))
+		for _, value := range e {
+			prefixed = append(prefixed, prefixExprFields(value, prefix))
+		}
+		return prefixed
+	}
+	return expr
+}
+
+func isChangeStreamUnsupported(err error) bool {
+	var serverErr mongo.ServerError
+	if errors.As(err, &serverErr) && serverErr.HasErrorCode(changeStreamUnsupportedCode) {
+		return true
+	}
+	return strings.Contains(err.Error(), "only supported on replica sets")
+}
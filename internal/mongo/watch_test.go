@@ -0,0 +1,118 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestParseChangeEvent(t *testing.T) {
+	id := primitive.NewObjectID()
+	raw := primitive.M{
+		"operationType": "update",
+		"documentKey":   primitive.M{"_id": id},
+		"fullDocument":  primitive.M{"_id": id, "status": "done"},
+		"clusterTime":   primitive.Timestamp{T: 1700000000, I: 1},
+	}
+
+	event := ParseChangeEvent(raw)
+
+	assert.Equal(t, OperationUpdate, event.OperationType)
+	assert.Equal(t, id, event.DocumentId)
+	assert.Equal(t, primitive.M{"_id": id, "status": "done"}, event.FullDocument)
+	assert.Equal(t, time.Unix(1700000000, 0), event.Time)
+}
+
+func TestParseChangeEventDelete(t *testing.T) {
+	raw := primitive.M{
+		"operationType": "delete",
+		"documentKey":   primitive.M{"_id": int32(7)},
+	}
+
+	event := ParseChangeEvent(raw)
+
+	assert.Equal(t, OperationDelete, event.OperationType)
+	assert.Equal(t, int32(7), event.DocumentId)
+	assert.Nil(t, event.FullDocument)
+	assert.False(t, event.Time.IsZero())
+}
+
+func TestWatchPipeline(t *testing.T) {
+	pipeline, err := WatchPipeline(primitive.M{}, []any{int32(1)})
+	assert.NoError(t, err)
+	assert.Equal(t, mongo.Pipeline{}, pipeline)
+
+	filter := primitive.M{
+		"status": "active",
+		"$or": primitive.A{
+			primitive.M{"age": primitive.M{"$gt": 18}},
+			primitive.M{"role": "admin"},
+		},
+	}
+
+	expected := mongo.Pipeline{{{Key: "$match", Value: primitive.M{
+		"$or": primitive.A{
+			primitive.M{"operationType": OperationDelete},
+			primitive.M{
+				"fullDocument.status": "active",
+				"$or": primitive.A{
+					primitive.M{"fullDocument.age": primitive.M{"$gt": 18}},
+					primitive.M{"fullDocument.role": "admin"},
+				},
+			},
+		},
+	}}}}
+
+	pipeline, err = WatchPipeline(filter, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, pipeline)
+}
+
+func TestWatchPipelineShownIds(t *testing.T) {
+	pipeline, err := WatchPipeline(primitive.M{"status": "active"}, []any{int32(1), "two"})
+	assert.NoError(t, err)
+
+	// updates of shown documents are passed even when they left the filter
+	assert.Equal(t, mongo.Pipeline{{{Key: "$match", Value: primitive.M{
+		"$or": primitive.A{
+			primitive.M{"operationType": OperationDelete},
+			primitive.M{"fullDocument.status": "active"},
+			primitive.M{
+				"operationType":   primitive.M{"$in": primitive.A{OperationUpdate, OperationReplace}},
+				"documentKey._id": primitive.M{"$in": []any{int32(1), "two"}},
+			},
+		},
+	}}}}, pipeline)
+}
+
+func TestWatchPipelineExpr(t *testing.T) {
+	filter := primitive.M{
+		"$expr": primitive.M{"$and": primitive.A{
+			primitive.M{"$gt": primitive.A{"$spent", "$budget"}},
+			primitive.M{"$eq": primitive.A{primitive.M{"$getField": primitive.M{"field": "a.b", "input": "$$ROOT"}}, "$$CURRENT.limit"}},
+			primitive.M{"$let": primitive.D{{Key: "vars", Value: primitive.M{"total": "$items.total"}}, {Key: "in", Value: "$$total"}}},
+			primitive.M{"$ne": primitive.A{"$status", primitive.M{"$literal": "$status"}}},
+		}},
+	}
+
+	pipeline, err := WatchPipeline(filter, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, primitive.M{"$expr": primitive.M{"$and": primitive.A{
+		primitive.M{"$gt": primitive.A{"$fullDocument.spent", "$fullDocument.budget"}},
+		primitive.M{"$eq": primitive.A{primitive.M{"$getField": primitive.M{"field": "a.b", "input": "$fullDocument"}}, "$fullDocument.limit"}},
+		primitive.M{"$let": primitive.D{{Key: "vars", Value: primitive.M{"total": "$fullDocument.items.total"}}, {Key: "in", Value: "$$total"}}},
+		primitive.M{"$ne": primitive.A{"$fullDocument.status", primitive.M{"$literal": "$status"}}},
+	}}}, pipeline[0][0].Value.(primitive.M)["$or"].(primitive.A)[1])
+}
+
+func TestWatchPipelineUnsupported(t *testing.T) {
+	_, err := WatchPipeline(primitive.M{"$where": "this.a > 1"}, nil)
+	assert.ErrorIs(t, err, ErrWatchFilterUnsupported)
+	assert.ErrorContains(t, err, "$where")
+
+	_, err = WatchPipeline(primitive.M{"$and": primitive.A{primitive.M{"$text": primitive.M{"$search": "coffee"}}}}, nil)
+	assert.ErrorIs(t, err, ErrWatchFilterUnsupported)
+}
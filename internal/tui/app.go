@@ -1,6 +1,8 @@
 package tui
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"strings"
 
@@ -77,6 +79,9 @@ func (a *App) setKeybindings() {
 		}
 
 		switch {
+		// cancelling takes precedence, so the app is closed only when no query is running
+		case a.GetKeys().Contains(a.GetKeys().Global.CancelQuery, event.Name()) && a.main.App != nil && a.main.CancelQuery():
+			return nil
 		case a.GetKeys().Contains(a.GetKeys().Global.CloseApp, event.Name()):
 			a.Stop()
 			return nil
@@ -205,6 +210,7 @@ func (a *App) showChangelogModal(entries []modal.ChangelogEntry) {
 // initAndRenderMain initializes and renders the main page
 // methods are combined as we need to establish connection first
 func (a *App) initAndRenderMain() {
+	prevDao := a.GetDao()
 	if err := a.connectToMongo(); err != nil {
 		a.renderConnection()
 		if _, ok := err.(*util.EncryptionError); ok {
@@ -228,6 +234,10 @@ func (a *App) initAndRenderMain() {
 	a.main.Render()
 	a.Pages.AddPage(a.main.GetIdentifier(), a.main, true, true)
 
+	if prevDao != nil && prevDao != a.GetDao() {
+		a.abortTransaction(prevDao)
+	}
+
 	if jumpInto := a.GetConfig().JumpInto; jumpInto != "" {
 		if err := a.jumpToCollection(jumpInto); err != nil {
 			modal.ShowError(a.Pages, "Unable to jump into the db/collection", err)
@@ -235,6 +245,20 @@ func (a *App) initAndRenderMain() {
 	}
 }
 
+// abortTransaction rolls back the transaction left on the connection that was switched from,
+// otherwise its session would stay open until the server expires it
+func (a *App) abortTransaction(dao *mongo.Dao) {
+	if dao.ActiveTransaction() == nil {
+		return
+	}
+	pending, err := dao.AbortTransaction(context.Background())
+	if err != nil {
+		modal.ShowError(a.Pages, "Error aborting transaction of the previous connection", err)
+		return
+	}
+	modal.ShowInfo(a.Pages, fmt.Sprintf("Connection changed, the transaction was aborted and its %d staged writes were rolled back", len(pending)))
+}
+
 // renderConnection renders the connection page
 func (a *App) renderConnection() {
 	a.connection.SetOnSubmitFunc(func() {
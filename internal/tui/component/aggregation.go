@@ -3,6 +3,7 @@ package component
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
@@ -20,6 +21,7 @@ import (
 	"github.com/kopecmaciej/vi-mongo/internal/util"
 	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 const (
@@ -41,6 +43,7 @@ type Aggregation struct {
 	resultsHeader *core.TextView
 	resultsTable  *core.Table
 	deleteModal   *modal.Confirm
+	explainModal  *modal.ExplainModal
 	peeker        *Peeker
 	tableJson     *widget.TableJson
 	tableColumns  *widget.TableColumns
@@ -54,6 +57,9 @@ type Aggregation struct {
 	focusOnResults bool
 	isPreview      bool
 	currentView    ViewType
+
+	// queries runs the pipeline in the background, so slow pipelines can be cancelled
+	queries *queryRunner
 }
 
 func NewAggregation() *Aggregation {
@@ -67,12 +73,14 @@ func NewAggregation() *Aggregation {
 		resultsHeader: core.NewTextView(),
 		resultsTable:  core.NewTable(),
 		deleteModal:   modal.NewConfirm(AggregationDeleteModalId),
+		explainModal:  modal.NewExplainModal(),
 		peeker:        NewPeeker(),
 		tableJson:     widget.NewTableJson(),
 		state:         &mongo.CollectionState{},
 		stateMap:      mongo.NewStateMap(),
 		editingIdx:    -1,
 		currentView:   TableView,
+		queries:       &queryRunner{},
 	}
 
 	a.SetIdentifier(AggregationId)
@@ -82,6 +90,7 @@ func NewAggregation() *Aggregation {
 }
 
 func (a *Aggregation) init() error {
+	a.queries.app = a.App
 	a.setLayout()
 	a.setStyle()
 	a.setKeybindings()
@@ -95,6 +104,9 @@ func (a *Aggregation) init() error {
 	if err := a.peeker.Init(a.App); err != nil {
 		return err
 	}
+	if err := a.explainModal.Init(a.App); err != nil {
+		return err
+	}
 
 	a.stageBar.EnableAggregationAutocomplete()
 	a.stageBar.EnableHistory()
@@ -156,6 +168,16 @@ func (a *Aggregation) handleEvents() {
 			if keys, ok := event.Message.Data.([]string); ok {
 				a.stageBar.LoadAutocomleteKeys(keys)
 			}
+		case manager.RunPipeline:
+			stages, ok := event.Message.Data.([]string)
+			if !ok {
+				return
+			}
+			go a.App.QueueUpdateDraw(func() {
+				a.state.SetPipelineStages(stages)
+				a.renderLayout()
+				a.runPipeline(context.Background(), false)
+			})
 		}
 	})
 }
@@ -197,6 +219,9 @@ func (a *Aggregation) setKeybindings() {
 		case k.Contains(k.Aggregation.Stages.EditPipelineInEditor, event.Name()):
 			a.handleEditPipelineInEditor()
 			return nil
+		case k.Contains(k.Aggregation.Stages.ExplainPipeline, event.Name()):
+			a.explainPipeline(context.Background())
+			return nil
 		}
 		return event
 	})
@@ -230,6 +255,7 @@ func (a *Aggregation) setKeybindings() {
 }
 
 func (a *Aggregation) HandleDatabaseSelection(ctx context.Context, db, coll string) error {
+	a.queries.Cancel()
 	a.currentDB = db
 	a.currentColl = coll
 
@@ -285,14 +311,7 @@ func (a *Aggregation) renderStagesTable() {
 	a.stagesTable.SetFixed(1, 0)
 
 	headers := []string{"#", "Operator", "Preview"}
-	for col, header := range headers {
-		cell := tview.NewTableCell(" " + header + " ").
-			SetSelectable(false).
-			SetAlign(tview.AlignCenter).
-			SetTextColor(styles.Content.ColumnKeyColor.Color()).
-			SetBackgroundColor(styles.Content.HeaderRowBackgroundColor.Color())
-		a.stagesTable.SetCell(0, col, cell)
-	}
+	a.stagesTable.SetHeaderRow(headers, styles)
 
 	stages := a.state.GetPipelineStages()
 	for row, stage := range stages {
@@ -330,6 +349,9 @@ func (a *Aggregation) renderResultsView() {
 	resultsHeaderFlex.SetTitle(title)
 	resultsHeaderFlex.SetTitleAlign(tview.AlignLeft)
 
+	a.resultsHeader.SetText(a.queries.Status())
+	resultsHeaderFlex.AddItem(a.resultsHeader, 1, 0, false)
+
 	a.resultsTable.SetSelectable(true, a.currentView == TableView)
 	switch a.currentView {
 	case TableView:
@@ -692,6 +714,28 @@ func (a *Aggregation) handleEditPipelineInEditor() {
 	}
 }
 
+func (a *Aggregation) explainPipeline(ctx context.Context) {
+	stages := a.state.GetPipelineStages()
+	if len(stages) == 0 {
+		modal.ShowError(a.App.Pages, "No stages", fmt.Errorf("add at least one stage before explaining"))
+		return
+	}
+
+	pipeline, err := mongo.ParsePipeline(stages)
+	if err != nil {
+		modal.ShowError(a.App.Pages, "Pipeline parse error", err)
+		return
+	}
+
+	plan, err := a.Dao.ExplainAggregate(ctx, a.currentDB, a.currentColl, pipeline)
+	if err != nil {
+		modal.ShowError(a.App.Pages, "Error explaining pipeline", err)
+		return
+	}
+
+	a.explainModal.Render("pipeline", plan)
+}
+
 func (a *Aggregation) runPipeline(ctx context.Context, preview bool) {
 	stages := a.state.GetPipelineStages()
 	if len(stages) == 0 {
@@ -709,13 +753,39 @@ func (a *Aggregation) runPipeline(ctx context.Context, preview bool) {
 		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: 5}})
 	}
 
-	docs, err := a.Dao.AggregateDocuments(ctx, a.currentDB, a.currentColl, pipeline)
-	if err != nil {
-		modal.ShowError(a.App.Pages, "Aggregation error", err)
-		return
+	db, coll, state := a.currentDB, a.currentColl, a.state
+	var docs []primitive.M
+	query := func(ctx context.Context) error {
+		results, err := a.Dao.AggregateDocuments(ctx, db, coll, pipeline)
+		docs = results
+		return err
+	}
+	onTick := func() {
+		a.resultsHeader.SetText(a.queries.Status())
 	}
+	done := func(err error) {
+		if err != nil {
+			a.resultsHeader.SetText(a.queries.Status())
+			if !errors.Is(err, context.Canceled) {
+				modal.ShowError(a.App.Pages, "Aggregation error", err)
+			}
+			return
+		}
 
-	a.isPreview = preview
-	a.state.SetAggDocs(docs)
-	a.Render()
+		a.isPreview = preview
+		state.SetAggDocs(docs)
+		// the pipeline may finish while another view is focused
+		hasFocus := a.HasFocus()
+		a.renderLayout()
+		if hasFocus {
+			a.restoreFocus()
+		}
+	}
+
+	a.queries.run(ctx, query, onTick, done)
+}
+
+// CancelQuery cancels the running pipeline, it returns false when no pipeline is running
+func (a *Aggregation) CancelQuery() bool {
+	return a.queries.Cancel()
 }
@@ -2,8 +2,11 @@ package component
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/atotto/clipboard"
 	"github.com/gdamore/tcell/v2"
@@ -15,6 +18,7 @@ import (
 	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/widget"
 	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"github.com/rs/zerolog/log"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -33,6 +37,14 @@ const (
 	JsonView
 )
 
+const (
+	// updatePreviewSize is the number of documents shown in the update preview
+	updatePreviewSize = 3
+	// largeDeleteThreshold is the number of documents from which deleting
+	// requires typing the collection name or the number of documents
+	largeDeleteThreshold = 100
+)
+
 // Content is a view that displays documents in a table
 type Content struct {
 	*core.BaseElement
@@ -48,6 +60,10 @@ type Content struct {
 	confirmModal      *modal.Confirm
 	queryOptionsModal *modal.QueryOptionsModal
 	inlineEditModal   *modal.InlineEditModal
+	exportModal       *modal.ExportModal
+	updateManyModal   *modal.UpdateManyModal
+	deleteManyModal   *modal.DeleteManyModal
+	explainModal      *modal.ExplainModal
 	docModifier       *DocModifier
 	state             *mongo.CollectionState
 	stateMap          *mongo.StateMap
@@ -55,6 +71,19 @@ type Content struct {
 	currentView  ViewType
 	tableColumns *widget.TableColumns
 	tableJson    *widget.TableJson
+	// schemaKeys are field paths found by sampling the collection in the Schema view
+	schemaKeys []string
+
+	// liveCancel stops the change stream, it's set only while live mode is on
+	liveCancel context.CancelFunc
+	// liveChanges are the last changes of documents received in live mode by their _id
+	liveChanges map[string]mongo.ChangeEvent
+	lastChange  *mongo.ChangeEvent
+
+	// queries loads documents in the background, so slow queries can be cancelled
+	queries *queryRunner
+	// jumpFunc opens another collection, it's used by mongosh commands
+	jumpFunc func(db, coll string) error
 }
 
 func NewContent() *Content {
@@ -71,10 +100,15 @@ func NewContent() *Content {
 		confirmModal:      modal.NewConfirm(ContentDeleteModalId),
 		queryOptionsModal: modal.NewQueryOptionsModal(),
 		inlineEditModal:   modal.NewInlineEditModal(),
+		exportModal:       modal.NewExportModal(),
+		updateManyModal:   modal.NewUpdateManyModal(),
+		deleteManyModal:   modal.NewDeleteManyModal(),
+		explainModal:      modal.NewExplainModal(),
 		docModifier:       NewDocModifier(),
 		state:             &mongo.CollectionState{},
 		stateMap:          mongo.NewStateMap(),
 		currentView:       TableView,
+		queries:           &queryRunner{},
 
 		tableJson: widget.NewTableJson(),
 	}
@@ -90,6 +124,7 @@ func NewContent() *Content {
 
 func (c *Content) init() error {
 	ctx := context.Background()
+	c.queries.app = c.App
 
 	c.setLayout()
 	c.setStyle()
@@ -110,6 +145,18 @@ func (c *Content) init() error {
 	if err := c.inlineEditModal.Init(c.App); err != nil {
 		return err
 	}
+	if err := c.exportModal.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.updateManyModal.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.deleteManyModal.Init(c.App); err != nil {
+		return err
+	}
+	if err := c.explainModal.Init(c.App); err != nil {
+		return err
+	}
 	if err := c.queryBar.Init(c.App); err != nil {
 		return err
 	}
@@ -163,11 +210,22 @@ func (c *Content) handleEvents(ctx context.Context) {
 				c.applyQuery(ctx, query)
 				c.App.SetFocus(c)
 			})
+		case manager.UpdateAutocompleteKeys:
+			keys, ok := event.Message.Data.([]string)
+			if !ok || event.Sender != SchemaId || c.state == nil {
+				return
+			}
+			go c.App.QueueUpdateDraw(func() {
+				c.schemaKeys = keys
+				c.loadAutocompleteKeys(c.state.GetAllDocs())
+			})
 		}
 	})
 }
 
 func (c *Content) UpdateDao(dao *mongo.Dao) {
+	c.stopLiveMode()
+	c.queries.Cancel()
 	c.table.Clear()
 	c.BaseElement.UpdateDao(dao)
 	c.docModifier.UpdateDao(dao)
@@ -264,6 +322,10 @@ func (c *Content) setKeybindings(ctx context.Context) {
 			return c.handlePreviousDocument(row, col)
 		case k.Contains(k.Content.PreviousPage, event.Name()):
 			return c.handlePreviousPage(ctx)
+		case k.Contains(k.Content.FirstPage, event.Name()):
+			return c.handleFirstPage(ctx)
+		case k.Contains(k.Content.LastPage, event.Name()):
+			return c.handleLastPage(ctx)
 		case k.Contains(k.Content.ToggleQueryOptions, event.Name()):
 			return c.handleShowQueryOptions(ctx)
 		case k.Contains(k.Content.MultipleSelect, event.Name()):
@@ -274,6 +336,22 @@ func (c *Content) setKeybindings(ctx context.Context) {
 			return c.handleCopyLine(row, col)
 		case k.Contains(k.Content.CopyDocument, event.Name()):
 			return c.handleCopyDocument(row, col)
+		case k.Contains(k.Content.ExportDocuments, event.Name()):
+			return c.handleExportDocuments()
+		case k.Contains(k.Content.UpdateMany, event.Name()):
+			return c.handleUpdateMany(ctx, "")
+		case k.Contains(k.Content.DeleteMany, event.Name()):
+			return c.handleDeleteMany(ctx, confirm)
+		case k.Contains(k.Content.ExplainQuery, event.Name()):
+			return c.handleExplainQuery(ctx)
+		case k.Contains(k.Content.ToggleLiveMode, event.Name()):
+			return c.handleToggleLiveMode(ctx)
+		case k.Contains(k.Content.StartTransaction, event.Name()):
+			return c.handleStartTransaction()
+		case k.Contains(k.Content.CommitTransaction, event.Name()):
+			return c.handleFinishTransaction(ctx, true)
+		case k.Contains(k.Content.AbortTransaction, event.Name()):
+			return c.handleFinishTransaction(ctx, false)
 		}
 
 		return event
@@ -284,6 +362,8 @@ func (c *Content) setKeybindings(ctx context.Context) {
 func (c *Content) HandleDatabaseSelection(ctx context.Context, db, coll string) error {
 	c.queryBar.SetText("")
 	c.sortBar.SetText("")
+	c.schemaKeys = nil
+	c.stopLiveMode()
 
 	state, ok := c.stateMap.Get(c.stateMap.Key(db, coll))
 	if ok {
@@ -297,6 +377,9 @@ func (c *Content) HandleDatabaseSelection(ctx context.Context, db, coll string)
 			_, _, _, height := c.table.GetInnerRect()
 			c.state.Limit = int64(height - 1)
 		}
+		if c.Dao.Config.Options.Pagination == string(mongo.PaginationKeyset) {
+			c.state.SetPagination(mongo.PaginationKeyset)
+		}
 	}
 
 	err := c.updateContent(ctx, false)
@@ -333,44 +416,94 @@ func (c *Content) Render() {
 	c.App.SetFocus(focusPrimitive)
 }
 
-func (c *Content) listDocuments(ctx context.Context) ([]primitive.M, error) {
-	filter, err := mongo.ParseStringQuery(c.state.Filter)
+// parseQuery parses filter, sort and projection stored in the current state
+func (c *Content) parseQuery() (filter primitive.M, sort primitive.D, projection primitive.M, err error) {
+	filter, err = mongo.ParseStringQuery(c.state.Filter)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
-	sort, err := mongo.ParseSortOptions(c.state.Sort)
+	// the order of sort keys is kept, it matters for keyset pagination and suggested indexes
+	sort, err = mongo.ParseSortKeys(c.state.Sort)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
-	var projection primitive.M
 	if c.state.Projection != "" {
 		projection, err = mongo.ParseStringQuery(c.state.Projection)
 		if err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 	}
 
-	countCallback := func(count int64) {
-		c.state.Count = count
+	return filter, sort, projection, nil
+}
+
+// listDocuments fetches documents of the current page in the background, the query
+// is parsed right away so its errors are returned, errors of the query itself are shown
+// once it finishes and onFail is called when it fails or is cancelled
+func (c *Content) listDocuments(ctx context.Context, onFail func()) error {
+	filter, sort, projection, err := c.parseQuery()
+	if err != nil {
+		return err
+	}
+
+	state := c.state
+	// the page query is built here, as the state can change on the UI goroutine while documents load
+	db, coll := state.Db, state.Coll
+	page := state.PageQuery(filter, sort)
+	// the count is reported from its own goroutine, so the state is updated on the UI goroutine
+	countCallback := func(count int64, kind mongo.CountKind) {
 		c.App.QueueUpdateDraw(func() {
+			state.Count = count
+			state.CountKind = kind
 			c.tableHeader.SetText(c.buildHeaderInfo())
 		})
 	}
 
-	documents, err := c.Dao.ListDocuments(ctx, c.state, filter, sort, projection, countCallback)
-	if err != nil {
-		return nil, err
+	var documents []primitive.M
+	query := func(ctx context.Context) error {
+		docs, err := c.Dao.ListDocuments(ctx, db, coll, filter, sort, page, projection, countCallback)
+		documents = docs
+		return err
 	}
-	if len(documents) == 0 {
-		return nil, nil
+	onTick := func() {
+		c.tableHeader.SetText(c.buildHeaderInfo())
+	}
+	done := func(err error) {
+		if err != nil {
+			if onFail != nil {
+				onFail()
+			}
+			c.tableHeader.SetText(c.buildHeaderInfo())
+			if !errors.Is(err, context.Canceled) {
+				modal.ShowError(c.App.Pages, "Error loading documents", err)
+			}
+			return
+		}
+		// an empty page is kept too, without the exact count it tells that there are no more pages
+		state.PopulateDocs(documents)
+		if len(documents) > 0 {
+			c.loadAutocompleteKeys(documents)
+		}
+		c.renderDocuments(documents)
+		if c.liveCancel != nil {
+			// watch again, so only changes matching the filter and changes
+			// of the documents of the loaded page are received
+			c.stopLiveMode()
+			if err := c.startLiveMode(ctx); err != nil {
+				c.tableHeader.SetText(c.buildHeaderInfo())
+				modal.ShowError(c.App.Pages, "Live mode stopped", err)
+			}
+		}
 	}
 
-	c.state.PopulateDocs(documents)
-
-	c.loadAutocompleteKeys(documents)
+	c.queries.run(ctx, query, onTick, done)
+	return nil
+}
 
-	return documents, nil
+// CancelQuery cancels loading of documents, it returns false when no query is running
+func (c *Content) CancelQuery() bool {
+	return c.queries.Cancel()
 }
 
 // loadAutocompleteKeys loads the autocomplete keys for the query and sort bars
@@ -398,6 +531,9 @@ func (c *Content) loadAutocompleteKeys(documents []primitive.M) {
 			addKeys(key, value)
 		}
 	}
+	for _, key := range c.schemaKeys {
+		uniqueKeys[key] = true
+	}
 
 	autocompleteKeys := make([]string, 0, len(uniqueKeys))
 	for key := range uniqueKeys {
@@ -412,32 +548,29 @@ func (c *Content) loadAutocompleteKeys(documents []primitive.M) {
 	})
 }
 
-// TODO: maybe show error modal here?
+// updateContent renders the documents, with useState the already loaded ones,
+// otherwise the documents are fetched in the background and rendered when the query finishes
 func (c *Content) updateContent(ctx context.Context, useState bool) error {
-	c.table.ClearSelection()
-	var documents []primitive.M
-
-	if useState {
-		documents = c.state.GetAllDocs()
-	} else {
-		docs, err := c.listDocuments(ctx)
-		if err != nil {
-			return err
-		}
-		documents = docs
+	if !useState {
+		return c.listDocuments(ctx, nil)
 	}
 
+	c.renderDocuments(c.state.GetAllDocs())
+	return nil
+}
+
+func (c *Content) renderDocuments(documents []primitive.M) {
+	c.table.ClearSelection()
 	c.table.Clear()
 	c.tableHeader.SetText(c.buildHeaderInfo())
 	c.stateMap.Set(c.stateMap.Key(c.state.Db, c.state.Coll), c.state)
 
 	if len(documents) == 0 {
 		c.table.SetCell(0, 0, tview.NewTableCell("No documents found"))
-		return nil
+		return
 	}
 
 	c.renderView(documents)
-	return nil
 }
 
 func (c *Content) renderView(documents []primitive.M) {
@@ -451,11 +584,52 @@ func (c *Content) renderView(documents []primitive.M) {
 			modal.ShowError(c.App.Pages, "Error rendering JSON view", err)
 		}
 	}
+	c.highlightChangedRows()
+}
+
+// highlightChangedRows marks rows of documents changed while in live mode
+// or written in the active transaction, in JsonView all rows of the document are marked
+func (c *Content) highlightChangedRows() {
+	changedIds := make(map[string]bool, len(c.liveChanges))
+	for id := range c.liveChanges {
+		changedIds[id] = true
+	}
+	if tx := c.Dao.ActiveTransaction(); tx != nil {
+		for _, op := range tx.Pending() {
+			if op.DocumentId != nil {
+				changedIds[mongo.StringifyId(op.DocumentId)] = true
+			}
+		}
+	}
+	if len(changedIds) == 0 {
+		return
+	}
+
+	var changed bool
+	for row := 0; row < c.table.GetRowCount(); row++ {
+		if cell := c.table.GetCell(row, 0); cell != nil && cell.GetReference() != nil {
+			changed = changedIds[mongo.StringifyId(cell.GetReference())]
+		}
+		if !changed {
+			continue
+		}
+		for col := 0; col < c.table.GetColumnCount(); col++ {
+			if cell := c.table.GetCell(row, col); cell != nil {
+				cell.SetBackgroundColor(c.style.ChangedRowColor.Color())
+			}
+		}
+	}
 }
 
 func (c *Content) buildHeaderInfo() string {
-	headerInfo := fmt.Sprintf("Documents: %d, Page: %d/%d (%d), Limit: %d",
-		c.state.Count, c.state.GetCurrentPage(), c.state.GetTotalPages(), c.state.Skip, c.state.Limit)
+	headerInfo := fmt.Sprintf("Documents: %s, Page: %s (%d), Limit: %d",
+		c.state.FormatCount(), c.state.FormatPage(), c.state.Skip, c.state.Limit)
+	if c.state.Pagination == mongo.PaginationKeyset {
+		headerInfo += ", Keyset"
+	}
+	if status := c.queries.Status(); status != "" {
+		headerInfo += fmt.Sprintf(" | %s", status)
+	}
 
 	if c.state.Filter != "" {
 		headerInfo += fmt.Sprintf(" | Filter: %s", c.state.Filter)
@@ -466,13 +640,24 @@ func (c *Content) buildHeaderInfo() string {
 	if c.state.Projection != "" {
 		headerInfo += fmt.Sprintf(" | Projection: %s", c.state.Projection)
 	}
+	if tx := c.Dao.ActiveTransaction(); tx != nil {
+		headerInfo += fmt.Sprintf(" | Transaction: %d pending, %s", len(tx.Pending()),
+			time.Since(tx.Started()).Round(time.Second))
+	}
+	if c.liveCancel != nil {
+		headerInfo += " | Live"
+		if c.lastChange != nil {
+			headerInfo += fmt.Sprintf("\nLast change: %s of %s at %s", c.lastChange.OperationType,
+				mongo.StringifyId(c.lastChange.DocumentId), c.lastChange.Time.Format(time.TimeOnly))
+		}
+	}
 
 	return headerInfo
 }
 
 func (c *Content) applyStateChange(ctx context.Context, setValue func(), clearValue func()) error {
 	setValue()
-	if err := c.updateContent(ctx, false); err != nil {
+	if err := c.listDocuments(ctx, clearValue); err != nil {
 		clearValue()
 		return err
 	}
@@ -481,7 +666,23 @@ func (c *Content) applyStateChange(ctx context.Context, setValue func(), clearVa
 }
 
 func (c *Content) applyQuery(ctx context.Context, query string) error {
-	if err := c.applyStateChange(ctx, func() { c.state.SetFilter(query) }, func() { c.state.SetFilter("") }); err != nil {
+	var err error
+	if mongo.IsShellQuery(query) {
+		err = c.applyShellQuery(ctx, query)
+	} else {
+		resultLimit := c.state.ResultLimit
+		setValue := func() {
+			c.state.SetFilter(query)
+			// result limit belongs to the mongosh command the filter replaces
+			c.state.ResultLimit = 0
+		}
+		clearValue := func() {
+			c.state.SetFilter("")
+			c.state.ResultLimit = resultLimit
+		}
+		err = c.applyStateChange(ctx, setValue, clearValue)
+	}
+	if err != nil {
 		return err
 	}
 	if query != "" && strings.ReplaceAll(query, " ", "") != "{}" {
@@ -490,6 +691,64 @@ func (c *Content) applyQuery(ctx context.Context, query string) error {
 	return nil
 }
 
+// applyShellQuery applies find or aggregate command in the mongosh syntax, the collection
+// of the command is opened first if it's not the current one. Find is translated into
+// the filter, projection, sort and skip, its limit caps the shown documents without
+// changing the page size, aggregate is run in the Aggregation view
+func (c *Content) applyShellQuery(ctx context.Context, query string) error {
+	shellQuery, err := mongo.ParseShellQuery(query)
+	if err != nil {
+		return err
+	}
+
+	if shellQuery.Collection != c.state.Coll {
+		if c.jumpFunc == nil {
+			return fmt.Errorf("can't open collection %s", shellQuery.Collection)
+		}
+		if err := c.jumpFunc(c.state.Db, shellQuery.Collection); err != nil {
+			return err
+		}
+	}
+
+	if shellQuery.IsAggregation() {
+		c.BroadcastEvent(manager.EventMsg{
+			Sender:  c.GetIdentifier(),
+			Message: manager.Message{Type: manager.RunPipeline, Data: shellQuery.Pipeline},
+		})
+		return nil
+	}
+
+	filter, sort, projection := c.state.Filter, c.state.Sort, c.state.Projection
+	resultLimit, skip := c.state.ResultLimit, c.state.Skip
+	setValue := func() {
+		c.state.SetFilter(shellQuery.Filter)
+		c.state.SetSort(shellQuery.Sort)
+		c.state.Projection = shellQuery.Projection
+		c.state.SetSkip(0)
+		if shellQuery.Skip != nil {
+			c.state.SetSkip(*shellQuery.Skip)
+		}
+		// limit(0) means no limit like in mongosh
+		c.state.ResultLimit = 0
+		if shellQuery.Limit != nil && *shellQuery.Limit > 0 {
+			c.state.ResultLimit = c.state.Skip + *shellQuery.Limit
+		}
+	}
+	clearValue := func() {
+		c.state.SetFilter(filter)
+		c.state.SetSort(sort)
+		c.state.Projection = projection
+		c.state.ResultLimit = resultLimit
+		c.state.SetSkip(skip)
+	}
+	return c.applyStateChange(ctx, setValue, clearValue)
+}
+
+// SetJumpFunc sets the function opening another collection
+func (c *Content) SetJumpFunc(jumpFunc func(db, coll string) error) {
+	c.jumpFunc = jumpFunc
+}
+
 func (c *Content) applySort(ctx context.Context, sort string) error {
 	return c.applyStateChange(ctx, func() { c.state.SetSort(sort) }, func() { c.state.SetSort("") })
 }
@@ -596,7 +855,10 @@ func (c *Content) handleFullPagePeek(ctx context.Context, row, col int) *tcell.E
 	return nil
 }
 
-func (c *Content) appendNewDocument(ctx context.Context, id primitive.ObjectID, row, col int) error {
+func (c *Content) appendNewDocument(ctx context.Context, id any, row, col int) error {
+	if id == nil {
+		return nil
+	}
 	doc, err := c.Dao.GetDocument(ctx, c.state.Db, c.state.Coll, id)
 	if err != nil {
 		return err
@@ -610,11 +872,11 @@ func (c *Content) appendNewDocument(ctx context.Context, id primitive.ObjectID,
 func (c *Content) handleAddDocument(ctx context.Context) *tcell.EventKey {
 	id, err := c.docModifier.Insert(ctx, c.state.Db, c.state.Coll)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error adding document", err)
+		c.showDocumentError("Error adding document", err)
 		return nil
 	}
 	if err := c.appendNewDocument(ctx, id, 1, 0); err != nil {
-		modal.ShowError(c.App.Pages, "Error getting inserted document", err)
+		c.showDocumentError("Error getting inserted document", err)
 	}
 	return nil
 }
@@ -628,7 +890,7 @@ func (c *Content) handleEditDocument(ctx context.Context, row, col int) *tcell.E
 	}
 	updated, err := c.docModifier.Edit(ctx, c.state.Db, c.state.Coll, _id, doc)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error editing document", err)
+		c.showDocumentError("Error editing document", err)
 		return nil
 	}
 
@@ -639,19 +901,14 @@ func (c *Content) handleEditDocument(ctx context.Context, row, col int) *tcell.E
 }
 
 func (c *Content) handleDuplicateDocument(ctx context.Context, row, col int) *tcell.EventKey {
-	doc, err := c.getDocumentBasedOnView(row, col)
+	_id := c.getDocumentId(row, col)
+	doc, err := c.state.GetJsonDocById(_id)
 	if err != nil {
 		modal.ShowError(c.App.Pages, "Error getting document", err)
 		return nil
 	}
 
-	objectId, err := mongo.GetIDFromJSON(doc)
-	if err != nil {
-		modal.ShowError(c.App.Pages, "Error extracting document ID", err)
-		return nil
-	}
-
-	stringifyId := mongo.StringifyId(objectId)
+	stringifyId := tview.Escape(mongo.StringifyId(_id))
 
 	c.confirmModal.SetConfirmButtonLabel("Duplicate")
 	c.confirmModal.SetText("Are you sure you want to duplicate document with ID: [blue]" + stringifyId)
@@ -663,11 +920,11 @@ func (c *Content) handleDuplicateDocument(ctx context.Context, row, col int) *tc
 		if buttonLabel == "Duplicate" {
 			id, err := c.docModifier.Duplicate(ctx, c.state.Db, c.state.Coll, doc)
 			if err != nil {
-				modal.ShowError(c.App.Pages, "Error duplicating document", err)
+				c.showDocumentError("Error duplicating document", err)
 				return
 			}
 			if err := c.appendNewDocument(ctx, id, row, col); err != nil {
-				modal.ShowError(c.App.Pages, "Error getting inserted document", err)
+				c.showDocumentError("Error getting inserted document", err)
 			}
 		}
 	})
@@ -680,16 +937,16 @@ func (c *Content) handleDuplicateDocument(ctx context.Context, row, col int) *tc
 func (c *Content) handleDuplicateDocumentNoConfirm(ctx context.Context, row, col int) *tcell.EventKey {
 	doc, err := c.getDocumentBasedOnView(row, col)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error duplicating document", err)
+		c.showDocumentError("Error duplicating document", err)
 		return nil
 	}
 	id, err := c.docModifier.DuplicateNoEditor(ctx, c.state.Db, c.state.Coll, doc)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error duplicating document", err)
+		c.showDocumentError("Error duplicating document", err)
 		return nil
 	}
 	if err := c.appendNewDocument(ctx, id, row, col); err != nil {
-		modal.ShowError(c.App.Pages, "Error getting inserted document", err)
+		c.showDocumentError("Error getting inserted document", err)
 	}
 	return nil
 }
@@ -700,31 +957,22 @@ func (c *Content) handleDeleteDocument(ctx context.Context, row, col int) *tcell
 	msg := "Are you sure you want to delete [blue]"
 	if len(sRows) > 0 {
 		for _, sRow := range sRows {
-			doc, err := c.getDocumentBasedOnView(sRow, col)
-			if err != nil {
-				modal.ShowError(c.App.Pages, "Error getting document by row and col", err)
+			_id := c.getDocumentId(sRow, col)
+			if _id == nil {
+				modal.ShowError(c.App.Pages, "Error getting document by row and col", fmt.Errorf("no document in row %d", sRow))
 				return nil
 			}
-			objectId, err := mongo.GetIDFromJSON(doc)
-			if err != nil {
-				modal.ShowError(c.App.Pages, "Error parsing document", err)
-			}
-			idsToDelete = append(idsToDelete, objectId)
+			idsToDelete = append(idsToDelete, _id)
 		}
 		c.confirmModal.SetText(fmt.Sprintf("%s%d[-] documents?", msg, len(idsToDelete)))
 
 	} else {
-		doc, err := c.getDocumentBasedOnView(row, col)
-		if err != nil {
-			modal.ShowError(c.App.Pages, "Error getting document by row and col", err)
+		_id := c.getDocumentId(row, col)
+		if _id == nil {
+			modal.ShowError(c.App.Pages, "Error getting document by row and col", fmt.Errorf("no document in row %d", row))
 			return nil
 		}
-		objectId, err := mongo.GetIDFromJSON(doc)
-		if err != nil {
-			modal.ShowError(c.App.Pages, "Error parsing document", err)
-			return nil
-		}
-		idsToDelete = append(idsToDelete, objectId)
+		idsToDelete = append(idsToDelete, _id)
 		c.confirmModal.SetText(fmt.Sprintf("%s1[-] document?", msg))
 	}
 
@@ -738,7 +986,7 @@ func (c *Content) handleDeleteDocument(ctx context.Context, row, col int) *tcell
 			for _, toDelete := range idsToDelete {
 				err := c.Dao.DeleteDocument(ctx, c.state.Db, c.state.Coll, toDelete)
 				if err != nil {
-					modal.ShowError(c.App.Pages, "Error deleting document", err)
+					c.showDocumentError("Error deleting document", err)
 					return
 				}
 				c.state.DeleteDoc(toDelete)
@@ -768,7 +1016,7 @@ func (c *Content) handleDeleteDocumentNoConfirm(ctx context.Context, row, col in
 
 	err := c.Dao.DeleteDocument(ctx, c.state.Db, c.state.Coll, _id)
 	if err != nil {
-		modal.ShowError(c.App.Pages, "Error deleting document", err)
+		c.showDocumentError("Error deleting document", err)
 		return nil
 	}
 
@@ -834,20 +1082,45 @@ func (c *Content) handlePreviousDocument(row, col int) *tcell.EventKey {
 }
 
 func (c *Content) handleNextPage(ctx context.Context) *tcell.EventKey {
-	if c.state.Skip+c.state.Limit >= c.state.Count {
+	if !c.state.HasNextPage() {
 		return nil
 	}
-	c.state.SetSkip(c.state.Skip + c.state.Limit)
-	c.stateMap.Set(c.stateMap.Key(c.state.Db, c.state.Coll), c.state)
-	c.updateContent(ctx, false)
-	return nil
+	return c.changePage(ctx, c.state.NextPage)
 }
 
 func (c *Content) handlePreviousPage(ctx context.Context) *tcell.EventKey {
 	if c.state.Skip == 0 {
 		return nil
 	}
-	c.state.SetSkip(c.state.Skip - c.state.Limit)
+	return c.changePage(ctx, c.state.PreviousPage)
+}
+
+func (c *Content) handleFirstPage(ctx context.Context) *tcell.EventKey {
+	if c.state.Skip == 0 {
+		return nil
+	}
+	return c.changePage(ctx, func(primitive.D) { c.state.FirstPage() })
+}
+
+func (c *Content) handleLastPage(ctx context.Context) *tcell.EventKey {
+	if !c.state.HasLastPage() {
+		return nil
+	}
+	return c.changePage(ctx, c.state.LastPage)
+}
+
+// changePage moves the state to another page using the current sort and loads it
+func (c *Content) changePage(ctx context.Context, move func(sort primitive.D)) *tcell.EventKey {
+	// pages are positioned by the loaded documents, so the next move waits for them
+	if c.queries.IsRunning() {
+		return nil
+	}
+	sort, err := mongo.ParseSortKeys(c.state.Sort)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error parsing sort", err)
+		return nil
+	}
+	move(sort)
 	c.stateMap.Set(c.stateMap.Key(c.state.Db, c.state.Coll), c.state)
 	c.updateContent(ctx, false)
 	return nil
@@ -915,6 +1188,284 @@ func (c *Content) handleCopyDocument(row, col int) *tcell.EventKey {
 	return nil
 }
 
+// handleExportDocuments exports all documents matching current filter, sort and projection,
+// not only the ones that are loaded on the current page
+func (c *Content) handleExportDocuments() *tcell.EventKey {
+	if c.state.Db == "" || c.state.Coll == "" {
+		return nil
+	}
+
+	filter, sort, projection, err := c.parseQuery()
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error parsing query", err)
+		return nil
+	}
+	db, coll := c.state.Db, c.state.Coll
+
+	c.exportModal.SetExportFunc(func(ctx context.Context, format mongo.FileFormat, path string, progress func(written, total int64)) (int64, error) {
+		// existing files are never overwritten, so only the file created here is removed on error
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return 0, err
+		}
+
+		written, err := c.Dao.ExportDocuments(ctx, db, coll, filter, sort, projection, format, file, progress)
+		if closeErr := file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			// don't leave partially written file behind
+			if removeErr := os.Remove(path); removeErr != nil {
+				log.Error().Err(removeErr).Str("path", path).Msg("Failed to remove export file")
+			}
+		}
+
+		return written, err
+	})
+	c.exportModal.Render(coll)
+
+	return nil
+}
+
+// handleUpdateMany opens the editor for the update of all documents matching
+// the current filter and shows the preview before running it
+func (c *Content) handleUpdateMany(ctx context.Context, rawUpdate string) *tcell.EventKey {
+	filter, _, _, err := c.parseQuery()
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error parsing query", err)
+		return nil
+	}
+
+	if rawUpdate == "" {
+		rawUpdate = defaultUpdateTemplate
+	}
+	edited, err := c.docModifier.EditUpdate(rawUpdate)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error editing update", err)
+		return nil
+	}
+	if edited == "" {
+		return nil
+	}
+
+	update, err := mongo.ParseUpdate(edited)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Invalid update", err)
+		return nil
+	}
+
+	db, coll := c.state.Db, c.state.Coll
+	count, err := c.Dao.CountDocuments(ctx, db, coll, filter)
+	if err != nil {
+		c.showDocumentError("Error counting documents", err)
+		return nil
+	}
+	if count == 0 {
+		modal.ShowInfo(c.App.Pages, "No documents match the current filter")
+		return nil
+	}
+
+	preview, err := c.Dao.PreviewUpdate(ctx, db, coll, filter, update, updatePreviewSize)
+	if err != nil {
+		c.showDocumentError("Error previewing update", err)
+		return nil
+	}
+
+	c.updateManyModal.SetUpdateFunc(func() {
+		matched, modified, err := c.Dao.UpdateManyDocuments(ctx, db, coll, filter, update)
+		if err != nil {
+			c.showDocumentError("Error updating documents", err)
+			return
+		}
+		if err := c.updateContent(ctx, false); err != nil {
+			modal.ShowError(c.App.Pages, "Error refreshing documents", err)
+			return
+		}
+		modal.ShowInfo(c.App.Pages, fmt.Sprintf("Matched %d, modified %d documents", matched, modified))
+	})
+	c.updateManyModal.SetEditFunc(func() {
+		c.handleUpdateMany(ctx, edited)
+	})
+
+	filterText := c.state.Filter
+	if filterText == "" {
+		filterText = "{}"
+	}
+	if err := c.updateManyModal.Render(filterText, util.CleanJsonWhitespaces(edited), count, preview); err != nil {
+		modal.ShowError(c.App.Pages, "Error rendering preview", err)
+	}
+	return nil
+}
+
+// handleDeleteMany deletes all documents matching the current filter, when
+// alwaysConfirm is set or many documents match, confirmation has to be typed
+func (c *Content) handleDeleteMany(ctx context.Context, alwaysConfirm bool) *tcell.EventKey {
+	filter, _, _, err := c.parseQuery()
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error parsing query", err)
+		return nil
+	}
+
+	db, coll := c.state.Db, c.state.Coll
+	count, err := c.Dao.CountDocuments(ctx, db, coll, filter)
+	if err != nil {
+		c.showDocumentError("Error counting documents", err)
+		return nil
+	}
+	if count == 0 {
+		modal.ShowInfo(c.App.Pages, "No documents match the current filter")
+		return nil
+	}
+
+	deleteFunc := func() {
+		deleted, err := c.Dao.DeleteManyDocuments(ctx, db, coll, filter)
+		if err != nil {
+			c.showDocumentError("Error deleting documents", err)
+			return
+		}
+		c.state.SetSkip(0)
+		if err := c.updateContent(ctx, false); err != nil {
+			modal.ShowError(c.App.Pages, "Error refreshing documents", err)
+			return
+		}
+		modal.ShowInfo(c.App.Pages, fmt.Sprintf("Deleted %d documents", deleted))
+	}
+
+	filterText := c.state.Filter
+	if filterText == "" {
+		filterText = "{}"
+	}
+	msg := fmt.Sprintf("Are you sure you want to delete [blue]%d[-] documents matching %s?", count, tview.Escape(filterText))
+
+	if alwaysConfirm || count >= largeDeleteThreshold {
+		c.deleteManyModal.Render(coll, count, msg, deleteFunc)
+		return nil
+	}
+
+	c.confirmModal.SetConfirmButtonLabel("Delete")
+	c.confirmModal.SetText(msg)
+	c.confirmModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		c.App.Pages.RemovePage(c.confirmModal.GetIdentifier())
+		if buttonLabel == "Delete" {
+			deleteFunc()
+		}
+	})
+	c.App.Pages.AddPage(c.confirmModal.GetIdentifier(), c.confirmModal, true, true)
+
+	return nil
+}
+
+// handleExplainQuery explains the find built from the current filter, sort and projection
+func (c *Content) handleExplainQuery(ctx context.Context) *tcell.EventKey {
+	filter, sort, projection, err := c.parseQuery()
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error parsing query", err)
+		return nil
+	}
+
+	plan, err := c.Dao.ExplainFind(ctx, c.state.Db, c.state.Coll, filter, sort, projection, c.state.Skip, c.state.Limit)
+	if err != nil {
+		modal.ShowError(c.App.Pages, "Error explaining query", err)
+		return nil
+	}
+
+	c.explainModal.Render("find", plan)
+	return nil
+}
+
+// handleToggleLiveMode starts or stops watching changes of the current collection
+func (c *Content) handleToggleLiveMode(ctx context.Context) *tcell.EventKey {
+	if c.liveCancel != nil {
+		c.stopLiveMode()
+		c.updateContent(ctx, true)
+		return nil
+	}
+
+	if err := c.startLiveMode(ctx); err != nil {
+		if errors.Is(err, mongo.ErrChangeStreamUnsupported) {
+			modal.ShowError(c.App.Pages, "Live mode requires a replica set or sharded cluster, it's not available on standalone servers", err)
+			return nil
+		}
+		modal.ShowError(c.App.Pages, "Error starting live mode", err)
+	}
+	return nil
+}
+
+// startLiveMode opens the change stream filtered by the query bar filter,
+// received changes are applied to the loaded documents
+func (c *Content) startLiveMode(ctx context.Context) error {
+	filter, _, _, err := c.parseQuery()
+	if err != nil {
+		return err
+	}
+
+	state := c.state
+	// updates of the shown documents are watched too, so they can be removed when they leave the filter
+	docs := state.GetAllDocs()
+	shownIds := make([]any, 0, len(docs))
+	for _, doc := range docs {
+		shownIds = append(shownIds, doc["_id"])
+	}
+	liveCtx, cancel := context.WithCancel(ctx)
+	onChange := func(event mongo.ChangeEvent) {
+		c.App.QueueUpdateDraw(func() {
+			// events may still be queued after live mode was stopped
+			if liveCtx.Err() != nil {
+				return
+			}
+			c.applyLiveChange(ctx, state, event)
+		})
+	}
+	onError := func(err error) {
+		c.App.QueueUpdateDraw(func() {
+			if liveCtx.Err() != nil {
+				return
+			}
+			c.stopLiveMode()
+			c.tableHeader.SetText(c.buildHeaderInfo())
+			modal.ShowError(c.App.Pages, "Live mode stopped", err)
+		})
+	}
+
+	if err := c.Dao.WatchCollection(liveCtx, state.Db, state.Coll, filter, shownIds, onChange, onError); err != nil {
+		cancel()
+		return err
+	}
+
+	c.liveCancel = cancel
+	c.liveChanges = make(map[string]mongo.ChangeEvent)
+	c.lastChange = nil
+	c.tableHeader.SetText(c.buildHeaderInfo())
+	return nil
+}
+
+func (c *Content) stopLiveMode() {
+	if c.liveCancel == nil {
+		return
+	}
+	c.liveCancel()
+	c.liveCancel = nil
+	c.liveChanges = nil
+	c.lastChange = nil
+}
+
+func (c *Content) applyLiveChange(ctx context.Context, state *mongo.CollectionState, event mongo.ChangeEvent) {
+	state.ApplyChange(event)
+	c.lastChange = &event
+	if event.OperationType == mongo.OperationDelete || event.LeftFilter {
+		delete(c.liveChanges, mongo.StringifyId(event.DocumentId))
+	} else {
+		c.liveChanges[mongo.StringifyId(event.DocumentId)] = event
+	}
+
+	// keep the selection, rendering moves it to the first row
+	row, col := c.table.GetSelection()
+	c.updateContent(ctx, true)
+	if row < c.table.GetRowCount() {
+		c.table.Select(row, col)
+	}
+}
+
 // Automatic sort (1 or -1) for given column, only in TableView
 func (c *Content) handleSortByColumn(ctx context.Context, col int) *tcell.EventKey {
 	if c.currentView != TableView {
@@ -1041,6 +1592,7 @@ func (c *Content) updateCellValue(ctx context.Context, _id any, fieldName, newVa
 
 	err := c.Dao.UpdateDocument(ctx, c.state.Db, c.state.Coll, _id, originalDoc, updatedDoc)
 	if err != nil {
+		c.refreshTransactionHeader(err)
 		return fmt.Errorf("error updating document: %w", err)
 	}
 
@@ -1098,3 +1650,105 @@ func (c *Content) setNestedField(docMap primitive.M, fieldPath, newValue string)
 
 	return nil
 }
+
+// showDocumentError shows the error of an operation run in the active transaction,
+// the header is refreshed when the server aborted the transaction
+func (c *Content) showDocumentError(message string, err error) {
+	c.refreshTransactionHeader(err)
+	modal.ShowError(c.App.Pages, message, err)
+}
+
+// refreshTransactionHeader removes the transaction from the header when err
+// shows it was aborted by the server, the error tells how many writes were rolled back
+func (c *Content) refreshTransactionHeader(err error) {
+	if errors.Is(err, mongo.ErrTransactionAborted) {
+		c.tableHeader.SetText(c.buildHeaderInfo())
+	}
+}
+
+// handleStartTransaction starts a transaction, inserts, edits and deletes
+// are then pending until the transaction is committed or aborted,
+// when the transaction is already active its pending writes are shown
+func (c *Content) handleStartTransaction() *tcell.EventKey {
+	if tx := c.Dao.ActiveTransaction(); tx != nil {
+		modal.ShowInfo(c.App.Pages, tview.Escape(formatPendingWrites("Pending writes", tx.Pending())))
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := c.Dao.StartTransaction(ctx); err != nil {
+		if errors.Is(err, mongo.ErrTransactionsUnsupported) {
+			modal.ShowError(c.App.Pages, "Transactions require a replica set or sharded cluster, they are not available on standalone servers", err)
+			return nil
+		}
+		modal.ShowError(c.App.Pages, "Error starting transaction", err)
+		return nil
+	}
+	c.tableHeader.SetText(c.buildHeaderInfo())
+	return nil
+}
+
+// handleFinishTransaction commits or aborts the active transaction after confirmation
+// and reloads documents, so they show the committed state
+func (c *Content) handleFinishTransaction(ctx context.Context, commit bool) *tcell.EventKey {
+	tx := c.Dao.ActiveTransaction()
+	if tx == nil {
+		modal.ShowError(c.App.Pages, "No active transaction", nil)
+		return nil
+	}
+
+	label, title := "Commit", "Commit writes"
+	if !commit {
+		label, title = "Abort", "Roll back writes"
+	}
+	c.confirmModal.SetConfirmButtonLabel(label)
+	c.confirmModal.SetText(tview.Escape(formatPendingWrites(title, tx.Pending())))
+	c.confirmModal.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		c.App.Pages.RemovePage(c.confirmModal.GetIdentifier())
+		if buttonLabel != label {
+			return
+		}
+
+		var err error
+		var pending []mongo.PendingOperation
+		if commit {
+			pending, err = c.Dao.CommitTransaction(ctx)
+		} else {
+			pending, err = c.Dao.AbortTransaction(ctx)
+		}
+		if errors.Is(err, mongo.ErrTransactionBusy) {
+			// the transaction is still active, it can be finished once the session is free
+			modal.ShowError(c.App.Pages, "Error finishing transaction", err)
+			return
+		}
+		if updateErr := c.updateContent(ctx, false); updateErr != nil {
+			log.Error().Err(updateErr).Msg("Error refreshing content after transaction")
+		}
+		if errors.Is(err, mongo.ErrTransactionAborted) {
+			modal.ShowError(c.App.Pages, fmt.Sprintf("Transaction was aborted by the server, %d staged writes were rolled back", len(pending)), err)
+			return
+		}
+		if err != nil {
+			modal.ShowError(c.App.Pages, fmt.Sprintf("Error finishing transaction, %d pending writes were not committed", len(pending)), err)
+			return
+		}
+		if commit {
+			modal.ShowInfo(c.App.Pages, fmt.Sprintf("Committed %d writes", len(pending)))
+		}
+	})
+	c.App.Pages.AddPage(c.confirmModal.GetIdentifier(), c.confirmModal, true, true)
+	return nil
+}
+
+func formatPendingWrites(title string, pending []mongo.PendingOperation) string {
+	if len(pending) == 0 {
+		return title + ": none"
+	}
+	lines := make([]string, 0, len(pending)+1)
+	lines = append(lines, fmt.Sprintf("%s (%d):", title, len(pending)))
+	for _, op := range pending {
+		lines = append(lines, op.String())
+	}
+	return strings.Join(lines, "\n")
+}
@@ -171,8 +171,9 @@ func (d *Databases) filter(ctx context.Context, text string) {
 
 			if matchedDB || len(matchedCollections) > 0 {
 				filteredDB := mongo.DBsWithCollections{
-					DB:          db.DB,
-					Collections: matchedCollections,
+					DB:              db.DB,
+					Collections:     matchedCollections,
+					CollectionTypes: db.CollectionTypes,
 				}
 				if matchedDB {
 					filteredDB.Collections = db.Collections
@@ -194,6 +195,10 @@ func (d *Databases) listDbsAndCollections(ctx context.Context) error {
 	}
 	d.dbsWithColls = dbsWitColls
 
+	// shard keys are only additional information, so errors are not shown
+	shardKeys, _ := d.Dao.GetShardKeys(ctx)
+	d.DbTree.SetShardKeys(shardKeys)
+
 	return nil
 }
 
@@ -201,6 +206,22 @@ func (d *Databases) SetSelectFunc(f func(ctx context.Context, db string, coll st
 	d.DbTree.SetSelectFunc(f)
 }
 
+func (d *Databases) SetProfilerFunc(f func(db string)) {
+	d.DbTree.SetProfilerFunc(f)
+}
+
+func (d *Databases) SetStatsFunc(f func(db, coll string)) {
+	d.DbTree.SetStatsFunc(f)
+}
+
+func (d *Databases) SetBucketFunc(f func(db, bucket string)) {
+	d.DbTree.SetBucketFunc(f)
+}
+
+func (d *Databases) SetUsersFunc(f func(db string)) {
+	d.DbTree.SetUsersFunc(f)
+}
+
 func (d *Databases) JumpToCollection(ctx context.Context, dbName, collectionName string) error {
 	if err := d.listDbsAndCollections(ctx); err != nil {
 		return err
@@ -3,7 +3,9 @@ package component
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/tview"
@@ -13,7 +15,9 @@ import (
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
 	"github.com/rs/zerolog/log"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 const (
@@ -21,17 +25,42 @@ const (
 	ConfirmModalId        = "ConfirmModal"
 	DatabaseTreeId        = "DatabaseTree"
 	DatabaseDeleteModalId = "DatabaseDeleteModal"
+	ValidationConfirmId   = "ValidationConfirm"
+	// validationCountTimeout limits counting of documents failing the validator
+	validationCountTimeout = 30 * time.Second
+	// sizesTimeout limits reading statistics of all collections for the tree
+	sizesTimeout = 60 * time.Second
 )
 
+// detailsMarker separates the collection name from its details,
+// like shard key or size, in the node text
+const detailsMarker = " [::d]"
+
 type DatabaseTree struct {
 	*core.BaseElement
 	*core.TreeView
 
 	inputModal  *primitives.InputModal
 	deleteModal *modal.Confirm
-	style       *config.DatabasesStyle
+	importModal *modal.ImportModal
+	createModal *modal.CreateCollectionModal
+	// validationConfirm asks for applying edited validation rules
+	validationConfirm *modal.Confirm
+	docModifier       *DocModifier
+	style             *config.DatabasesStyle
 
 	nodeSelectFunc func(ctx context.Context, db string, coll string) error
+	profilerFunc   func(db string)
+	usersFunc      func(db string)
+	statsFunc      func(db, coll string)
+	bucketFunc     func(db, bucket string)
+	// shardKeys are shard keys of sharded collections by namespace
+	shardKeys map[string]string
+	// collTypes are types of collections by namespace, used to pick the leaf symbol
+	collTypes map[string]string
+	// collSizes are document counts and sizes shown next to collections by namespace,
+	// empty when sizes are hidden
+	collSizes map[string]string
 }
 
 func NewDatabaseTree() *DatabaseTree {
@@ -40,6 +69,13 @@ func NewDatabaseTree() *DatabaseTree {
 		TreeView:    core.NewTreeView(),
 		inputModal:  primitives.NewInputModal(),
 		deleteModal: modal.NewConfirm(DatabaseDeleteModalId),
+		importModal: modal.NewImportModal(),
+		createModal: modal.NewCreateCollectionModal(),
+		collTypes:   map[string]string{},
+		collSizes:   map[string]string{},
+
+		validationConfirm: modal.NewConfirm(ValidationConfirmId),
+		docModifier:       NewDocModifier(),
 	}
 
 	d.SetIdentifier(DatabaseTreeId)
@@ -61,6 +97,18 @@ func (t *DatabaseTree) init() error {
 	if err := t.deleteModal.Init(t.App); err != nil {
 		return err
 	}
+	if err := t.importModal.Init(t.App); err != nil {
+		return err
+	}
+	if err := t.createModal.Init(t.App); err != nil {
+		return err
+	}
+	if err := t.validationConfirm.Init(t.App); err != nil {
+		return err
+	}
+	if err := t.docModifier.Init(t.App); err != nil {
+		return err
+	}
 
 	t.handleEvents()
 
@@ -74,7 +122,7 @@ func (t *DatabaseTree) setLayout() {
 	t.SetGraphics(false)
 
 	t.inputModal.SetBorder(true)
-	t.inputModal.SetTitle("Add collection")
+	t.inputModal.SetTitle("Rename collection")
 }
 
 func (t *DatabaseTree) setStyle() {
@@ -113,6 +161,27 @@ func (t *DatabaseTree) setKeybindings(ctx context.Context) {
 		case k.Contains(k.Databases.RenameCollection, event.Name()):
 			t.showRenameCollectionModal(ctx)
 			return nil
+		case k.Contains(k.Databases.ImportDocuments, event.Name()):
+			t.showImportModal(ctx)
+			return nil
+		case k.Contains(k.Databases.ShowProfiler, event.Name()):
+			t.showDbView(t.profilerFunc)
+			return nil
+		case k.Contains(k.Databases.ShowUsers, event.Name()):
+			t.showDbView(t.usersFunc)
+			return nil
+		case k.Contains(k.Databases.EditValidation, event.Name()):
+			t.editValidation(ctx)
+			return nil
+		case k.Contains(k.Databases.TestValidation, event.Name()):
+			t.testValidation(ctx)
+			return nil
+		case k.Contains(k.Databases.ShowStats, event.Name()):
+			t.showStats()
+			return nil
+		case k.Contains(k.Databases.ToggleSizes, event.Name()):
+			t.toggleSizes()
+			return nil
 		}
 		return event
 	})
@@ -156,11 +225,13 @@ func (t *DatabaseTree) Render(ctx context.Context, dbsWitColls []mongo.DBsWithCo
 		rootNode.AddChild(emptyNode)
 	}
 
+	t.collTypes = map[string]string{}
 	for _, item := range dbsWitColls {
 		parent := t.dbNode(item.DB)
 		rootNode.AddChild(parent)
 
 		for _, child := range item.Collections {
+			t.collTypes[item.DB+"."+child] = item.CollectionTypes[child]
 			t.addChildNode(ctx, parent, child, false)
 		}
 	}
@@ -176,7 +247,7 @@ func (t *DatabaseTree) RefreshStyle() {
 		if parent != nil {
 			t.updateNodeSymbol(parent)
 		}
-		t.updateLeafSymbol(node)
+		t.updateLeafSymbol(node, parent)
 		return true
 	})
 }
@@ -186,42 +257,25 @@ func (t *DatabaseTree) showAddCollectionModal(ctx context.Context) error {
 	if parent == nil {
 		return nil
 	}
-	db := parent.GetText()
+	db, _ := t.removeSymbols(parent.GetText(), "")
 
-	t.inputModal.SetLabel(fmt.Sprintf("Add collection name for [%s][::b]%s", t.style.NodeTextColor.Color(), db))
-	t.inputModal.SetInputCapture(t.createAddCollectionInputCapture(ctx, parent, db))
-	t.App.Pages.AddPage(InputModalId, t.inputModal, true, true)
+	t.createModal.SetCreateFunc(func(name string, opts mongo.CollectionOptions) error {
+		return t.handleAddCollection(ctx, parent, db, name, opts)
+	})
+	t.createModal.Render(db)
 	return nil
 }
 
-func (t *DatabaseTree) createAddCollectionInputCapture(ctx context.Context, parent *tview.TreeNode, db string) func(*tcell.EventKey) *tcell.EventKey {
-	return func(event *tcell.EventKey) *tcell.EventKey {
-		switch event.Key() {
-		case tcell.KeyEnter:
-			t.handleAddCollection(ctx, parent, db)
-		case tcell.KeyEscape:
-			t.closeAddModal()
-		}
-		return event
-	}
-}
-
-func (t *DatabaseTree) handleAddCollection(ctx context.Context, parent *tview.TreeNode, db string) {
-	collectionName := t.inputModal.GetText()
-	if collectionName == "" {
-		return
-	}
-	db, collectionName = t.removeSymbols(db, collectionName)
-	err := t.Dao.AddCollection(ctx, db, collectionName)
-	if err != nil {
-		modal.ShowError(t.App.Pages, "Error adding collection", err)
-		return
+func (t *DatabaseTree) handleAddCollection(ctx context.Context, parent *tview.TreeNode, db, collectionName string, opts mongo.CollectionOptions) error {
+	if err := t.Dao.CreateCollection(ctx, db, collectionName, opts); err != nil {
+		return err
 	}
+	t.collTypes[db+"."+collectionName] = opts.Type
 	t.addChildNode(ctx, parent, collectionName, true)
-	t.closeAddModal()
+	return nil
 }
 
-func (t *DatabaseTree) closeAddModal() {
+func (t *DatabaseTree) closeInputModal() {
 	t.inputModal.SetText("")
 	t.App.Pages.RemovePage(InputModalId)
 }
@@ -239,16 +293,268 @@ func (t *DatabaseTree) showDeleteCollectionModal(ctx context.Context) error {
 	return nil
 }
 
+func (t *DatabaseTree) showImportModal(ctx context.Context) {
+	if t.GetCurrentNode().GetLevel() < 2 {
+		return
+	}
+	parent := t.GetCurrentNode().GetReference().(*tview.TreeNode)
+	db, coll := t.removeSymbols(parent.GetText(), t.GetCurrentNode().GetText())
+	if t.isBucket(db, coll) {
+		modal.ShowError(t.App.Pages, "Documents can't be imported into GridFS bucket", nil)
+		return
+	}
+
+	t.importModal.SetImportFunc(func(importCtx context.Context, path string, opts mongo.ImportOptions, progress func(mongo.ImportResult)) (mongo.ImportResult, error) {
+		file, err := os.Open(path)
+		if err != nil {
+			return mongo.ImportResult{}, err
+		}
+		defer file.Close()
+
+		result, err := t.Dao.ImportDocuments(importCtx, db, coll, file, opts, progress)
+		if result.Inserted+result.Updated > 0 && t.nodeSelectFunc != nil {
+			t.App.QueueUpdateDraw(func() {
+				if err := t.nodeSelectFunc(ctx, db, coll); err != nil {
+					log.Error().Err(err).Msg("Error refreshing content after import")
+				}
+			})
+		}
+		return result, err
+	})
+	t.importModal.Render(db, coll)
+}
+
+// currentCollection returns the database and collection of the current node,
+// false is returned when the current node is not a collection
+func (t *DatabaseTree) currentCollection() (string, string, bool) {
+	if t.GetCurrentNode() == nil || t.GetCurrentNode().GetLevel() < 2 {
+		return "", "", false
+	}
+	parent := t.GetCurrentNode().GetReference().(*tview.TreeNode)
+	db, coll := t.removeSymbols(parent.GetText(), t.GetCurrentNode().GetText())
+	return db, coll, true
+}
+
+// editValidation opens the validation rules of the current collection
+// in the editor and applies them after confirmation
+func (t *DatabaseTree) editValidation(ctx context.Context) {
+	db, coll, ok := t.currentCollection()
+	if !ok {
+		return
+	}
+	if t.isBucket(db, coll) {
+		modal.ShowError(t.App.Pages, "Validation rules can't be set on GridFS bucket", nil)
+		return
+	}
+
+	rules, err := t.Dao.GetValidationRules(ctx, db, coll)
+	if err != nil {
+		modal.ShowError(t.App.Pages, "Error getting validation rules", err)
+		return
+	}
+	rulesJson, err := rules.ToJson()
+	if err != nil {
+		modal.ShowError(t.App.Pages, "Error converting validation rules", err)
+		return
+	}
+
+	edited, err := t.docModifier.EditValidation(rulesJson)
+	if err != nil {
+		modal.ShowError(t.App.Pages, "Error editing validation rules", err)
+		return
+	}
+	if edited == "" {
+		return
+	}
+	newRules, err := mongo.ParseValidationRulesJson(edited)
+	if err != nil {
+		modal.ShowError(t.App.Pages, "Invalid validation rules", err)
+		return
+	}
+
+	failing := "unknown number of"
+	if count, err := t.countInvalidDocuments(ctx, db, coll, newRules.Validator); err == nil {
+		failing = fmt.Sprintf("%d", count)
+	}
+	t.validationConfirm.SetConfirmButtonLabel("Apply")
+	t.validationConfirm.SetText(fmt.Sprintf("Apply validation rules to [%s]%s[-:-:-]? %s existing documents do not pass the new validator.",
+		t.style.LeafTextColor.Color(), coll, failing))
+	t.validationConfirm.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		t.App.Pages.RemovePage(ValidationConfirmId)
+		if buttonLabel != "Apply" {
+			return
+		}
+		if err := t.Dao.SetValidationRules(ctx, db, coll, newRules); err != nil {
+			modal.ShowError(t.App.Pages, "Error applying validation rules", err)
+			return
+		}
+		modal.ShowInfo(t.App.Pages, fmt.Sprintf("Validation rules of %s applied", coll))
+	})
+	t.App.Pages.AddPage(ValidationConfirmId, t.validationConfirm, true, true)
+}
+
+// testValidation counts documents of the current collection
+// which do not pass its validator
+func (t *DatabaseTree) testValidation(ctx context.Context) {
+	db, coll, ok := t.currentCollection()
+	if !ok {
+		return
+	}
+	if t.isBucket(db, coll) {
+		modal.ShowError(t.App.Pages, "Validation rules can't be set on GridFS bucket", nil)
+		return
+	}
+
+	rules, err := t.Dao.GetValidationRules(ctx, db, coll)
+	if err != nil {
+		modal.ShowError(t.App.Pages, "Error getting validation rules", err)
+		return
+	}
+	if len(rules.Validator) == 0 {
+		modal.ShowInfo(t.App.Pages, fmt.Sprintf("Collection %s has no validator", coll))
+		return
+	}
+
+	count, err := t.countInvalidDocuments(ctx, db, coll, rules.Validator)
+	if err != nil {
+		modal.ShowError(t.App.Pages, "Error testing validator", err)
+		return
+	}
+	modal.ShowInfo(t.App.Pages, fmt.Sprintf("%d documents in %s do not pass the validator", count, coll))
+}
+
+func (t *DatabaseTree) countInvalidDocuments(ctx context.Context, db, coll string, validator primitive.M) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, validationCountTimeout)
+	defer cancel()
+	return t.Dao.CountInvalidDocuments(ctx, db, coll, validator)
+}
+
+// showDbView opens the view of the database of the current node
+func (t *DatabaseTree) showDbView(showFunc func(db string)) {
+	parent := t.getParentNode()
+	if parent == nil || showFunc == nil {
+		return
+	}
+	db, _ := t.removeSymbols(parent.GetText(), "")
+	showFunc(db)
+}
+
+// showStats opens statistics of the current collection, of the chunks collection
+// for GridFS bucket, or of the database when the database node is selected
+func (t *DatabaseTree) showStats() {
+	if t.statsFunc == nil || t.GetCurrentNode() == nil {
+		return
+	}
+	if db, coll, ok := t.currentCollection(); ok {
+		if t.isBucket(db, coll) {
+			// chunks hold the content of files, so they take most of the bucket's storage
+			_, coll = mongo.GridFSCollections(coll)
+		}
+		t.statsFunc(db, coll)
+		return
+	}
+	parent := t.getParentNode()
+	if parent == nil {
+		return
+	}
+	db, _ := t.removeSymbols(parent.GetText(), "")
+	t.statsFunc(db, "")
+}
+
+// toggleSizes shows or hides document counts and storage sizes
+// next to collection names, statistics are read in the background
+func (t *DatabaseTree) toggleSizes() {
+	if len(t.collSizes) > 0 {
+		t.collSizes = map[string]string{}
+		t.refreshCollectionTexts()
+		return
+	}
+
+	dbs := []string{}
+	for _, dbNode := range t.GetRoot().GetChildren() {
+		if len(dbNode.GetChildren()) > 0 {
+			db, _ := t.removeSymbols(dbNode.GetText(), "")
+			dbs = append(dbs, db)
+		}
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sizesTimeout)
+		defer cancel()
+		sizes := map[string]string{}
+		for _, db := range dbs {
+			stats, err := t.Dao.ListCollectionStats(ctx, db)
+			if err != nil {
+				log.Error().Err(err).Str("db", db).Msg("Error reading collection sizes")
+				continue
+			}
+			for _, coll := range stats {
+				sizes[db+"."+coll.Name] = fmt.Sprintf("%s docs, %s",
+					util.FormatCount(coll.Count), util.FormatBytes(float64(coll.StorageSize)))
+			}
+		}
+		t.App.QueueUpdateDraw(func() {
+			t.collSizes = sizes
+			t.refreshCollectionTexts()
+		})
+	}()
+}
+
+// refreshCollectionTexts rebuilds texts of collection nodes with current details
+func (t *DatabaseTree) refreshCollectionTexts() {
+	for _, dbNode := range t.GetRoot().GetChildren() {
+		db, _ := t.removeSymbols(dbNode.GetText(), "")
+		for _, collNode := range dbNode.GetChildren() {
+			_, coll := t.removeSymbols("", collNode.GetText())
+			collNode.SetText(t.collText(db, coll))
+		}
+	}
+}
+
+func (t *DatabaseTree) SetStatsFunc(f func(db, coll string)) {
+	t.statsFunc = f
+}
+
+// SetBucketFunc sets the function called when GridFS bucket node is selected
+func (t *DatabaseTree) SetBucketFunc(f func(db, bucket string)) {
+	t.bucketFunc = f
+}
+
+// isBucket checks if the collection node is a GridFS bucket
+func (t *DatabaseTree) isBucket(db, coll string) bool {
+	return t.collTypes[db+"."+coll] == mongo.CollectionTypeGridFSBucket
+}
+
+func (t *DatabaseTree) SetProfilerFunc(f func(db string)) {
+	t.profilerFunc = f
+}
+
+func (t *DatabaseTree) SetUsersFunc(f func(db string)) {
+	t.usersFunc = f
+}
+
+// SetShardKeys sets shard keys which are shown next to sharded collections
+func (t *DatabaseTree) SetShardKeys(shardKeys map[string]string) {
+	t.shardKeys = shardKeys
+}
+
 func (t *DatabaseTree) SetSelectFunc(f func(ctx context.Context, db string, coll string) error) {
 	t.nodeSelectFunc = f
 }
 
 func (t *DatabaseTree) addChildNode(ctx context.Context, parent *tview.TreeNode, collectionName string, expand bool) {
-	collNode := t.collNode(collectionName)
+	db, _ := t.removeSymbols(parent.GetText(), "")
+	collNode := t.collNode(db, collectionName)
 	parent.AddChild(collNode).SetExpanded(expand)
 	collNode.SetReference(parent)
 	collNode.SetSelectedFunc(func() {
 		db, coll := t.removeSymbols(parent.GetText(), collNode.GetText())
+		if t.isBucket(db, coll) {
+			if t.bucketFunc != nil {
+				t.bucketFunc(db, coll)
+			}
+			return
+		}
 		err := t.nodeSelectFunc(ctx, db, coll)
 		if err != nil {
 			log.Error().Err(err).Msg("Error selecting node")
@@ -286,9 +592,8 @@ func (t *DatabaseTree) dbNode(name string) *tview.TreeNode {
 	return r
 }
 
-func (t *DatabaseTree) collNode(name string) *tview.TreeNode {
-	leafSymbol := config.SymbolWithColor(t.style.LeafSymbol, t.style.LeafSymbolColor)
-	ch := tview.NewTreeNode(fmt.Sprintf("%s %s", leafSymbol, name))
+func (t *DatabaseTree) collNode(db, name string) *tview.TreeNode {
+	ch := tview.NewTreeNode(t.collText(db, name))
 	ch.SetColor(t.style.LeafTextColor.Color())
 	ch.SetSelectable(true)
 	ch.SetExpanded(false)
@@ -296,20 +601,58 @@ func (t *DatabaseTree) collNode(name string) *tview.TreeNode {
 	return ch
 }
 
+// collText returns the node text of the collection with its shard key and size
+func (t *DatabaseTree) collText(db, name string) string {
+	text := fmt.Sprintf("%s %s", t.leafSymbol(db, name), name)
+	details := []string{}
+	if shardKey, ok := t.shardKey(db, name); ok {
+		details = append(details, tview.Escape(shardKey))
+	}
+	if size, ok := t.collSizes[db+"."+name]; ok {
+		details = append(details, size)
+	}
+	if len(details) > 0 {
+		text += detailsMarker + strings.Join(details, " | ")
+	}
+	return text
+}
+
+// shardKey returns the shard key of the collection, for GridFS bucket it's the key
+// of its chunks collection, or of the files collection when only that one is sharded
+func (t *DatabaseTree) shardKey(db, coll string) (string, bool) {
+	if !t.isBucket(db, coll) {
+		shardKey, ok := t.shardKeys[db+"."+coll]
+		return shardKey, ok
+	}
+	files, chunks := mongo.GridFSCollections(coll)
+	if shardKey, ok := t.shardKeys[db+"."+chunks]; ok {
+		return shardKey, true
+	}
+	shardKey, ok := t.shardKeys[db+"."+files]
+	return shardKey, ok
+}
+
 func (t *DatabaseTree) removeSymbols(db, coll string) (string, string) {
 	openNodeSymbol := config.SymbolWithColor(t.style.OpenNodeSymbol, t.style.NodeSymbolColor)
 	closedNodeSymbol := config.SymbolWithColor(t.style.ClosedNodeSymbol, t.style.NodeSymbolColor)
 	leafSymbol := config.SymbolWithColor(t.style.LeafSymbol, t.style.LeafSymbolColor)
+	viewSymbol := config.SymbolWithColor(t.style.ViewSymbol, t.style.LeafSymbolColor)
+	timeSeriesSymbol := config.SymbolWithColor(t.style.TimeSeriesSymbol, t.style.LeafSymbolColor)
+	bucketSymbol := config.SymbolWithColor(t.style.BucketSymbol, t.style.LeafSymbolColor)
 	symbolsToRemove := []string{
 		openNodeSymbol,
 		closedNodeSymbol,
 		leafSymbol,
+		viewSymbol,
+		timeSeriesSymbol,
+		bucketSymbol,
 	}
 
 	for _, symbol := range symbolsToRemove {
 		db = strings.ReplaceAll(db, symbol, "")
 		coll = strings.ReplaceAll(coll, symbol, "")
 	}
+	coll, _, _ = strings.Cut(coll, detailsMarker)
 
 	return strings.TrimSpace(db), strings.TrimSpace(coll)
 }
@@ -345,7 +688,12 @@ func (t *DatabaseTree) createDeleteCollectionDoneFunc(ctx context.Context, db, c
 }
 
 func (t *DatabaseTree) handleDeleteCollection(ctx context.Context, db, coll string, parent *tview.TreeNode) {
-	err := t.Dao.DeleteCollection(ctx, db, coll)
+	var err error
+	if t.isBucket(db, coll) {
+		err = t.Dao.DropGridFSBucket(ctx, db, coll)
+	} else {
+		err = t.Dao.DeleteCollection(ctx, db, coll)
+	}
 	if err != nil {
 		return
 	}
@@ -410,20 +758,42 @@ func (t *DatabaseTree) updateNodeSymbol(node *tview.TreeNode) {
 	})
 }
 
-func (t *DatabaseTree) updateLeafSymbol(node *tview.TreeNode) {
+func (t *DatabaseTree) updateLeafSymbol(node, parent *tview.TreeNode) {
 	node.SetColor(t.style.LeafTextColor.Color())
-	leafSymbol := config.SymbolWithColor(t.style.LeafSymbol, t.style.LeafSymbolColor)
 	name := extractName(node.GetText())
 	if name == "" {
 		return
 	}
-	node.SetText(fmt.Sprintf("%s %s", leafSymbol, name))
+	db := ""
+	if parent != nil {
+		db = extractName(parent.GetText())
+	}
+	coll, _, _ := strings.Cut(name, detailsMarker)
+	node.SetText(fmt.Sprintf("%s %s", t.leafSymbol(db, coll), name))
+}
+
+// leafSymbol returns the symbol of the collection depending on its type
+func (t *DatabaseTree) leafSymbol(db, coll string) string {
+	switch t.collTypes[db+"."+coll] {
+	case mongo.CollectionTypeView:
+		return config.SymbolWithColor(t.style.ViewSymbol, t.style.LeafSymbolColor)
+	case mongo.CollectionTypeTimeSeries:
+		return config.SymbolWithColor(t.style.TimeSeriesSymbol, t.style.LeafSymbolColor)
+	case mongo.CollectionTypeGridFSBucket:
+		return config.SymbolWithColor(t.style.BucketSymbol, t.style.LeafSymbolColor)
+	default:
+		return config.SymbolWithColor(t.style.LeafSymbol, t.style.LeafSymbolColor)
+	}
 }
 
 func (t *DatabaseTree) showRenameCollectionModal(ctx context.Context) error {
 	if t.GetCurrentNode().GetLevel() < 2 {
 		return fmt.Errorf("cannot rename database")
 	}
+	if db, coll, _ := t.currentCollection(); t.isBucket(db, coll) {
+		modal.ShowError(t.App.Pages, "GridFS bucket can't be renamed", nil)
+		return nil
+	}
 	db, coll := t.GetCurrentNode().GetReference().(*tview.TreeNode).GetText(), t.GetCurrentNode().GetText()
 	t.inputModal.SetLabel(fmt.Sprintf("Rename collection name for [%s][::b]%s", t.style.NodeTextColor.Color(), db))
 	t.inputModal.SetInputCapture(t.createRenameCollectionInputCapture(ctx, db, coll))
@@ -437,7 +807,7 @@ func (t *DatabaseTree) createRenameCollectionInputCapture(ctx context.Context, d
 		case tcell.KeyEnter:
 			t.handleRenameCollection(ctx, db, coll)
 		case tcell.KeyEscape:
-			t.closeAddModal()
+			t.closeInputModal()
 		}
 		return event
 	}
@@ -454,15 +824,20 @@ func (t *DatabaseTree) handleRenameCollection(ctx context.Context, db, coll stri
 		modal.ShowError(t.App.Pages, "Error renaming collection", err)
 		return
 	}
-	t.renameCollectionNode(newCollectionName)
-	t.closeAddModal()
+	t.renameCollectionNode(db, coll, newCollectionName)
+	t.closeInputModal()
 }
 
-func (t *DatabaseTree) renameCollectionNode(newName string) {
-	currentNode := t.GetCurrentNode()
-	leafSymbol := config.SymbolWithColor(t.style.LeafSymbol, t.style.LeafSymbolColor)
-	newText := fmt.Sprintf("%s %s", leafSymbol, newName)
-	currentNode.SetText(newText)
+func (t *DatabaseTree) renameCollectionNode(db, coll, newName string) {
+	if collType, ok := t.collTypes[db+"."+coll]; ok {
+		delete(t.collTypes, db+"."+coll)
+		t.collTypes[db+"."+newName] = collType
+	}
+	if size, ok := t.collSizes[db+"."+coll]; ok {
+		delete(t.collSizes, db+"."+coll)
+		t.collSizes[db+"."+newName] = size
+	}
+	t.GetCurrentNode().SetText(t.collText(db, newName))
 }
 
 func (t *DatabaseTree) JumpToCollection(ctx context.Context, targetDb, targetColl string) error {
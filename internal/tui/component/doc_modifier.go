@@ -14,11 +14,12 @@ import (
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/kopecmaciej/vi-mongo/internal/util"
 	"github.com/rs/zerolog/log"
-	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 const (
 	DocModifierId = "DocModifier"
+
+	defaultUpdateTemplate = `{"$set": {}}`
 )
 
 // DocModifier is a view that allows editing JSON documents
@@ -32,32 +33,29 @@ func NewDocModifier() *DocModifier {
 	}
 }
 
-func (d *DocModifier) Insert(ctx context.Context, db, coll string) (primitive.ObjectID, error) {
+// Insert opens the editor with an empty document and inserts it, the returned
+// _id can be of any BSON type, it's nil when nothing was inserted
+func (d *DocModifier) Insert(ctx context.Context, db, coll string) (any, error) {
 	createdDoc, err := d.openEditor("{}")
 	if err != nil {
-		return primitive.NilObjectID, nil
+		return nil, nil
 	}
 	if strings.ReplaceAll(createdDoc, " ", "") == "{}" {
 		log.Debug().Msgf("No document created")
-		return primitive.NilObjectID, nil
+		return nil, nil
 	}
 
 	document, err := mongo.ParseJsonToBson(createdDoc)
 	if err != nil {
-		return primitive.NilObjectID, fmt.Errorf("error parsing JSON: %v", err)
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
 	}
 
 	rawId, err := d.Dao.InsetDocument(ctx, db, coll, document)
 	if err != nil {
-		return primitive.NilObjectID, fmt.Errorf("error inserting document: %v", err)
-	}
-
-	id, ok := rawId.(primitive.ObjectID)
-	if !ok {
-		return primitive.NilObjectID, fmt.Errorf("error converting _id to primitive.ObjectID")
+		return nil, fmt.Errorf("error inserting document: %w", err)
 	}
 
-	return id, nil
+	return rawId, nil
 }
 
 // Edit opens the editor with the document and saves it if it was changed
@@ -74,73 +72,91 @@ func (d *DocModifier) Edit(ctx context.Context, db, coll string, _id any, jsonDo
 
 	err = d.updateDocument(ctx, db, coll, _id, jsonDoc, updatedDocument)
 	if err != nil {
-		return "", fmt.Errorf("error saving document: %v", err)
+		return "", fmt.Errorf("error saving document: %w", err)
 	}
 
 	return updatedDocument, nil
 }
 
 // Duplicate opens the editor with the document and saves it as a new document
-func (d *DocModifier) Duplicate(ctx context.Context, db, coll string, rawDocument string) (primitive.ObjectID, error) {
+func (d *DocModifier) Duplicate(ctx context.Context, db, coll string, rawDocument string) (any, error) {
 	replacedDoc, err := removeField(rawDocument, "_id")
 	if err != nil {
-		return primitive.NilObjectID, fmt.Errorf("error removing _id field: %v", err)
+		return nil, fmt.Errorf("error removing _id field: %v", err)
 	}
 
 	duplicateDoc, err := d.openEditor(replacedDoc)
 	if err != nil {
-		return primitive.NilObjectID, err
+		return nil, err
 	}
 	if duplicateDoc == "" {
 		log.Debug().Msgf("Document not duplicated")
-		return primitive.NilObjectID, nil
+		return nil, nil
 	}
 
 	parsedDoc, err := mongo.ParseJsonToBson(duplicateDoc)
 	if err != nil {
-		return primitive.NilObjectID, fmt.Errorf("error parsing JSON: %v", err)
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
 	}
 
 	delete(parsedDoc, "_id")
 
 	rawID, err := d.Dao.InsetDocument(ctx, db, coll, parsedDoc)
 	if err != nil {
-		return primitive.NilObjectID, fmt.Errorf("error inserting document: %v", err)
-	}
-
-	id, ok := rawID.(primitive.ObjectID)
-	if !ok {
-		return primitive.NilObjectID, fmt.Errorf("error converting _id to primitive.ObjectID")
+		return nil, fmt.Errorf("error inserting document: %w", err)
 	}
 
-	return id, nil
+	return rawID, nil
 }
 
 // DuplicateNoEditor duplicates a document without opening an editor
-func (d *DocModifier) DuplicateNoEditor(ctx context.Context, db, coll string, rawDocument string) (primitive.ObjectID, error) {
+func (d *DocModifier) DuplicateNoEditor(ctx context.Context, db, coll string, rawDocument string) (any, error) {
 	replacedDoc, err := removeField(rawDocument, "_id")
 	if err != nil {
-		return primitive.NilObjectID, fmt.Errorf("error removing _id field: %v", err)
+		return nil, fmt.Errorf("error removing _id field: %v", err)
 	}
 
 	parsedDoc, err := mongo.ParseJsonToBson(replacedDoc)
 	if err != nil {
-		return primitive.NilObjectID, fmt.Errorf("error parsing JSON: %v", err)
+		return nil, fmt.Errorf("error parsing JSON: %v", err)
 	}
 
 	delete(parsedDoc, "_id") // Extra safety to ensure _id is removed
 
 	rawID, err := d.Dao.InsetDocument(ctx, db, coll, parsedDoc)
 	if err != nil {
-		return primitive.NilObjectID, fmt.Errorf("error inserting document: %v", err)
+		return nil, fmt.Errorf("error inserting document: %w", err)
 	}
 
-	id, ok := rawID.(primitive.ObjectID)
-	if !ok {
-		return primitive.NilObjectID, fmt.Errorf("error converting _id to primitive.ObjectID")
+	return rawID, nil
+}
+
+// EditUpdate opens the editor with the update document or pipeline and returns
+// the edited text, empty string means that nothing was changed
+func (d *DocModifier) EditUpdate(update string) (string, error) {
+	edited, err := d.openEditor(update)
+	if err != nil {
+		return "", err
 	}
+	if util.CleanAllWhitespaces(edited) == util.CleanAllWhitespaces(defaultUpdateTemplate) {
+		log.Debug().Msgf("No update provided")
+		return "", nil
+	}
+	return edited, nil
+}
 
-	return id, nil
+// EditValidation opens the editor with the validation rules and returns
+// the edited text, empty string means that nothing was changed
+func (d *DocModifier) EditValidation(rules string) (string, error) {
+	edited, err := d.openEditor(rules)
+	if err != nil {
+		return "", err
+	}
+	if edited == "" || util.CleanAllWhitespaces(edited) == util.CleanAllWhitespaces(rules) {
+		log.Debug().Msgf("Validation rules not changed")
+		return "", nil
+	}
+	return edited, nil
 }
 
 // updateDocument saves the document to the database
@@ -268,8 +284,11 @@ func (d *DocModifier) writeToTempFile(bufferJson bytes.Buffer) (*os.File, error)
 // removeField removes the specified field from a JSON string.
 func removeField(jsonStr, fieldToRemove string) (string, error) {
 	// Unmarshal the JSON into a map
+	// numbers are kept as they are, so big integers don't lose precision
 	var data map[string]any
-	err := json.Unmarshal([]byte(jsonStr), &data)
+	decoder := json.NewDecoder(strings.NewReader(jsonStr))
+	decoder.UseNumber()
+	err := decoder.Decode(&data)
 	if err != nil {
 		log.Error().Err(err).Msg("Error while unmarshalling JSON")
 		return "", err
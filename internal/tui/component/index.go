@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/tview"
@@ -14,30 +15,53 @@ import (
 	"github.com/kopecmaciej/vi-mongo/internal/mongo"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
 	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
-	"go.mongodb.org/mongo-driver/bson"
 	mongoDriver "go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const (
 	IndexId            = "Index"
 	IndexAddFormId     = "IndexAddForm"
 	IndexDeleteModalId = "IndexDeleteModal"
+	// indexBuildPollInterval is how often the progress of index build is checked
+	indexBuildPollInterval = time.Second
+	// recommendationsTimeout limits explaining recorded queries for recommendations
+	recommendationsTimeout = 30 * time.Second
+)
+
+// indexKeyTypeLabels are labels of mongo.IndexKeyTypes in the same order
+var indexKeyTypeLabels = []string{"1 (Ascending)", "-1 (Descending)", "text", "2dsphere", "2d", "hashed", "wildcard"}
+
+const (
+	indexNameLabel          = "Index Name"
+	uniqueLabel             = "Unique"
+	sparseLabel             = "Sparse"
+	hiddenLabel             = "Hidden"
+	ttlLabel                = "TTL (seconds)"
+	partialFilterLabel      = "Partial filter"
+	collationLocaleLabel    = "Collation locale"
+	wildcardProjectionLabel = "Wildcard projection"
+	textWeightsLabel        = "Text weights"
+	defaultLanguageLabel    = "Default language"
 )
 
 type Index struct {
 	*core.BaseElement
 	*core.Flex
 
-	table       *core.Table
-	addForm     *core.Form
-	indexes     []mongo.IndexInfo
-	deleteModal *modal.Confirm
+	table           *core.Table
+	addForm         *core.Form
+	indexes         []mongo.IndexInfo
+	deleteModal     *modal.Confirm
+	recommendations *core.Table
 
 	currentDB        string
 	currentColl      string
 	docKeys          []string
 	isAddFormVisible bool
+	// keyCount is the number of field and type pairs at the top of the add form
+	keyCount int
+	// showRecommendations is set when recommendations panel is shown below indexes
+	showRecommendations bool
 }
 
 func NewIndex() *Index {
@@ -46,6 +70,7 @@ func NewIndex() *Index {
 		Flex:             core.NewFlex(),
 		table:            core.NewTable(),
 		addForm:          core.NewForm(),
+		recommendations:  core.NewTable(),
 		deleteModal:      modal.NewConfirm(IndexDeleteModalId),
 		isAddFormVisible: false,
 	}
@@ -75,9 +100,12 @@ func (i *Index) setStyle() {
 	i.SetStyle(globalStyle)
 	i.table.SetStyle(globalStyle)
 	i.addForm.SetStyle(globalStyle)
+	i.recommendations.SetStyle(globalStyle)
 
-	i.table.SetSeparator(globalStyle.Others.SeparatorSymbol.Rune())
-	i.table.SetBordersColor(globalStyle.Others.SeparatorColor.Color())
+	for _, table := range []*core.Table{i.table, i.recommendations} {
+		table.SetSeparator(globalStyle.Others.SeparatorSymbol.Rune())
+		table.SetBordersColor(globalStyle.Others.SeparatorColor.Color())
+	}
 }
 
 func (i *Index) setLayout() {
@@ -86,6 +114,11 @@ func (i *Index) setLayout() {
 	i.SetTitleAlign(tview.AlignCenter)
 	i.SetBorderPadding(0, 0, 1, 1)
 	i.table.SetSelectable(true, true)
+
+	i.recommendations.SetBorder(true)
+	i.recommendations.SetTitle(" Recommendations ")
+	i.recommendations.SetTitleAlign(tview.AlignLeft)
+	i.recommendations.SetSelectable(false, false)
 }
 
 func (i *Index) handleEvents() {
@@ -123,6 +156,16 @@ func (i *Index) setKeybindings() {
 				i.showDeleteIndexModal()
 				return nil
 			}
+		case k.Contains(k.Index.ToggleHidden, event.Name()):
+			if !i.isAddFormVisible {
+				i.toggleHidden()
+				return nil
+			}
+		case k.Contains(k.Index.ToggleRecommendations, event.Name()):
+			if !i.isAddFormVisible {
+				i.toggleRecommendations()
+				return nil
+			}
 		case k.Contains(k.IndexAddForm.ExitForm, event.Name()):
 			if i.isAddFormVisible {
 				i.closeAddForm()
@@ -146,6 +189,7 @@ func (i *Index) setKeybindings() {
 func (i *Index) HandleDatabaseSelection(ctx context.Context, db, coll string) error {
 	i.currentDB = db
 	i.currentColl = coll
+	i.showRecommendations = false
 	return i.refreshIndexes(ctx)
 }
 
@@ -169,7 +213,10 @@ func (i *Index) Render() {
 	} else {
 		i.table.Clear()
 		i.renderIndexTable()
-		i.Flex.AddItem(i.table, 0, 1, true)
+		i.Flex.AddItem(i.table, 0, 2, true)
+		if i.showRecommendations {
+			i.Flex.AddItem(i.recommendations, 0, 1, false)
+		}
 	}
 }
 
@@ -178,14 +225,7 @@ func (i *Index) renderIndexTable() {
 	i.table.SetFixed(1, 0)
 
 	headers := []string{"Name", "Definition", "Type", "Size", "Usage", "Properties"}
-	for col, header := range headers {
-		cell := tview.NewTableCell(" " + header + " ").
-			SetSelectable(false).
-			SetAlign(tview.AlignCenter).
-			SetTextColor(styles.Content.ColumnKeyColor.Color()).
-			SetBackgroundColor(styles.Content.HeaderRowBackgroundColor.Color())
-		i.table.SetCell(0, col, cell)
-	}
+	i.table.SetHeaderRow(headers, styles)
 
 	for row, index := range i.indexes {
 		var definition string
@@ -212,40 +252,40 @@ func (i *Index) InsertPairedFields(pos int) {
 
 	dropdown := tview.NewDropDown().
 		SetLabel("Field Type").
-		SetOptions([]string{"1 (Ascending)", "-1 (Descending)", "text", "2dsphere"}, nil)
+		SetOptions(indexKeyTypeLabels, nil)
 
 	i.addForm.InsertFormItem(pos, inputField)
 	i.addForm.InsertFormItem(pos+1, dropdown)
+	i.keyCount++
 }
 
 func (i *Index) renderAddIndexForm() {
 	i.addForm.SetTitle("Add Index")
+	i.keyCount = 0
 	i.InsertPairedFields(0)
 
 	i.addForm.AddTextView("Optionals", strings.Repeat("─", 48), 0, 1, false, false)
-	i.addForm.AddInputField("Index Name", "", 30, nil, nil)
-	i.addForm.AddCheckbox("Unique", false, nil)
-	i.addForm.AddInputField("TTL (seconds)", "", 20, nil, nil)
+	i.addForm.AddInputField(indexNameLabel, "", 30, nil, nil)
+	i.addForm.AddCheckbox(uniqueLabel, false, nil)
+	i.addForm.AddCheckbox(sparseLabel, false, nil)
+	i.addForm.AddCheckbox(hiddenLabel, false, nil)
+	i.addForm.AddInputField(ttlLabel, "", 20, nil, nil)
+	i.addForm.AddInputField(partialFilterLabel, "", 50, nil, nil)
+	i.addForm.AddInputField(collationLocaleLabel, "", 20, nil, nil)
+	i.addForm.AddInputField(wildcardProjectionLabel, "", 50, nil, nil)
+	i.addForm.AddInputField(textWeightsLabel, "", 50, nil, nil)
+	i.addForm.AddInputField(defaultLanguageLabel, "", 20, nil, nil)
 	i.addForm.AddButton("+", i.addIndexField)
 	i.addForm.AddButton("Create", i.handleAddIndex)
 	i.addForm.AddButton("Cancel", i.closeAddForm)
 }
 
 func (i *Index) addIndexField() {
-	optionalsIndex := -1
-	for idx := 0; idx < i.addForm.GetFormItemCount(); idx++ {
-		if textView, ok := i.addForm.GetFormItem(idx).(*tview.TextView); ok && textView.GetText(true) == "----------------" {
-			optionalsIndex = idx
-			break
-		}
-	}
-	if optionalsIndex != -1 {
-		i.InsertPairedFields(optionalsIndex)
-	}
-	ttl := i.addForm.GetFormItemByLabel("TTL (seconds)")
+	i.InsertPairedFields(i.keyCount * 2)
+	ttl := i.addForm.GetFormItemByLabel(ttlLabel)
 	ttl.SetDisabled(true)
 
-	i.addForm.SetFocus(optionalsIndex)
+	i.addForm.SetFocus((i.keyCount - 1) * 2)
 	i.App.SetFocus(i.addForm)
 }
 
@@ -267,65 +307,108 @@ func (i *Index) setAutocompleteFunc(currentText string) (entries []tview.Autocom
 }
 
 func (i *Index) handleAddIndex() {
-	keys := bson.D{}
-	pairCount := ((i.addForm.GetFormItemCount()) - 4) / 2
+	definition := mongo.IndexDefinition{
+		Name:               i.formText(indexNameLabel),
+		Unique:             i.formChecked(uniqueLabel),
+		Sparse:             i.formChecked(sparseLabel),
+		Hidden:             i.formChecked(hiddenLabel),
+		PartialFilter:      i.formText(partialFilterLabel),
+		CollationLocale:    i.formText(collationLocaleLabel),
+		WildcardProjection: i.formText(wildcardProjectionLabel),
+		Weights:            i.formText(textWeightsLabel),
+		DefaultLanguage:    i.formText(defaultLanguageLabel),
+	}
 
-	for pair := 0; pair < pairCount; pair++ {
+	for pair := 0; pair < i.keyCount; pair++ {
 		formIdx := pair * 2
 		field := i.addForm.GetFormItem(formIdx).(*tview.InputField).GetText()
 		fieldType, _ := i.addForm.GetFormItem(formIdx + 1).(*tview.DropDown).GetCurrentOption()
-
-		var fieldValue any
-		switch fieldType {
-		case 0:
-			fieldValue = 1
-		case 1:
-			fieldValue = -1
-		case 2:
-			fieldValue = "text"
-		case 3:
-			fieldValue = "2dsphere"
+		if fieldType < 0 {
+			fieldType = 0
 		}
-
-		keys = append(keys, bson.E{Key: field, Value: fieldValue})
+		definition.Keys = append(definition.Keys, mongo.IndexKey{Field: field, Type: mongo.IndexKeyTypes[fieldType]})
 	}
 
-	indexName := i.addForm.GetFormItemByLabel("Index Name").(*tview.InputField).GetText()
-	unique := i.addForm.GetFormItemByLabel("Unique").(*tview.Checkbox).IsChecked()
-	ttlStr := i.addForm.GetFormItemByLabel("TTL (seconds)").(*tview.InputField).GetText()
-
-	options := options.Index()
-	if unique {
-		options.SetUnique(unique)
-	}
-	if indexName != "" {
-		options.SetName(indexName)
-	}
-	if ttlStr != "" {
-		ttl, err := strconv.Atoi(ttlStr)
+	if ttlStr := i.formText(ttlLabel); ttlStr != "" {
+		ttl, err := strconv.ParseInt(ttlStr, 10, 32)
 		if err != nil {
 			modal.ShowError(i.App.Pages, "Invalid TTL value", err)
 			return
 		}
-		options.SetExpireAfterSeconds(int32(ttl))
+		expireAfterSeconds := int32(ttl)
+		definition.ExpireAfterSeconds = &expireAfterSeconds
 	}
 
-	indexModel := mongoDriver.IndexModel{
-		Keys:    keys,
-		Options: options,
+	indexModel, err := definition.ToIndexModel()
+	if err != nil {
+		modal.ShowError(i.App.Pages, "Invalid index", err)
+		return
 	}
 
+	i.closeAddForm()
+	go i.createIndex(i.currentDB, i.currentColl, indexModel)
+}
+
+func (i *Index) formText(label string) string {
+	return strings.TrimSpace(i.addForm.GetFormItemByLabel(label).(*tview.InputField).GetText())
+}
+
+func (i *Index) formChecked(label string) bool {
+	return i.addForm.GetFormItemByLabel(label).(*tview.Checkbox).IsChecked()
+}
+
+// createIndex builds the index and shows the build progress in the title
+// until the build is finished
+func (i *Index) createIndex(db, coll string, indexModel mongoDriver.IndexModel) {
 	ctx := context.Background()
-	if err := i.Dao.CreateIndex(ctx, i.currentDB, i.currentColl, indexModel); err != nil {
-		modal.ShowError(i.App.Pages, "Error creating index", err)
-		return
+	done := make(chan error, 1)
+	go func() {
+		done <- i.Dao.CreateIndex(ctx, db, coll, indexModel)
+	}()
+
+	ticker := time.NewTicker(indexBuildPollInterval)
+	defer ticker.Stop()
+	poll := ticker.C
+	for {
+		select {
+		case err := <-done:
+			i.App.QueueUpdateDraw(func() {
+				i.SetTitle(" Indexes ")
+				if err != nil {
+					modal.ShowError(i.App.Pages, "Error creating index", err)
+					return
+				}
+				if db != i.currentDB || coll != i.currentColl {
+					return
+				}
+				if err := i.refreshIndexes(ctx); err != nil {
+					modal.ShowError(i.App.Pages, "Error refreshing indexes", err)
+				}
+			})
+			return
+		case <-poll:
+			builds, err := i.Dao.GetIndexBuilds(ctx, db, coll)
+			if err != nil {
+				// currentOp may not be permitted, the build is still awaited
+				poll = nil
+				continue
+			}
+			i.App.QueueUpdateDraw(func() {
+				i.SetTitle(formatIndexBuilds(db, coll, builds))
+			})
+		}
 	}
+}
 
-	i.closeAddForm()
-	err := i.refreshIndexes(ctx)
-	if err != nil {
-		modal.ShowError(i.App.Pages, "Error refreshing indexes", err)
+func formatIndexBuilds(db, coll string, builds []mongo.IndexBuild) string {
+	progress := "building"
+	for _, build := range builds {
+		if percent := build.Percent(); percent >= 0 {
+			progress = fmt.Sprintf("building %.0f%%", percent)
+			break
+		}
 	}
+	return fmt.Sprintf(" Indexes (%s.%s: %s) ", db, coll, progress)
 }
 
 func (i *Index) addIndexForm() {
@@ -379,6 +462,92 @@ func (i *Index) createDeleteIndexDoneFunc(indexName string, row int) func(int, s
 	}
 }
 
+// toggleHidden hides or unhides the selected index from the query planner
+func (i *Index) toggleHidden() {
+	row, _ := i.table.GetSelection()
+	if row < 1 {
+		return
+	}
+	indexName, _ := i.table.GetCell(row, 0).GetReference().(string)
+	for _, index := range i.indexes {
+		if index.Name != indexName {
+			continue
+		}
+		if index.Name == "_id_" {
+			modal.ShowInfo(i.App.Pages, "The _id index can't be hidden")
+			return
+		}
+		ctx := context.Background()
+		if err := i.Dao.SetIndexHidden(ctx, i.currentDB, i.currentColl, index.Name, !index.Hidden); err != nil {
+			modal.ShowError(i.App.Pages, "Error changing index visibility", err)
+			return
+		}
+		if err := i.refreshIndexes(ctx); err != nil {
+			modal.ShowError(i.App.Pages, "Error refreshing indexes", err)
+			return
+		}
+		i.table.Select(row, 0)
+		return
+	}
+}
+
+// toggleRecommendations shows or hides recommendations for indexes
+// of the current collection, they are loaded in the background
+// as recorded queries are explained
+func (i *Index) toggleRecommendations() {
+	i.showRecommendations = !i.showRecommendations
+	i.Render()
+	if !i.showRecommendations {
+		return
+	}
+
+	i.recommendations.Clear()
+	i.recommendations.SetCell(0, 0, tview.NewTableCell(" Loading recommendations... ").SetSelectable(false))
+
+	db, coll := i.currentDB, i.currentColl
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), recommendationsTimeout)
+		defer cancel()
+		recommendations, err := i.Dao.GetIndexRecommendations(ctx, db, coll)
+		i.App.QueueUpdateDraw(func() {
+			if db != i.currentDB || coll != i.currentColl {
+				return
+			}
+			if err != nil {
+				i.showRecommendations = false
+				i.Render()
+				modal.ShowError(i.App.Pages, "Error getting index recommendations", err)
+				return
+			}
+			i.renderRecommendations(recommendations)
+		})
+	}()
+}
+
+func (i *Index) renderRecommendations(recommendations []mongo.IndexRecommendation) {
+	styles := i.App.GetStyles()
+	i.recommendations.Clear()
+	i.recommendations.SetFixed(1, 0)
+
+	headers := []string{"Kind", "Index", "Keys", "Reason"}
+	i.recommendations.SetHeaderRow(headers, styles)
+
+	if len(recommendations) == 0 {
+		i.recommendations.SetCell(1, 0, tview.NewTableCell(" No recommendations, indexes look fine ").SetSelectable(false))
+		return
+	}
+	for row, recommendation := range recommendations {
+		index := recommendation.Index
+		if index == "" {
+			index = "-"
+		}
+		i.recommendations.SetCell(row+1, 0, tview.NewTableCell(" "+recommendation.Kind+" "))
+		i.recommendations.SetCell(row+1, 1, tview.NewTableCell(" "+tview.Escape(index)+" "))
+		i.recommendations.SetCell(row+1, 2, tview.NewTableCell(" "+tview.Escape(recommendation.Keys)+" "))
+		i.recommendations.SetCell(row+1, 3, tview.NewTableCell(" "+tview.Escape(recommendation.Reason)+" "))
+	}
+}
+
 func (i *Index) IsAddFormFocused() bool {
 	return i.isAddFormVisible
 }
@@ -76,14 +76,7 @@ func (p *Peeker) setLayout() {
 }
 
 func (p *Peeker) setStyle() {
-	style := &p.App.GetStyles().DocPeeker
-	p.ViewModal.SetStyle(p.App.GetStyles())
-	p.SetHighlightColor(style.HighlightColor.Color())
-	p.SetDocumentColors(
-		style.KeyColor.Color(),
-		style.ValueColor.Color(),
-		style.BracketColor.Color(),
-	)
+	p.ViewModal.SetPeekStyle(p.App.GetStyles())
 }
 
 func (p *Peeker) setKeybindings() {
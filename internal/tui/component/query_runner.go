@@ -0,0 +1,125 @@
+package component
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+// spinnerInterval is the time between frames of the spinner shown while a query runs
+const spinnerInterval = 100 * time.Millisecond
+
+var spinnerFrames = []rune("⠋⠙⠹⠸⠼⠴⠦⠧⠇⠏")
+
+// queryRunner runs queries in the background, so the UI stays responsive
+// and the query can be cancelled. All methods must be called from the UI goroutine
+type queryRunner struct {
+	app *core.App
+
+	// cancel cancels the context of the last query, it's kept after the query
+	// finishes, as the context may still be used by a count run next to it
+	cancel  context.CancelFunc
+	running bool
+	// generation identifies the last query, results of older queries are dropped
+	generation int
+	started    time.Time
+	frame      int
+	// lastDuration is the time taken by the last finished query
+	lastDuration time.Duration
+	cancelled    bool
+}
+
+// run cancels the previous query and runs the query in the background, onTick
+// is called on every spinner frame and done when the query finishes, both on the UI goroutine
+func (r *queryRunner) run(ctx context.Context, query func(ctx context.Context) error, onTick func(), done func(err error)) {
+	r.stop()
+	ctx, cancel := context.WithCancel(ctx)
+	r.cancel = cancel
+	r.running = true
+	r.cancelled = false
+	r.generation++
+	r.started = time.Now()
+	r.frame = 0
+	generation := r.generation
+	onTick()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- query(ctx)
+	}()
+
+	go func() {
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.app.QueueUpdateDraw(func() {
+					if r.generation == generation && r.running {
+						r.frame++
+						onTick()
+					}
+				})
+			case err := <-result:
+				r.app.QueueUpdateDraw(func() {
+					if r.generation != generation {
+						return
+					}
+					r.running = false
+					r.lastDuration = time.Since(r.started)
+					if err != nil && errors.Is(ctx.Err(), context.Canceled) {
+						err = context.Canceled
+					}
+					done(err)
+				})
+				return
+			}
+		}
+	}()
+}
+
+// Cancel cancels the running query, it returns false when no query is running
+func (r *queryRunner) Cancel() bool {
+	if !r.running {
+		return false
+	}
+	r.cancelled = true
+	r.cancel()
+	return true
+}
+
+func (r *queryRunner) stop() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+// IsRunning returns true while the query runs
+func (r *queryRunner) IsRunning() bool {
+	return r.running
+}
+
+// Status describes the running query with the spinner and elapsed time,
+// or the time taken by the last query once it's finished
+func (r *queryRunner) Status() string {
+	switch {
+	case r.running:
+		frame := spinnerFrames[r.frame%len(spinnerFrames)]
+		return fmt.Sprintf("%c Running %s", frame, formatDuration(time.Since(r.started)))
+	case r.cancelled:
+		return "Cancelled"
+	case !r.started.IsZero():
+		return fmt.Sprintf("Took: %s", formatDuration(r.lastDuration))
+	}
+	return ""
+}
+
+func formatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	return d.Round(100 * time.Millisecond).String()
+}
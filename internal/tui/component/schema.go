@@ -0,0 +1,262 @@
+package component
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SchemaId = "Schema"
+
+	// schemaValueWidth is the maximum width of a single value shown in the table
+	schemaValueWidth = 30
+)
+
+// schemaSampleSizes are the sample sizes the user can switch between
+var schemaSampleSizes = []int64{100, 500, 1000, 5000}
+
+// Schema is a view that shows the schema inferred from sampled documents
+type Schema struct {
+	*core.BaseElement
+	*core.Flex
+
+	header *core.TextView
+	table  *core.Table
+
+	fields      []mongo.FieldSchema
+	sampled     int
+	sampleSize  int64
+	currentDB   string
+	currentColl string
+	// stale is true when the selected collection wasn't sampled yet
+	stale bool
+	// generation identifies the last sampling, results of older ones are dropped
+	generation int
+	loading    bool
+}
+
+func NewSchema() *Schema {
+	s := &Schema{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		header:      core.NewTextView(),
+		table:       core.NewTable(),
+		sampleSize:  schemaSampleSizes[0],
+	}
+
+	s.SetIdentifier(SchemaId)
+	s.SetAfterInitFunc(s.init)
+
+	return s
+}
+
+func (s *Schema) init() error {
+	s.setLayout()
+	s.setStyle()
+	s.setKeybindings()
+
+	s.handleEvents()
+
+	return nil
+}
+
+func (s *Schema) setLayout() {
+	s.SetBorder(true)
+	s.SetTitle(" Schema ")
+	s.SetTitleAlign(tview.AlignCenter)
+	s.SetBorderPadding(0, 0, 1, 1)
+	s.SetDirection(tview.FlexRow)
+	s.table.SetSelectable(true, false)
+
+	s.AddItem(s.header, 1, 0, false)
+	s.AddItem(s.table, 0, 1, true)
+}
+
+func (s *Schema) setStyle() {
+	styles := s.App.GetStyles()
+	s.SetStyle(styles)
+	s.header.SetStyle(styles)
+	s.table.SetStyle(styles)
+
+	s.header.SetTextColor(styles.Content.StatusTextColor.Color())
+	s.table.SetSeparator(styles.Others.SeparatorSymbol.Rune())
+	s.table.SetBordersColor(styles.Others.SeparatorColor.Color())
+}
+
+func (s *Schema) setKeybindings() {
+	k := s.App.GetKeys()
+	s.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case k.Contains(k.Navigation.MoveUp, event.Name()):
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case k.Contains(k.Navigation.MoveDown, event.Name()):
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		}
+		return event
+	})
+	s.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case k.Contains(k.Schema.Refresh, event.Name()):
+			s.refresh()
+			return nil
+		case k.Contains(k.Schema.ChangeSampleSize, event.Name()):
+			s.nextSampleSize()
+			s.refresh()
+			return nil
+		}
+		return event
+	})
+}
+
+func (s *Schema) handleEvents() {
+	go s.HandleEvents(SchemaId, func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			s.setStyle()
+			s.Render()
+		}
+	})
+}
+
+// HandleDatabaseSelection selects the collection, documents are sampled
+// once the Schema tab is activated, not on every selection
+func (s *Schema) HandleDatabaseSelection(ctx context.Context, db, coll string) error {
+	s.currentDB = db
+	s.currentColl = coll
+	s.fields = nil
+	s.sampled = 0
+	s.stale = true
+	s.generation++
+	s.loading = false
+	s.Render()
+	return nil
+}
+
+// Focus samples the selected collection when the tab is activated
+func (s *Schema) Focus(delegate func(p tview.Primitive)) {
+	if s.stale {
+		s.refresh()
+	}
+	s.Flex.Focus(delegate)
+}
+
+// refresh samples the selected collection in the background
+func (s *Schema) refresh() {
+	if s.currentColl == "" {
+		return
+	}
+	s.stale = false
+	s.loading = true
+	s.generation++
+	generation := s.generation
+	db, coll, sampleSize := s.currentDB, s.currentColl, s.sampleSize
+	s.Render()
+
+	go func() {
+		docs, err := s.Dao.SampleDocuments(context.Background(), db, coll, sampleSize)
+		s.App.QueueUpdateDraw(func() {
+			if s.generation != generation {
+				return
+			}
+			s.loading = false
+			if err != nil {
+				s.Render()
+				modal.ShowError(s.App.Pages, "Error analyzing schema", err)
+				return
+			}
+			s.analyze(docs)
+		})
+	}()
+}
+
+func (s *Schema) nextSampleSize() {
+	for i, size := range schemaSampleSizes {
+		if size == s.sampleSize {
+			s.sampleSize = schemaSampleSizes[(i+1)%len(schemaSampleSizes)]
+			return
+		}
+	}
+	s.sampleSize = schemaSampleSizes[0]
+}
+
+func (s *Schema) analyze(docs []primitive.M) {
+	s.fields = mongo.AnalyzeSchema(docs)
+	s.sampled = len(docs)
+	s.Render()
+
+	// sampled paths are richer than keys from the current page of documents
+	s.App.GetManager().Broadcast(manager.EventMsg{
+		Sender:  s.GetIdentifier(),
+		Message: manager.Message{Type: manager.UpdateAutocompleteKeys, Data: mongo.SchemaPaths(s.fields)},
+	})
+}
+
+func (s *Schema) Render() {
+	s.table.Clear()
+	if s.currentColl == "" {
+		s.header.SetText("")
+		return
+	}
+	if s.loading {
+		s.header.SetText(fmt.Sprintf("Sampling %d documents...", s.sampleSize))
+		return
+	}
+
+	s.header.SetText(fmt.Sprintf("Sampled %d documents (sample size %d), %d field paths",
+		s.sampled, s.sampleSize, len(s.fields)))
+	s.renderSchemaTable()
+}
+
+func (s *Schema) renderSchemaTable() {
+	styles := s.App.GetStyles()
+	s.table.SetFixed(1, 0)
+
+	headers := []string{"Path", "Types", "Presence", "Min", "Max", "Top values"}
+	s.table.SetHeaderRow(headers, styles)
+
+	for row, field := range s.fields {
+		types := make([]string, 0, len(field.Types))
+		for _, typeStat := range field.Types {
+			types = append(types, fmt.Sprintf("%s %.0f%%", typeStat.Type, typeStat.Percent))
+		}
+
+		var minValue, maxValue string
+		if field.Min != nil {
+			minValue = util.StringifyMongoValueByType(field.Min)
+			maxValue = util.StringifyMongoValueByType(field.Max)
+		}
+
+		topValues := make([]string, 0, len(field.TopValues))
+		for _, value := range field.TopValues {
+			topValues = append(topValues, fmt.Sprintf("%s (%d)", tview.Escape(truncateSchemaValue(value.Value)), value.Count))
+		}
+
+		s.table.SetCell(row+1, 0, tview.NewTableCell(" "+tview.Escape(field.Path)+" ").
+			SetReference(field.Path))
+		s.table.SetCell(row+1, 1, tview.NewTableCell(" "+strings.Join(types, ", ")+" ").
+			SetTextColor(styles.Content.ColumnTypeColor.Color()))
+		s.table.SetCell(row+1, 2, tview.NewTableCell(fmt.Sprintf(" %.0f%% ", field.Presence*100)).
+			SetAlign(tview.AlignRight))
+		s.table.SetCell(row+1, 3, tview.NewTableCell(" "+minValue+" "))
+		s.table.SetCell(row+1, 4, tview.NewTableCell(" "+maxValue+" "))
+		s.table.SetCell(row+1, 5, tview.NewTableCell(" "+strings.Join(topValues, ", ")+" "))
+	}
+}
+
+func truncateSchemaValue(value string) string {
+	runes := []rune(value)
+	if len(runes) <= schemaValueWidth {
+		return value
+	}
+	return string(runes[:schemaValueWidth-3]) + "..."
+}
@@ -95,6 +95,18 @@ func (t *TabBar) PreviousTab() {
 	t.Render()
 }
 
+// SetActiveTab activates the tab with given name, returns false when there is no such tab
+func (t *TabBar) SetActiveTab(name string) bool {
+	for i, tab := range t.tabs {
+		if tab.id == name {
+			t.active = i
+			t.Render()
+			return true
+		}
+	}
+	return false
+}
+
 func (t *TabBar) Render() {
 	styles := t.App.GetStyles()
 	t.Clear()
@@ -133,6 +133,17 @@ func (v *ViewModal) SetStyle(style *config.Styles) {
 	SetCommonStyle(v.ViewModal, style)
 }
 
+// SetPeekStyle styles the modal like the document peeker
+func (v *ViewModal) SetPeekStyle(style *config.Styles) {
+	v.SetStyle(style)
+	v.SetHighlightColor(style.DocPeeker.HighlightColor.Color())
+	v.SetDocumentColors(
+		style.DocPeeker.KeyColor.Color(),
+		style.DocPeeker.ValueColor.Color(),
+		style.DocPeeker.BracketColor.Color(),
+	)
+}
+
 func (l *ListModal) SetStyle(style *config.Styles) {
 	SetCommonStyle(l.ListModal, style)
 }
@@ -23,6 +23,17 @@ func (t *Table) SetStyle(style *config.Styles) {
 	t.SetFocusStyle(tcell.StyleDefault.Foreground(style.Global.FocusColor.Color()).Background(style.Global.BackgroundColor.Color()))
 }
 
+// SetHeaderRow sets the first row of the table to non selectable column headers
+func (t *Table) SetHeaderRow(headers []string, style *config.Styles) {
+	for col, header := range headers {
+		t.SetCell(0, col, tview.NewTableCell(" "+header+" ").
+			SetSelectable(false).
+			SetAlign(tview.AlignCenter).
+			SetTextColor(style.Content.ColumnKeyColor.Color()).
+			SetBackgroundColor(style.Content.HeaderRowBackgroundColor.Color()))
+	}
+}
+
 // MoveUpUntil moves the selection up until a condition is met
 func (t *Table) MoveUpUntil(row, col int, condition func(cell *tview.TableCell) bool) {
 	for row > 0 {
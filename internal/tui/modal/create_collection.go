@@ -0,0 +1,291 @@
+package modal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	CreateCollectionModalId = "CreateCollectionModal"
+)
+
+// collectionKinds are the options of the type dropdown, capped and clustered
+// are regular collections with additional options
+var collectionKinds = []string{"Collection", "Capped", "Clustered", "Time series", "View"}
+
+const (
+	kindCollection = iota
+	kindCapped
+	kindClustered
+	kindTimeSeries
+	kindView
+)
+
+const (
+	nameLabel        = "Name"
+	typeLabel        = "Type"
+	sizeLabel        = "Size (bytes)"
+	maxDocsLabel     = "Max documents"
+	timeFieldLabel   = "Time field"
+	metaFieldLabel   = "Meta field"
+	granularityLabel = "Granularity"
+	expireLabel      = "Expire after seconds"
+	viewOnLabel      = "Source collection"
+	pipelineLabel    = "Pipeline"
+	collationLabel   = "Collation locale"
+	defaultOption    = "default"
+)
+
+// CreateCollectionFunc creates the collection with given name and options
+type CreateCollectionFunc func(name string, opts mongo.CollectionOptions) error
+
+// CreateCollectionModal is a form for creating collections, capped, clustered
+// and time-series collections and views, fields depend on the chosen type
+type CreateCollectionModal struct {
+	*core.BaseElement
+	*core.FormModal
+
+	typeDropDown *tview.DropDown
+	kind         int
+	// values keeps entered values when fields are rebuilt after type change
+	values     map[string]string
+	createFunc CreateCollectionFunc
+}
+
+func NewCreateCollectionModal() *CreateCollectionModal {
+	cm := &CreateCollectionModal{
+		BaseElement:  core.NewBaseElement(),
+		FormModal:    core.NewFormModal(),
+		typeDropDown: tview.NewDropDown(),
+		values:       map[string]string{},
+	}
+
+	cm.SetIdentifier(CreateCollectionModalId)
+	cm.SetAfterInitFunc(cm.init)
+	return cm
+}
+
+func (cm *CreateCollectionModal) init() error {
+	cm.setLayout()
+	cm.setStyle()
+	cm.setKeybindings()
+	cm.handleEvents()
+
+	return nil
+}
+
+func (cm *CreateCollectionModal) setLayout() {
+	cm.SetBorder(true)
+	cm.SetTitleAlign(tview.AlignCenter)
+	cm.Form.SetBorderPadding(2, 2, 2, 2)
+
+	cm.typeDropDown.SetLabel(typeLabel)
+	cm.typeDropDown.SetOptions(collectionKinds, func(_ string, index int) {
+		cm.changeKind(index)
+	})
+}
+
+func (cm *CreateCollectionModal) setStyle() {
+	styles := cm.App.GetStyles()
+	cm.SetStyle(styles)
+
+	cm.Form.SetFieldTextColor(styles.Connection.FormInputColor.Color())
+	cm.Form.SetFieldBackgroundColor(styles.Connection.FormInputBackgroundColor.Color())
+	cm.Form.SetLabelColor(styles.Connection.FormLabelColor.Color())
+}
+
+func (cm *CreateCollectionModal) setKeybindings() {
+	cm.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			cm.Hide()
+			return nil
+		}
+
+		return event
+	})
+}
+
+func (cm *CreateCollectionModal) handleEvents() {
+	go cm.HandleEvents(cm.GetIdentifier(), func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			cm.setStyle()
+		}
+	})
+}
+
+// SetCreateFunc sets the function that creates the collection
+func (cm *CreateCollectionModal) SetCreateFunc(createFunc CreateCollectionFunc) {
+	cm.createFunc = createFunc
+}
+
+// Render shows an empty form for creating a collection in given database
+func (cm *CreateCollectionModal) Render(db string) {
+	cm.SetTitle(fmt.Sprintf(" Add collection to %s ", db))
+	cm.values = map[string]string{}
+	cm.kind = kindCollection
+	cm.typeDropDown.SetCurrentOption(kindCollection)
+	cm.renderForm()
+	cm.Form.SetFocus(0)
+
+	cm.Show()
+}
+
+func (cm *CreateCollectionModal) changeKind(kind int) {
+	if kind == cm.kind {
+		return
+	}
+	cm.kind = kind
+	cm.renderForm()
+	// keep the focus on the type dropdown after the fields are rebuilt
+	cm.Form.SetFocus(1)
+}
+
+func (cm *CreateCollectionModal) renderForm() {
+	cm.Form.Clear(true)
+
+	cm.addInput(nameLabel, 40, false)
+	cm.Form.AddFormItem(cm.typeDropDown)
+
+	switch cm.kind {
+	case kindCapped:
+		cm.addInput(sizeLabel, 20, true)
+		cm.addInput(maxDocsLabel, 20, true)
+	case kindClustered:
+		cm.addInput(expireLabel, 20, true)
+	case kindTimeSeries:
+		cm.addInput(timeFieldLabel, 30, false)
+		cm.addInput(metaFieldLabel, 30, false)
+		cm.addGranularityDropDown()
+		cm.addInput(expireLabel, 20, true)
+	case kindView:
+		cm.addInput(viewOnLabel, 40, false)
+		cm.addInput(pipelineLabel, 60, false)
+	}
+	cm.addInput(collationLabel, 20, false)
+
+	cm.Form.AddButton("Create", func() {
+		cm.handleCreate()
+	})
+	cm.Form.AddButton("Cancel", func() {
+		cm.Hide()
+	})
+}
+
+func (cm *CreateCollectionModal) addInput(label string, width int, numeric bool) {
+	var accept func(string, rune) bool
+	if numeric {
+		accept = func(textToCheck string, lastChar rune) bool {
+			_, err := strconv.ParseInt(textToCheck, 10, 64)
+			return err == nil || textToCheck == ""
+		}
+	}
+	cm.Form.AddInputField(label, cm.values[label], width, accept, func(text string) {
+		cm.values[label] = text
+	})
+}
+
+func (cm *CreateCollectionModal) addGranularityDropDown() {
+	options := append([]string{defaultOption}, mongo.TimeSeriesGranularities...)
+	current := 0
+	for i, option := range options {
+		if option == cm.values[granularityLabel] {
+			current = i
+		}
+	}
+	cm.Form.AddDropDown(granularityLabel, options, current, func(option string, _ int) {
+		cm.values[granularityLabel] = option
+	})
+}
+
+func (cm *CreateCollectionModal) handleCreate() {
+	name := strings.TrimSpace(cm.values[nameLabel])
+	if name == "" {
+		ShowError(cm.App.Pages, "Collection name is required", nil)
+		return
+	}
+	opts, err := cm.collectionOptions()
+	if err != nil {
+		ShowError(cm.App.Pages, "Invalid collection options", err)
+		return
+	}
+	if err := opts.Validate(); err != nil {
+		ShowError(cm.App.Pages, "Invalid collection options", err)
+		return
+	}
+	if cm.createFunc == nil {
+		return
+	}
+	if err := cm.createFunc(name, opts); err != nil {
+		ShowError(cm.App.Pages, "Error adding collection", err)
+		return
+	}
+	cm.Hide()
+}
+
+// collectionOptions converts values of the form into collection options
+func (cm *CreateCollectionModal) collectionOptions() (mongo.CollectionOptions, error) {
+	opts := mongo.CollectionOptions{
+		Type:            mongo.CollectionTypeCollection,
+		CollationLocale: cm.values[collationLabel],
+	}
+	var err error
+	switch cm.kind {
+	case kindCapped:
+		opts.Capped = true
+		if opts.SizeInBytes, err = cm.intValue(sizeLabel); err != nil {
+			return opts, err
+		}
+		if opts.MaxDocuments, err = cm.intValue(maxDocsLabel); err != nil {
+			return opts, err
+		}
+	case kindClustered:
+		opts.Clustered = true
+		if opts.ExpireAfterSeconds, err = cm.intValue(expireLabel); err != nil {
+			return opts, err
+		}
+	case kindTimeSeries:
+		opts.Type = mongo.CollectionTypeTimeSeries
+		opts.TimeField = cm.values[timeFieldLabel]
+		opts.MetaField = cm.values[metaFieldLabel]
+		if granularity := cm.values[granularityLabel]; granularity != defaultOption {
+			opts.Granularity = granularity
+		}
+		if opts.ExpireAfterSeconds, err = cm.intValue(expireLabel); err != nil {
+			return opts, err
+		}
+	case kindView:
+		opts.Type = mongo.CollectionTypeView
+		opts.ViewOn = strings.TrimSpace(cm.values[viewOnLabel])
+		opts.Pipeline = cm.values[pipelineLabel]
+	}
+	return opts, nil
+}
+
+func (cm *CreateCollectionModal) intValue(label string) (int64, error) {
+	text := strings.TrimSpace(cm.values[label])
+	if text == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", strings.ToLower(label), err)
+	}
+	return value, nil
+}
+
+func (cm *CreateCollectionModal) Show() {
+	cm.App.Pages.AddPage(CreateCollectionModalId, cm, true, true)
+}
+
+func (cm *CreateCollectionModal) Hide() {
+	cm.App.Pages.RemovePage(CreateCollectionModalId)
+}
@@ -0,0 +1,142 @@
+package modal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	DeleteManyModalId = "DeleteManyModal"
+)
+
+// DeleteManyModal asks to type the collection name or the number of documents
+// before deleting all documents matching the filter
+type DeleteManyModal struct {
+	*core.BaseElement
+	*core.FormModal
+
+	message    *tview.TextView
+	expected   []string
+	deleteFunc func()
+}
+
+func NewDeleteManyModal() *DeleteManyModal {
+	dm := &DeleteManyModal{
+		BaseElement: core.NewBaseElement(),
+		FormModal:   core.NewFormModal(),
+		message:     tview.NewTextView(),
+	}
+
+	dm.SetIdentifier(DeleteManyModalId)
+	dm.SetAfterInitFunc(dm.init)
+	return dm
+}
+
+func (dm *DeleteManyModal) init() error {
+	dm.setLayout()
+	dm.setStyle()
+	dm.setKeybindings()
+	dm.handleEvents()
+
+	return nil
+}
+
+func (dm *DeleteManyModal) setLayout() {
+	dm.SetTitle(" Delete matching documents ")
+	dm.SetBorder(true)
+	dm.SetTitleAlign(tview.AlignCenter)
+	dm.Form.SetBorderPadding(2, 2, 2, 2)
+
+	dm.message.SetDynamicColors(true)
+	dm.message.SetWrap(true)
+	dm.message.SetSize(3, 0)
+}
+
+func (dm *DeleteManyModal) setStyle() {
+	styles := dm.App.GetStyles()
+	dm.SetStyle(styles)
+
+	dm.Form.SetFieldTextColor(styles.Connection.FormInputColor.Color())
+	dm.Form.SetFieldBackgroundColor(styles.Connection.FormInputBackgroundColor.Color())
+	dm.Form.SetLabelColor(styles.Connection.FormLabelColor.Color())
+	dm.Form.SetButtonActivatedStyle(tcell.StyleDefault.
+		Background(styles.Others.DeleteButtonSelectedBackgroundColor.Color()))
+	dm.message.SetTextColor(styles.Others.ModalTextColor.Color())
+	dm.message.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+}
+
+func (dm *DeleteManyModal) setKeybindings() {
+	dm.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			dm.Hide()
+			return nil
+		}
+
+		return event
+	})
+}
+
+func (dm *DeleteManyModal) handleEvents() {
+	go dm.HandleEvents(dm.GetIdentifier(), func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			dm.setStyle()
+		}
+	})
+}
+
+// Render shows the modal, deleteFunc is called only when typed text
+// matches the collection name or the number of documents
+func (dm *DeleteManyModal) Render(coll string, count int64, message string, deleteFunc func()) {
+	dm.Form.Clear(true)
+	dm.expected = []string{coll, strconv.FormatInt(count, 10)}
+	dm.deleteFunc = deleteFunc
+
+	dm.message.SetText(fmt.Sprintf("%s\nType [::b]%s[::-] or [::b]%d[::-] to confirm.", message, tview.Escape(coll), count))
+	dm.Form.AddFormItem(dm.message)
+	dm.Form.AddInputField("Confirm", "", 30, nil, nil)
+	dm.Form.GetFormItemByLabel("Confirm").(*tview.InputField).SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			dm.handleDelete()
+		}
+	})
+
+	dm.Form.AddButton("Delete", func() {
+		dm.handleDelete()
+	})
+	dm.Form.AddButton("Cancel", func() {
+		dm.Hide()
+	})
+
+	dm.Form.SetFocus(1)
+	dm.Show()
+}
+
+func (dm *DeleteManyModal) handleDelete() {
+	typed := strings.TrimSpace(dm.Form.GetFormItemByLabel("Confirm").(*tview.InputField).GetText())
+	for _, expected := range dm.expected {
+		if typed == expected {
+			dm.Hide()
+			if dm.deleteFunc != nil {
+				dm.deleteFunc()
+			}
+			return
+		}
+	}
+	ShowError(dm.App.Pages, "Confirmation does not match", fmt.Errorf("type %s or %s to delete documents", dm.expected[0], dm.expected[1]))
+}
+
+func (dm *DeleteManyModal) Show() {
+	dm.App.Pages.AddPage(DeleteManyModalId, dm, true, true)
+}
+
+func (dm *DeleteManyModal) Hide() {
+	dm.App.Pages.RemovePage(DeleteManyModalId)
+}
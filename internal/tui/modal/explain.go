@@ -0,0 +1,203 @@
+package modal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/config"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	ExplainModalId = "ExplainModal"
+)
+
+// ExplainModal shows the query plan returned by explain as a tree
+type ExplainModal struct {
+	*core.BaseElement
+	*core.Flex
+
+	summary *core.TextView
+	tree    *core.TreeView
+	style   *config.Styles
+}
+
+func NewExplainModal() *ExplainModal {
+	em := &ExplainModal{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		summary:     core.NewTextView(),
+		tree:        core.NewTreeView(),
+	}
+
+	em.SetIdentifier(ExplainModalId)
+	em.SetAfterInitFunc(em.init)
+	return em
+}
+
+func (em *ExplainModal) init() error {
+	em.setLayout()
+	em.setStyle()
+	em.setKeybindings()
+	em.handleEvents()
+
+	return nil
+}
+
+func (em *ExplainModal) setLayout() {
+	em.SetBorder(true)
+	em.SetTitle(" Explain ")
+	em.SetTitleAlign(tview.AlignCenter)
+	em.SetBorderPadding(0, 0, 1, 1)
+	em.SetDirection(tview.FlexRow)
+
+	em.summary.SetDynamicColors(true)
+	em.tree.SetGraphics(true)
+	em.tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+
+	em.AddItem(em.summary, 4, 0, false)
+	em.AddItem(em.tree, 0, 1, true)
+}
+
+func (em *ExplainModal) setStyle() {
+	em.style = em.App.GetStyles()
+	em.Flex.SetStyle(em.style)
+	em.summary.SetStyle(em.style)
+	em.tree.SetStyle(em.style)
+	em.tree.SetGraphicsColor(em.style.Global.GraphicsColor.Color())
+}
+
+func (em *ExplainModal) setKeybindings() {
+	k := em.App.GetKeys()
+	em.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEsc:
+			em.Hide()
+			return nil
+		case k.Contains(k.Navigation.MoveUp, event.Name()):
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case k.Contains(k.Navigation.MoveDown, event.Name()):
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		}
+		return event
+	})
+}
+
+func (em *ExplainModal) handleEvents() {
+	go em.HandleEvents(em.GetIdentifier(), func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			em.setStyle()
+		}
+	})
+}
+
+// Render shows the summary of the explain and the plan tree
+func (em *ExplainModal) Render(title string, plan *mongo.ExplainPlan) {
+	em.SetTitle(fmt.Sprintf(" Explain %s ", title))
+	em.summary.SetText(em.summaryText(plan))
+
+	root := tview.NewTreeNode(tview.Escape(plan.Namespace)).
+		SetColor(em.style.Global.SecondaryTextColor.Color()).
+		SetSelectable(false)
+
+	winning := em.sectionNode("Winning plan")
+	if plan.WinningPlan != nil {
+		winning.AddChild(em.stageNode(plan.WinningPlan))
+	}
+	root.AddChild(winning)
+
+	if len(plan.PipelineStages) > 0 {
+		pipeline := em.sectionNode("Pipeline stages")
+		for _, stage := range plan.PipelineStages {
+			text := fmt.Sprintf("%s | returned: %d, time: %dms", stage.Name, stage.NReturned, stage.ExecutionTimeMillis)
+			pipeline.AddChild(tview.NewTreeNode(tview.Escape(text)).SetColor(em.style.Global.TextColor.Color()))
+		}
+		root.AddChild(pipeline)
+	}
+
+	rejected := em.sectionNode(fmt.Sprintf("Rejected plans (%d)", len(plan.RejectedPlans)))
+	for _, rejectedPlan := range plan.RejectedPlans {
+		rejected.AddChild(em.stageNode(rejectedPlan))
+	}
+	rejected.SetExpanded(false)
+	root.AddChild(rejected)
+
+	em.tree.SetRoot(root)
+	em.tree.SetCurrentNode(winning)
+
+	em.Show()
+}
+
+func (em *ExplainModal) summaryText(plan *mongo.ExplainPlan) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Returned: %d | Keys examined: %d | Docs examined: %d | Time: %dms\n",
+		plan.NReturned, plan.TotalKeysExamined, plan.TotalDocsExamined, plan.ExecutionTimeMillis)
+
+	indexes := plan.UsedIndexes()
+	if len(indexes) > 0 {
+		fmt.Fprintf(&b, "Indexes used: %s\n", tview.Escape(strings.Join(indexes, ", ")))
+	} else {
+		b.WriteString("Indexes used: none\n")
+	}
+	if plan.HasCollectionScan() {
+		fmt.Fprintf(&b, "[%s]Winning plan scans the whole collection[-]\n", em.style.Others.DeleteButtonSelectedBackgroundColor.Color())
+	}
+	return b.String()
+}
+
+func (em *ExplainModal) sectionNode(text string) *tview.TreeNode {
+	return tview.NewTreeNode(text).
+		SetColor(em.style.Global.SecondaryTextColor.Color()).
+		SetExpanded(true)
+}
+
+func (em *ExplainModal) stageNode(stage *mongo.PlanStage) *tview.TreeNode {
+	node := tview.NewTreeNode(tview.Escape(planStageText(stage))).
+		SetColor(em.style.Global.TextColor.Color()).
+		SetExpanded(true)
+	if stage.Stage == "COLLSCAN" {
+		node.SetColor(em.style.Others.DeleteButtonSelectedBackgroundColor.Color())
+	}
+	for _, child := range stage.Children {
+		node.AddChild(em.stageNode(child))
+	}
+	return node
+}
+
+func planStageText(stage *mongo.PlanStage) string {
+	parts := []string{stage.Stage}
+	if stage.IndexName != "" {
+		parts = append(parts, fmt.Sprintf("index: %s %s", stage.IndexName, stage.KeyPattern))
+	}
+	if stage.Filter != "" {
+		parts = append(parts, "filter: "+stage.Filter)
+	}
+	if stage.HasStats {
+		parts = append(parts, fmt.Sprintf("returned: %d, keys examined: %d, docs examined: %d, time: %dms",
+			stage.NReturned, stage.KeysExamined, stage.DocsExamined, stage.ExecutionTimeMillis))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// Draw draws the modal in the center of the screen
+func (em *ExplainModal) Draw(screen tcell.Screen) {
+	screenWidth, screenHeight := screen.Size()
+	width, height := screenWidth*3/4, screenHeight*3/4
+	em.Flex.SetRect((screenWidth-width)/2, (screenHeight-height)/2, width, height)
+	em.Flex.Draw(screen)
+}
+
+func (em *ExplainModal) Show() {
+	em.App.Pages.AddPage(ExplainModalId, em, true, true)
+}
+
+func (em *ExplainModal) Hide() {
+	em.App.Pages.RemovePage(ExplainModalId)
+}
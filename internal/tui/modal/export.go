@@ -0,0 +1,223 @@
+package modal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	ExportModalId = "ExportModal"
+)
+
+// ExportFunc exports documents to the file at path, progress should be
+// called after every written document
+type ExportFunc func(ctx context.Context, format mongo.FileFormat, path string, progress func(written, total int64)) (int64, error)
+
+// ExportModal is a form for choosing export format and destination file,
+// it shows the progress of running export and allows to cancel it
+type ExportModal struct {
+	*core.BaseElement
+	*core.FormModal
+
+	progress   *tview.TextView
+	exportFunc ExportFunc
+	cancel     context.CancelFunc
+}
+
+func NewExportModal() *ExportModal {
+	em := &ExportModal{
+		BaseElement: core.NewBaseElement(),
+		FormModal:   core.NewFormModal(),
+		progress:    tview.NewTextView(),
+	}
+
+	em.SetIdentifier(ExportModalId)
+	em.SetAfterInitFunc(em.init)
+	return em
+}
+
+func (em *ExportModal) init() error {
+	em.setLayout()
+	em.setStyle()
+	em.setKeybindings()
+	em.handleEvents()
+
+	return nil
+}
+
+func (em *ExportModal) setLayout() {
+	em.SetTitle(" Export Documents ")
+	em.SetBorder(true)
+	em.SetTitleAlign(tview.AlignCenter)
+	em.Form.SetBorderPadding(2, 2, 2, 2)
+
+	em.progress.SetDynamicColors(true)
+}
+
+func (em *ExportModal) setStyle() {
+	styles := em.App.GetStyles()
+	em.SetStyle(styles)
+
+	em.Form.SetFieldTextColor(styles.Connection.FormInputColor.Color())
+	em.Form.SetFieldBackgroundColor(styles.Connection.FormInputBackgroundColor.Color())
+	em.Form.SetLabelColor(styles.Connection.FormLabelColor.Color())
+	em.progress.SetTextColor(styles.Others.ModalTextColor.Color())
+	em.progress.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+}
+
+func (em *ExportModal) setKeybindings() {
+	em.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			em.handleCancel()
+			return nil
+		}
+
+		return event
+	})
+}
+
+func (em *ExportModal) handleEvents() {
+	go em.HandleEvents(em.GetIdentifier(), func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			em.setStyle()
+		}
+	})
+}
+
+// SetExportFunc sets the function that runs the export
+func (em *ExportModal) SetExportFunc(exportFunc ExportFunc) {
+	em.exportFunc = exportFunc
+}
+
+// Render shows the form with the default file name based on the collection name
+func (em *ExportModal) Render(collection string) {
+	if em.cancel != nil {
+		em.Show()
+		return
+	}
+
+	em.Form.Clear(true)
+	em.progress.SetText("")
+
+	formats := make([]string, len(mongo.ExportFormats))
+	for i, format := range mongo.ExportFormats {
+		formats[i] = string(format)
+	}
+
+	em.Form.AddInputField("File", collection+mongo.FormatJSON.Extension(), 50, nil, nil)
+	em.Form.AddDropDown("Format", formats, 0, func(option string, _ int) {
+		em.replaceFileExtension(mongo.FileFormat(option))
+	})
+	em.progress.SetLabel("Progress")
+	em.Form.AddFormItem(em.progress)
+
+	em.Form.AddButton("Export", func() {
+		em.handleExport()
+	})
+	em.Form.AddButton("Cancel", func() {
+		em.handleCancel()
+	})
+
+	em.Show()
+}
+
+func (em *ExportModal) replaceFileExtension(format mongo.FileFormat) {
+	fileInput, ok := em.Form.GetFormItemByLabel("File").(*tview.InputField)
+	if !ok {
+		return
+	}
+	path := fileInput.GetText()
+	for _, f := range mongo.ExportFormats {
+		if strings.HasSuffix(path, f.Extension()) {
+			path = strings.TrimSuffix(path, f.Extension())
+			break
+		}
+	}
+	fileInput.SetText(path + format.Extension())
+}
+
+func (em *ExportModal) handleExport() {
+	if em.cancel != nil || em.exportFunc == nil {
+		return
+	}
+
+	path := strings.TrimSpace(em.Form.GetFormItemByLabel("File").(*tview.InputField).GetText())
+	if path == "" {
+		ShowError(em.App.Pages, "Invalid file path", fmt.Errorf("file path cannot be empty"))
+		return
+	}
+	_, option := em.Form.GetFormItemByLabel("Format").(*tview.DropDown).GetCurrentOption()
+	format := mongo.FileFormat(option)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	em.cancel = cancel
+	em.progress.SetText("Starting...")
+
+	go func() {
+		progress := func(written, total int64) {
+			// redrawing after every document would slow down the export
+			if written%500 != 0 && written != total {
+				return
+			}
+			em.App.QueueUpdateDraw(func() {
+				em.progress.SetText(formatExportProgress(written, total))
+			})
+		}
+
+		written, err := em.exportFunc(ctx, format, path, progress)
+
+		em.App.QueueUpdateDraw(func() {
+			em.cancel = nil
+			cancel()
+			switch {
+			case errors.Is(err, context.Canceled):
+				em.progress.SetText(fmt.Sprintf("Cancelled after %d documents", written))
+			case err != nil:
+				em.progress.SetText("")
+				ShowError(em.App.Pages, "Error exporting documents", err)
+			default:
+				em.Hide()
+				if absPath, err := filepath.Abs(path); err == nil {
+					path = absPath
+				}
+				ShowInfo(em.App.Pages, fmt.Sprintf("Exported %d documents to %s", written, path))
+			}
+		})
+	}()
+}
+
+// handleCancel stops running export, if there is none it closes the modal
+func (em *ExportModal) handleCancel() {
+	if em.cancel != nil {
+		em.progress.SetText("Cancelling...")
+		em.cancel()
+		return
+	}
+	em.Hide()
+}
+
+func formatExportProgress(written, total int64) string {
+	if total <= 0 {
+		return fmt.Sprintf("%d documents", written)
+	}
+	return fmt.Sprintf("%d / %d documents (%d%%)", written, total, written*100/total)
+}
+
+func (em *ExportModal) Show() {
+	em.App.Pages.AddPage(ExportModalId, em, true, true)
+}
+
+func (em *ExportModal) Hide() {
+	em.App.Pages.RemovePage(ExportModalId)
+}
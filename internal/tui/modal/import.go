@@ -0,0 +1,234 @@
+package modal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+)
+
+const (
+	ImportModalId = "ImportModal"
+)
+
+// ImportFunc imports documents from the file at path, progress should be called after every batch
+type ImportFunc func(ctx context.Context, path string, opts mongo.ImportOptions, progress func(mongo.ImportResult)) (mongo.ImportResult, error)
+
+// ImportModal is a form for choosing the file and import options,
+// it shows the progress of running import and allows to cancel it
+type ImportModal struct {
+	*core.BaseElement
+	*core.FormModal
+
+	progress   *tview.TextView
+	importFunc ImportFunc
+	cancel     context.CancelFunc
+}
+
+func NewImportModal() *ImportModal {
+	im := &ImportModal{
+		BaseElement: core.NewBaseElement(),
+		FormModal:   core.NewFormModal(),
+		progress:    tview.NewTextView(),
+	}
+
+	im.SetIdentifier(ImportModalId)
+	im.SetAfterInitFunc(im.init)
+	return im
+}
+
+func (im *ImportModal) init() error {
+	im.setLayout()
+	im.setStyle()
+	im.setKeybindings()
+	im.handleEvents()
+
+	return nil
+}
+
+func (im *ImportModal) setLayout() {
+	im.SetBorder(true)
+	im.SetTitleAlign(tview.AlignCenter)
+	im.Form.SetBorderPadding(2, 2, 2, 2)
+
+	im.progress.SetDynamicColors(true)
+}
+
+func (im *ImportModal) setStyle() {
+	styles := im.App.GetStyles()
+	im.SetStyle(styles)
+
+	im.Form.SetFieldTextColor(styles.Connection.FormInputColor.Color())
+	im.Form.SetFieldBackgroundColor(styles.Connection.FormInputBackgroundColor.Color())
+	im.Form.SetLabelColor(styles.Connection.FormLabelColor.Color())
+	im.progress.SetTextColor(styles.Others.ModalTextColor.Color())
+	im.progress.SetBackgroundColor(styles.Global.BackgroundColor.Color())
+}
+
+func (im *ImportModal) setKeybindings() {
+	im.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEsc:
+			im.handleCancel()
+			return nil
+		}
+
+		return event
+	})
+}
+
+func (im *ImportModal) handleEvents() {
+	go im.HandleEvents(im.GetIdentifier(), func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			im.setStyle()
+		}
+	})
+}
+
+// SetImportFunc sets the function that runs the import
+func (im *ImportModal) SetImportFunc(importFunc ImportFunc) {
+	im.importFunc = importFunc
+}
+
+// Render shows the form for importing documents into given collection
+func (im *ImportModal) Render(db, coll string) {
+	if im.cancel != nil {
+		im.Show()
+		return
+	}
+
+	im.SetTitle(fmt.Sprintf(" Import into %s.%s ", db, coll))
+	im.Form.Clear(true)
+	im.progress.SetText("")
+
+	formats := make([]string, len(mongo.ImportFormats))
+	for i, format := range mongo.ImportFormats {
+		formats[i] = string(format)
+	}
+
+	im.Form.AddInputField("File", "", 50, nil, func(path string) {
+		im.selectFormatByPath(path)
+	})
+	im.Form.AddDropDown("Format", formats, 0, nil)
+	im.Form.AddCheckbox("Ordered", true, nil)
+	im.Form.AddCheckbox("Upsert by _id", false, nil)
+	im.Form.AddCheckbox("Infer CSV types", true, nil)
+	im.progress.SetLabel("Progress")
+	im.Form.AddFormItem(im.progress)
+
+	im.Form.AddButton("Import", func() {
+		im.handleImport()
+	})
+	im.Form.AddButton("Cancel", func() {
+		im.handleCancel()
+	})
+
+	im.Show()
+}
+
+func (im *ImportModal) selectFormatByPath(path string) {
+	format, ok := mongo.FileFormatFromPath(path)
+	if !ok {
+		return
+	}
+	idx := slices.Index(mongo.ImportFormats, format)
+	if idx < 0 {
+		return
+	}
+	if dropDown, ok := im.Form.GetFormItemByLabel("Format").(*tview.DropDown); ok {
+		dropDown.SetCurrentOption(idx)
+	}
+}
+
+func (im *ImportModal) handleImport() {
+	if im.cancel != nil || im.importFunc == nil {
+		return
+	}
+
+	path := strings.TrimSpace(im.Form.GetFormItemByLabel("File").(*tview.InputField).GetText())
+	if path == "" {
+		ShowError(im.App.Pages, "Invalid file path", fmt.Errorf("file path cannot be empty"))
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		ShowError(im.App.Pages, "Invalid file path", err)
+		return
+	}
+
+	_, format := im.Form.GetFormItemByLabel("Format").(*tview.DropDown).GetCurrentOption()
+	opts := mongo.ImportOptions{
+		Format:     mongo.FileFormat(format),
+		Ordered:    im.Form.GetFormItemByLabel("Ordered").(*tview.Checkbox).IsChecked(),
+		UpsertById: im.Form.GetFormItemByLabel("Upsert by _id").(*tview.Checkbox).IsChecked(),
+		InferTypes: im.Form.GetFormItemByLabel("Infer CSV types").(*tview.Checkbox).IsChecked(),
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	im.cancel = cancel
+	im.progress.SetText("Starting...")
+
+	go func() {
+		progress := func(result mongo.ImportResult) {
+			im.App.QueueUpdateDraw(func() {
+				im.progress.SetText(formatImportSummary(result))
+			})
+		}
+
+		result, err := im.importFunc(ctx, path, opts, progress)
+
+		im.App.QueueUpdateDraw(func() {
+			im.cancel = nil
+			cancel()
+			switch {
+			case errors.Is(err, context.Canceled):
+				im.progress.SetText("Cancelled, " + formatImportSummary(result))
+			case err != nil:
+				im.progress.SetText(formatImportSummary(result))
+				ShowError(im.App.Pages, "Error importing documents", err)
+			default:
+				im.Hide()
+				ShowInfo(im.App.Pages, formatImportReport(result))
+			}
+		})
+	}()
+}
+
+// handleCancel stops running import, if there is none it closes the modal
+func (im *ImportModal) handleCancel() {
+	if im.cancel != nil {
+		im.progress.SetText("Cancelling...")
+		im.cancel()
+		return
+	}
+	im.Hide()
+}
+
+func formatImportSummary(result mongo.ImportResult) string {
+	return fmt.Sprintf("read: %d, inserted: %d, updated: %d, failed: %d",
+		result.Read, result.Inserted, result.Updated, result.Failed)
+}
+
+func formatImportReport(result mongo.ImportResult) string {
+	report := "Import finished, " + formatImportSummary(result)
+	if len(result.Errors) > 0 {
+		report += "\n\n" + tview.Escape(strings.Join(result.Errors, "\n"))
+	}
+	return report
+}
+
+func (im *ImportModal) Show() {
+	im.App.Pages.AddPage(ImportModalId, im, true, true)
+}
+
+func (im *ImportModal) Hide() {
+	im.App.Pages.RemovePage(ImportModalId)
+}
@@ -98,10 +98,21 @@ func (qo *QueryOptionsModal) Render(ctx context.Context, state *mongo.Collection
 			return err == nil || textToCheck == ""
 		}, nil)
 
+	paginationModes := make([]string, len(mongo.PaginationModes))
+	currentPagination := 0
+	for i, mode := range mongo.PaginationModes {
+		paginationModes[i] = string(mode)
+		if mode == state.Pagination {
+			currentPagination = i
+		}
+	}
+	qo.Form.AddDropDown("Pagination", paginationModes, currentPagination, nil)
+
 	qo.Form.AddButton("Apply", func() {
 		limitText := qo.Form.GetFormItemByLabel("Limit").(*tview.InputField).GetText()
 		skipText := qo.Form.GetFormItemByLabel("Skip").(*tview.InputField).GetText()
 		projText := qo.Form.GetFormItemByLabel("Projection").(*tview.InputField).GetText()
+		_, pagination := qo.Form.GetFormItemByLabel("Pagination").(*tview.DropDown).GetCurrentOption()
 
 		if strings.Trim(limitText, " ") != "" {
 			val, err := strconv.ParseInt(limitText, 10, 64)
@@ -114,15 +125,22 @@ func (qo *QueryOptionsModal) Render(ctx context.Context, state *mongo.Collection
 			state.Limit = defaultLimit
 		}
 
+		if mongo.PaginationMode(pagination) != state.Pagination {
+			state.SetPagination(mongo.PaginationMode(pagination))
+		}
+
 		if strings.Trim(skipText, " ") != "" {
 			val, err := strconv.ParseInt(skipText, 10, 64)
 			if err != nil {
 				ShowError(qo.App.Pages, "Invalid skip value", err)
 				return
 			}
-			state.Skip = val
+			// keep the keyset position of the page if skip wasn't changed
+			if val != state.Skip {
+				state.SetSkip(val)
+			}
 		} else {
-			state.Skip = 0
+			state.SetSkip(0)
 		}
 
 		state.Projection = projText
@@ -0,0 +1,152 @@
+package modal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+)
+
+const (
+	UpdateManyModalId = "UpdateManyModal"
+)
+
+// UpdateManyModal shows how many documents match the filter together with
+// a sample of documents before and after the update and asks for confirmation
+type UpdateManyModal struct {
+	*core.BaseElement
+	*core.ViewModal
+
+	updateFunc func()
+	editFunc   func()
+}
+
+func NewUpdateManyModal() *UpdateManyModal {
+	um := &UpdateManyModal{
+		BaseElement: core.NewBaseElement(),
+		ViewModal:   core.NewViewModal(),
+	}
+
+	um.SetIdentifier(UpdateManyModalId)
+	um.SetAfterInitFunc(um.init)
+	return um
+}
+
+func (um *UpdateManyModal) init() error {
+	um.setLayout()
+	um.setStyle()
+	um.setKeybindings()
+	um.handleEvents()
+
+	return nil
+}
+
+func (um *UpdateManyModal) setLayout() {
+	um.SetBorder(true)
+	um.SetTitle(" Update matching documents ")
+	um.SetTitleAlign(tview.AlignLeft)
+
+	um.ViewModal.AddButtons([]string{"Update", "Edit", "Cancel"})
+	um.ViewModal.SetDoneFunc(func(_ int, buttonLabel string) {
+		um.Hide()
+		switch buttonLabel {
+		case "Update":
+			if um.updateFunc != nil {
+				um.updateFunc()
+			}
+		case "Edit":
+			if um.editFunc != nil {
+				um.editFunc()
+			}
+		}
+	})
+}
+
+func (um *UpdateManyModal) setStyle() {
+	styles := um.App.GetStyles()
+	um.ViewModal.SetPeekStyle(styles)
+	um.SetButtonActivatedStyle(tcell.StyleDefault.
+		Background(styles.Others.DeleteButtonSelectedBackgroundColor.Color()))
+}
+
+func (um *UpdateManyModal) setKeybindings() {
+	um.ViewModal.SetNavigationKeys(um.App.GetKeys())
+}
+
+func (um *UpdateManyModal) handleEvents() {
+	go um.HandleEvents(um.GetIdentifier(), func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			um.setStyle()
+		}
+	})
+}
+
+// SetUpdateFunc sets the function called when the update is confirmed
+func (um *UpdateManyModal) SetUpdateFunc(updateFunc func()) {
+	um.updateFunc = updateFunc
+}
+
+// SetEditFunc sets the function called when the user wants to change the update
+func (um *UpdateManyModal) SetEditFunc(editFunc func()) {
+	um.editFunc = editFunc
+}
+
+// Render shows the number of matching documents and the preview of the update
+func (um *UpdateManyModal) Render(filter, update string, count int64, preview mongo.UpdatePreview) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Filter: %s\n", tview.Escape(filter))
+	fmt.Fprintf(&b, "Update: %s\n", tview.Escape(update))
+	fmt.Fprintf(&b, "Matching documents: %d\n", count)
+
+	if preview.Unavailable != "" {
+		fmt.Fprintf(&b, "\nPreview of the result is not available: %s\n", tview.Escape(preview.Unavailable))
+	}
+
+	for i, before := range preview.Before {
+		if err := writePreviewDocument(&b, fmt.Sprintf("Before %d/%d", i+1, len(preview.Before)), before); err != nil {
+			return err
+		}
+		if i < len(preview.After) {
+			if err := writePreviewDocument(&b, fmt.Sprintf("After %d/%d", i+1, len(preview.Before)), preview.After[i]); err != nil {
+				return err
+			}
+		}
+	}
+
+	um.ViewModal.MoveToTop()
+	um.ViewModal.SetText(primitives.Text{
+		Content: b.String(),
+		Color:   um.App.GetStyles().DocPeeker.ValueColor.Color(),
+		Align:   tview.AlignLeft,
+	})
+	um.ViewModal.SetFocus(0)
+	um.Show()
+	return nil
+}
+
+func writePreviewDocument(b *strings.Builder, label string, doc map[string]any) error {
+	jsonDoc, err := mongo.ParseBsonDocument(doc)
+	if err != nil {
+		return err
+	}
+	indented, err := mongo.IndentJson(jsonDoc)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(b, "\n--- %s ---\n%s\n", label, indented.String())
+	return nil
+}
+
+func (um *UpdateManyModal) Show() {
+	um.App.Pages.AddPage(UpdateManyModalId, um, true, true)
+}
+
+func (um *UpdateManyModal) Hide() {
+	um.App.Pages.RemovePage(UpdateManyModalId)
+}
@@ -0,0 +1,459 @@
+package page
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	DashboardPageId = "Dashboard"
+	// dashboardHistorySize is the number of samples kept for each metric
+	dashboardHistorySize = 300
+	// dashboardLabelWidth and dashboardValueWidth are widths of the columns
+	// before the sparkline
+	dashboardLabelWidth = 20
+	dashboardValueWidth = 16
+)
+
+// dashboardIntervals are the polling intervals the user can switch between
+var dashboardIntervals = []time.Duration{time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second}
+
+// dashboardMetric is a single row of the dashboard
+type dashboardMetric struct {
+	label   string
+	format  func(value float64) string
+	history *mongo.MetricHistory
+	// value returns the metric value and false if the metric is not available
+	value func(curr mongo.ServerMetrics, rates mongo.ServerRates) (float64, bool)
+}
+
+// Dashboard is a view that polls serverStatus and shows server metrics
+// with their history
+type Dashboard struct {
+	*core.BaseElement
+	*core.Flex
+
+	header          *core.TextView
+	body            *core.TextView
+	replicaSetInfo  *core.TextView
+	replicaSetTable *core.Table
+
+	metrics        []*dashboardMetric
+	last           *mongo.ServerMetrics
+	layoutRendered bool
+	interval       time.Duration
+	// paused is read by the polling goroutine, so it's atomic
+	paused atomic.Bool
+	// stopPolling stops polling, it's set only while the view is shown
+	stopPolling context.CancelFunc
+	// changeInterval notifies the polling loop about the new interval
+	changeInterval chan time.Duration
+}
+
+func NewDashboard() *Dashboard {
+	d := &Dashboard{
+		BaseElement:     core.NewBaseElement(),
+		Flex:            core.NewFlex(),
+		header:          core.NewTextView(),
+		body:            core.NewTextView(),
+		replicaSetInfo:  core.NewTextView(),
+		replicaSetTable: core.NewTable(),
+		interval:        dashboardIntervals[1],
+	}
+	d.metrics = d.newMetrics()
+
+	d.SetIdentifier(DashboardPageId)
+	d.SetAfterInitFunc(d.init)
+
+	return d
+}
+
+func (d *Dashboard) init() error {
+	d.setLayout()
+	d.setStyle()
+	d.setKeybindings()
+
+	d.handleEvents()
+
+	return nil
+}
+
+func (d *Dashboard) newMetrics() []*dashboardMetric {
+	perSecond := func(value float64) string { return fmt.Sprintf("%.1f/s", value) }
+	bytesPerSecond := func(value float64) string { return util.FormatBytes(value) + "/s" }
+	count := func(value float64) string { return fmt.Sprintf("%.0f", value) }
+	bytes := func(value float64) string { return util.FormatBytes(value) }
+	percent := func(value float64) string { return fmt.Sprintf("%.1f%%", value) }
+	seconds := func(value float64) string { return fmt.Sprintf("%.0fs", value) }
+
+	metric := func(label string, format func(float64) string, value func(mongo.ServerMetrics, mongo.ServerRates) (float64, bool)) *dashboardMetric {
+		return &dashboardMetric{
+			label:   label,
+			format:  format,
+			value:   value,
+			history: mongo.NewMetricHistory(dashboardHistorySize),
+		}
+	}
+
+	return []*dashboardMetric{
+		metric("Inserts", perSecond, func(_ mongo.ServerMetrics, r mongo.ServerRates) (float64, bool) { return r.Insert, true }),
+		metric("Queries", perSecond, func(_ mongo.ServerMetrics, r mongo.ServerRates) (float64, bool) { return r.Query, true }),
+		metric("Updates", perSecond, func(_ mongo.ServerMetrics, r mongo.ServerRates) (float64, bool) { return r.Update, true }),
+		metric("Deletes", perSecond, func(_ mongo.ServerMetrics, r mongo.ServerRates) (float64, bool) { return r.Delete, true }),
+		metric("Getmores", perSecond, func(_ mongo.ServerMetrics, r mongo.ServerRates) (float64, bool) { return r.GetMore, true }),
+		metric("Commands", perSecond, func(_ mongo.ServerMetrics, r mongo.ServerRates) (float64, bool) { return r.Command, true }),
+		metric("Network in", bytesPerSecond, func(_ mongo.ServerMetrics, r mongo.ServerRates) (float64, bool) { return r.BytesIn, true }),
+		metric("Network out", bytesPerSecond, func(_ mongo.ServerMetrics, r mongo.ServerRates) (float64, bool) { return r.BytesOut, true }),
+		metric("Connections", count, func(m mongo.ServerMetrics, _ mongo.ServerRates) (float64, bool) {
+			return float64(m.CurrentConns), true
+		}),
+		metric("Active connections", count, func(m mongo.ServerMetrics, _ mongo.ServerRates) (float64, bool) {
+			return float64(m.ActiveConns), true
+		}),
+		metric("Cache used", bytes, func(m mongo.ServerMetrics, _ mongo.ServerRates) (float64, bool) {
+			return float64(m.CacheUsedBytes), m.CacheMaxBytes > 0
+		}),
+		metric("Cache fill", percent, func(m mongo.ServerMetrics, _ mongo.ServerRates) (float64, bool) {
+			if m.CacheMaxBytes == 0 {
+				return 0, false
+			}
+			return float64(m.CacheUsedBytes) / float64(m.CacheMaxBytes) * 100, true
+		}),
+		metric("Cache dirty", bytes, func(m mongo.ServerMetrics, _ mongo.ServerRates) (float64, bool) {
+			return float64(m.CacheDirtyBytes), m.CacheMaxBytes > 0
+		}),
+		metric("Replication lag", seconds, func(m mongo.ServerMetrics, _ mongo.ServerRates) (float64, bool) {
+			if m.ReplicaSet == nil {
+				return 0, false
+			}
+			lag, ok := m.ReplicaSet.MaxLag()
+			return lag.Seconds(), ok
+		}),
+	}
+}
+
+func (d *Dashboard) setLayout() {
+	d.SetBorder(true)
+	d.SetTitle(" Server Status ")
+	d.SetTitleAlign(tview.AlignCenter)
+	d.SetBorderPadding(0, 0, 1, 1)
+	d.SetDirection(tview.FlexRow)
+
+	d.body.SetDynamicColors(true)
+	d.body.SetWrap(false)
+
+	d.replicaSetInfo.SetBorder(true)
+	d.replicaSetInfo.SetTitle(" Replica Set ")
+	d.replicaSetInfo.SetTitleAlign(tview.AlignLeft)
+	d.replicaSetTable.SetSelectable(false, false)
+}
+
+// renderLayout shows the replica set panel only for replica set members
+func (d *Dashboard) renderLayout() {
+	d.layoutRendered = true
+	d.Flex.Clear()
+	d.Flex.AddItem(d.header, 2, 0, false)
+	if d.last == nil || d.last.ReplicaSet == nil {
+		d.Flex.AddItem(d.body, 0, 1, true)
+		return
+	}
+	d.Flex.AddItem(d.body, 0, 3, true)
+	d.Flex.AddItem(d.replicaSetInfo, 4, 0, false)
+	d.Flex.AddItem(d.replicaSetTable, 0, 1, false)
+}
+
+func (d *Dashboard) setStyle() {
+	styles := d.App.GetStyles()
+	d.SetStyle(styles)
+	d.header.SetStyle(styles)
+	d.body.SetStyle(styles)
+	d.replicaSetInfo.SetStyle(styles)
+	d.replicaSetTable.SetStyle(styles)
+
+	d.header.SetTextColor(styles.Content.StatusTextColor.Color())
+	d.replicaSetInfo.SetTextColor(styles.Content.StatusTextColor.Color())
+	d.replicaSetTable.SetSeparator(styles.Others.SeparatorSymbol.Rune())
+	d.replicaSetTable.SetBordersColor(styles.Others.SeparatorColor.Color())
+}
+
+func (d *Dashboard) setKeybindings() {
+	k := d.App.GetKeys()
+
+	d.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case k.Contains(k.Dashboard.Close, event.Name()):
+			d.Hide()
+			return nil
+		case k.Contains(k.Dashboard.TogglePause, event.Name()):
+			d.paused.Store(!d.paused.Load())
+			d.renderHeader()
+			return nil
+		case k.Contains(k.Dashboard.ChangeInterval, event.Name()):
+			d.nextInterval()
+			return nil
+		}
+		return event
+	})
+}
+
+func (d *Dashboard) handleEvents() {
+	go d.HandleEvents(DashboardPageId, func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			d.setStyle()
+			go d.App.QueueUpdateDraw(func() {
+				d.renderBody()
+				d.renderReplicaSet()
+			})
+		}
+	})
+}
+
+// Render shows the dashboard and starts polling the server
+func (d *Dashboard) Render() {
+	if err := d.poll(context.Background()); err != nil {
+		modal.ShowError(d.App.Pages, "Error getting server status", err)
+		return
+	}
+	d.App.Pages.AddPage(DashboardPageId, d, true, true)
+	d.App.SetFocus(d)
+
+	// Render may be called while the dashboard is shown, so the previous polling is stopped
+	d.stopPollingLoop()
+	ctx, cancel := context.WithCancel(context.Background())
+	d.stopPolling = cancel
+	d.changeInterval = make(chan time.Duration, 1)
+	go d.pollPeriodically(ctx, d.interval, d.changeInterval)
+}
+
+// Hide closes the dashboard and stops polling, collected history is kept
+// so reopening the dashboard continues the graphs
+func (d *Dashboard) Hide() {
+	d.stopPollingLoop()
+	d.App.Pages.RemovePage(DashboardPageId)
+}
+
+func (d *Dashboard) stopPollingLoop() {
+	if d.stopPolling != nil {
+		d.stopPolling()
+		d.stopPolling = nil
+	}
+}
+
+// Reset clears the collected history, used when connecting to another server
+func (d *Dashboard) Reset() {
+	d.last = nil
+	d.layoutRendered = false
+	d.metrics = d.newMetrics()
+}
+
+func (d *Dashboard) pollPeriodically(ctx context.Context, interval time.Duration, changeInterval <-chan time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case interval := <-changeInterval:
+			ticker.Reset(interval)
+		case <-ticker.C:
+			if d.paused.Load() {
+				continue
+			}
+			metrics, err := d.getMetrics(ctx)
+			if err != nil {
+				// errors are shown only when opening the dashboard to not spam the user
+				continue
+			}
+			d.App.QueueUpdateDraw(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				d.update(metrics)
+			})
+		}
+	}
+}
+
+func (d *Dashboard) getMetrics(ctx context.Context) (mongo.ServerMetrics, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	return d.Dao.GetServerMetrics(ctx)
+}
+
+func (d *Dashboard) poll(ctx context.Context) error {
+	metrics, err := d.getMetrics(ctx)
+	if err != nil {
+		return err
+	}
+	d.update(metrics)
+	return nil
+}
+
+// update adds new metrics to the history, rates are computed
+// starting from the second sample
+func (d *Dashboard) update(metrics mongo.ServerMetrics) {
+	if d.last != nil {
+		rates := mongo.ComputeRates(*d.last, metrics)
+		for _, metric := range d.metrics {
+			if value, ok := metric.value(metrics, rates); ok {
+				metric.history.Add(value)
+			}
+		}
+	}
+	hadReplicaSet := d.last != nil && d.last.ReplicaSet != nil
+	d.last = &metrics
+	if hadReplicaSet != (metrics.ReplicaSet != nil) || !d.layoutRendered {
+		d.renderLayout()
+	}
+	d.renderHeader()
+	d.renderBody()
+	d.renderReplicaSet()
+}
+
+func (d *Dashboard) nextInterval() {
+	for i, interval := range dashboardIntervals {
+		if interval == d.interval {
+			d.interval = dashboardIntervals[(i+1)%len(dashboardIntervals)]
+			break
+		}
+	}
+	if d.changeInterval != nil {
+		// only the latest interval matters, so the pending one is dropped
+		select {
+		case <-d.changeInterval:
+		default:
+		}
+		d.changeInterval <- d.interval
+	}
+	d.renderHeader()
+}
+
+func (d *Dashboard) renderHeader() {
+	if d.last == nil {
+		return
+	}
+	polling := fmt.Sprintf("every %s", d.interval)
+	if d.paused.Load() {
+		polling = "paused"
+	}
+	d.header.SetText(fmt.Sprintf("Host: %s | Version: %s | Uptime: %s | Role: %s\nResident memory: %d MB | Virtual memory: %d MB | Available connections: %d | Polling: %s",
+		d.last.Host, d.last.Version, time.Duration(d.last.Uptime)*time.Second, d.last.Role,
+		d.last.ResidentMb, d.last.VirtualMb, d.last.AvailableConns, polling))
+}
+
+func (d *Dashboard) renderBody() {
+	styles := d.App.GetStyles()
+	labelColor := styles.Content.ColumnKeyColor.Color()
+	valueColor := styles.Content.CellTextColor.Color()
+	graphColor := styles.Content.ColumnTypeColor.Color()
+
+	_, _, width, _ := d.body.GetInnerRect()
+	graphWidth := width - dashboardLabelWidth - dashboardValueWidth
+	if graphWidth < 0 {
+		graphWidth = 0
+	}
+
+	var sb strings.Builder
+	for _, metric := range d.metrics {
+		values := metric.history.Values()
+		value := "-"
+		if len(values) > 0 {
+			value = metric.format(metric.history.Last())
+		}
+		sb.WriteString(fmt.Sprintf("[%s]%-*s[%s]%*s  [%s]%s[-]\n\n",
+			labelColor, dashboardLabelWidth, metric.label,
+			valueColor, dashboardValueWidth-2, value,
+			graphColor, util.Sparkline(values, graphWidth)))
+	}
+	d.body.SetText(sb.String())
+}
+
+func (d *Dashboard) renderReplicaSet() {
+	if d.last == nil || d.last.ReplicaSet == nil {
+		return
+	}
+	replicaSet := d.last.ReplicaSet
+
+	lastElection := "unknown"
+	if !replicaSet.LastElection.IsZero() {
+		lastElection = replicaSet.LastElection.Local().Format(time.DateTime)
+	}
+	info := fmt.Sprintf("Name: %s | Primary: %s | Connected to: %s | Term: %d\nLast election: %s",
+		replicaSet.Name, replicaSet.Primary, replicaSet.Me, replicaSet.Term, lastElection)
+	if replicaSet.LastElectionReason != "" {
+		info += fmt.Sprintf(" (%s)", replicaSet.LastElectionReason)
+	}
+	if replicaSet.ElectionId != "" {
+		info += fmt.Sprintf(" | Election id: %s", replicaSet.ElectionId)
+	}
+	d.replicaSetInfo.SetText(info)
+
+	styles := d.App.GetStyles()
+	d.replicaSetTable.Clear()
+	d.replicaSetTable.SetFixed(1, 0)
+
+	headers := []string{"Id", "Member", "State", "Health", "Uptime", "Optime", "Lag", "Sync source", "Ping", "Last heartbeat"}
+	d.replicaSetTable.SetHeaderRow(headers, styles)
+
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.Local().Format(time.DateTime)
+	}
+
+	for i, member := range replicaSet.Members {
+		row := i + 1
+
+		name := member.Name
+		if member.Self {
+			name += " (self)"
+		}
+		health := "ok"
+		if !member.Healthy {
+			health = "down"
+			if member.Message != "" {
+				health += ": " + member.Message
+			}
+		}
+		lag := "-"
+		if member.HasLag {
+			lag = member.Lag.String()
+		}
+		syncSource := member.SyncSource
+		if syncSource == "" {
+			syncSource = "-"
+		}
+		ping := "-"
+		if !member.Self && member.Healthy {
+			ping = fmt.Sprintf("%dms", member.PingMs)
+		}
+
+		d.replicaSetTable.SetCell(row, 0, tview.NewTableCell(fmt.Sprintf(" %d ", member.Id)))
+		d.replicaSetTable.SetCell(row, 1, tview.NewTableCell(" "+name+" "))
+		d.replicaSetTable.SetCell(row, 2, tview.NewTableCell(" "+tview.Escape(member.State)+" "))
+		d.replicaSetTable.SetCell(row, 3, tview.NewTableCell(" "+tview.Escape(health)+" "))
+		d.replicaSetTable.SetCell(row, 4, tview.NewTableCell(" "+(time.Duration(member.Uptime)*time.Second).String()+" ").
+			SetAlign(tview.AlignRight))
+		d.replicaSetTable.SetCell(row, 5, tview.NewTableCell(" "+formatTime(member.Optime)+" "))
+		d.replicaSetTable.SetCell(row, 6, tview.NewTableCell(" "+lag+" ").
+			SetAlign(tview.AlignRight))
+		d.replicaSetTable.SetCell(row, 7, tview.NewTableCell(" "+syncSource+" "))
+		d.replicaSetTable.SetCell(row, 8, tview.NewTableCell(" "+ping+" ").
+			SetAlign(tview.AlignRight))
+		d.replicaSetTable.SetCell(row, 9, tview.NewTableCell(" "+formatTime(member.LastHeartbeat)+" "))
+	}
+}
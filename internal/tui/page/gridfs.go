@@ -0,0 +1,373 @@
+package page
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	GridFSPageId          = "GridFS"
+	GridFSFormId          = "GridFSForm"
+	GridFSDeleteConfirmId = "GridFSDeleteConfirm"
+	gridFSCommandTimeout  = 10 * time.Second
+	// gridFSTransferTimeout limits download and upload of a single file
+	gridFSTransferTimeout = 10 * time.Minute
+	// gridFSFilesLimit is the number of the most recent files listed
+	gridFSFilesLimit     = 1000
+	gridFSFormInputWidth = 50
+)
+
+// GridFS is a view that lists files of a GridFS bucket
+// and allows to download, upload and delete them
+type GridFS struct {
+	*core.BaseElement
+	*core.Flex
+
+	header        *core.TextView
+	table         *core.Table
+	form          *core.FormModal
+	deleteConfirm *modal.Confirm
+
+	db     string
+	bucket string
+	files  []mongo.GridFSFile
+}
+
+func NewGridFS() *GridFS {
+	g := &GridFS{
+		BaseElement:   core.NewBaseElement(),
+		Flex:          core.NewFlex(),
+		header:        core.NewTextView(),
+		table:         core.NewTable(),
+		form:          core.NewFormModal(),
+		deleteConfirm: modal.NewConfirm(GridFSDeleteConfirmId),
+	}
+
+	g.SetIdentifier(GridFSPageId)
+	g.SetAfterInitFunc(g.init)
+
+	return g
+}
+
+func (g *GridFS) init() error {
+	g.setLayout()
+	g.setStyle()
+	g.setKeybindings()
+
+	if err := g.deleteConfirm.Init(g.App); err != nil {
+		return err
+	}
+
+	g.handleEvents()
+
+	return nil
+}
+
+func (g *GridFS) setLayout() {
+	g.SetBorder(true)
+	g.SetTitleAlign(tview.AlignCenter)
+	g.SetBorderPadding(0, 0, 1, 1)
+	g.SetDirection(tview.FlexRow)
+
+	g.table.SetSelectable(true, false)
+
+	g.form.SetBorder(true)
+	g.form.SetTitleAlign(tview.AlignCenter)
+	g.form.Form.SetBorderPadding(1, 1, 2, 2)
+
+	g.Flex.AddItem(g.header, 1, 0, false)
+	g.Flex.AddItem(g.table, 0, 1, true)
+}
+
+func (g *GridFS) setStyle() {
+	styles := g.App.GetStyles()
+	g.SetStyle(styles)
+	g.header.SetStyle(styles)
+	g.table.SetStyle(styles)
+	g.form.SetStyle(styles)
+
+	g.header.SetTextColor(styles.Content.StatusTextColor.Color())
+	g.table.SetSeparator(styles.Others.SeparatorSymbol.Rune())
+	g.table.SetBordersColor(styles.Others.SeparatorColor.Color())
+
+	g.form.Form.SetFieldTextColor(styles.Connection.FormInputColor.Color())
+	g.form.Form.SetFieldBackgroundColor(styles.Connection.FormInputBackgroundColor.Color())
+	g.form.Form.SetLabelColor(styles.Connection.FormLabelColor.Color())
+}
+
+func (g *GridFS) setKeybindings() {
+	k := g.App.GetKeys()
+
+	g.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case k.Contains(k.Navigation.MoveUp, event.Name()):
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case k.Contains(k.Navigation.MoveDown, event.Name()):
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case k.Contains(k.GridFS.Close, event.Name()):
+			g.Hide()
+			return nil
+		case k.Contains(k.GridFS.Refresh, event.Name()):
+			g.refresh()
+			return nil
+		case k.Contains(k.GridFS.Download, event.Name()):
+			g.showDownloadForm()
+			return nil
+		case k.Contains(k.GridFS.Upload, event.Name()):
+			g.showUploadForm()
+			return nil
+		case k.Contains(k.GridFS.Delete, event.Name()):
+			g.deleteFile()
+			return nil
+		}
+		return event
+	})
+
+	g.form.SetCancelFunc(g.hideForm)
+}
+
+func (g *GridFS) handleEvents() {
+	go g.HandleEvents(GridFSPageId, func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			g.setStyle()
+		}
+	})
+}
+
+// Render shows files of the bucket in the given database
+func (g *GridFS) Render(db, bucket string) {
+	g.db, g.bucket = db, bucket
+	g.SetTitle(fmt.Sprintf(" GridFS - %s.%s ", db, bucket))
+
+	if !g.refresh() {
+		return
+	}
+	g.App.Pages.AddPage(GridFSPageId, g, true, true)
+	g.App.SetFocus(g.table)
+}
+
+func (g *GridFS) Hide() {
+	g.App.Pages.RemovePage(GridFSPageId)
+}
+
+// refresh fetches files of the bucket, returns false when fetching failed
+func (g *GridFS) refresh() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), gridFSCommandTimeout)
+	defer cancel()
+
+	files, err := g.Dao.ListGridFSFiles(ctx, g.db, g.bucket, gridFSFilesLimit)
+	if err != nil {
+		modal.ShowError(g.App.Pages, "Error listing GridFS files", err)
+		return false
+	}
+	g.files = files
+	g.renderHeader("")
+	g.renderTable()
+	return true
+}
+
+// renderHeader shows the summary of the bucket followed by the status of running transfer
+func (g *GridFS) renderHeader(status string) {
+	var total int64
+	for _, file := range g.files {
+		total += file.Length
+	}
+	text := fmt.Sprintf("Database: %s | Bucket: %s | Files: %d | Size: %s",
+		g.db, g.bucket, len(g.files), util.FormatBytes(float64(total)))
+	if len(g.files) == gridFSFilesLimit {
+		text += fmt.Sprintf(" (the most recent %d)", gridFSFilesLimit)
+	}
+	if status != "" {
+		text += " | " + status
+	}
+	g.header.SetText(text)
+}
+
+func (g *GridFS) renderTable() {
+	styles := g.App.GetStyles()
+	g.table.Clear()
+	g.table.SetFixed(1, 0)
+
+	headers := []string{"Filename", "Length", "Upload date", "Metadata"}
+	g.table.SetHeaderRow(headers, styles)
+
+	for i, file := range g.files {
+		row := i + 1
+		uploadDate := ""
+		if !file.UploadDate.IsZero() {
+			uploadDate = file.UploadDate.Local().Format(time.DateTime)
+		}
+		g.table.SetCell(row, 0, tview.NewTableCell(" "+tview.Escape(file.Filename)+" "))
+		g.table.SetCell(row, 1, tview.NewTableCell(" "+util.FormatBytes(float64(file.Length))+" ").
+			SetAlign(tview.AlignRight))
+		g.table.SetCell(row, 2, tview.NewTableCell(" "+uploadDate+" "))
+		g.table.SetCell(row, 3, tview.NewTableCell(" "+tview.Escape(file.MetadataJson())+" ").
+			SetExpansion(1))
+	}
+	g.table.Select(1, 0)
+}
+
+func (g *GridFS) selectedFile() *mongo.GridFSFile {
+	row, _ := g.table.GetSelection()
+	if row < 1 || row > len(g.files) {
+		return nil
+	}
+	return &g.files[row-1]
+}
+
+func (g *GridFS) showForm(title, submitLabel string, submit func() error) {
+	g.form.SetTitle(title)
+	g.form.Form.AddButton(submitLabel, func() {
+		if err := submit(); err != nil {
+			modal.ShowError(g.App.Pages, "Error in GridFS form", err)
+			return
+		}
+		g.hideForm()
+	})
+	g.form.Form.AddButton("Cancel", g.hideForm)
+	g.App.Pages.AddPage(GridFSFormId, g.form, true, true)
+}
+
+func (g *GridFS) hideForm() {
+	g.App.Pages.RemovePage(GridFSFormId)
+	g.App.SetFocus(g.table)
+}
+
+func (g *GridFS) formText(label string) string {
+	return strings.TrimSpace(g.form.Form.GetFormItemByLabel(label).(*tview.InputField).GetText())
+}
+
+func (g *GridFS) showDownloadForm() {
+	file := g.selectedFile()
+	if file == nil {
+		return
+	}
+	selected := *file
+
+	g.form.Form.Clear(true)
+	g.form.Form.AddInputField("Save as", filepath.Base(selected.Filename), gridFSFormInputWidth, nil, nil)
+
+	g.showForm(fmt.Sprintf(" Download %s ", selected.Filename), "Download", func() error {
+		path := g.formText("Save as")
+		if path == "" {
+			return fmt.Errorf("file path cannot be empty")
+		}
+		// existing files are never overwritten
+		out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			return err
+		}
+
+		g.transfer(fmt.Sprintf("Downloading %s...", selected.Filename), func(ctx context.Context) (string, error) {
+			written, err := g.Dao.DownloadGridFSFile(ctx, g.db, g.bucket, selected.ID, out)
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return "", errors.Join(err, os.Remove(path))
+			}
+			return fmt.Sprintf("Downloaded %s (%s) to %s", selected.Filename, util.FormatBytes(float64(written)), path), nil
+		})
+		return nil
+	})
+}
+
+func (g *GridFS) showUploadForm() {
+	g.form.Form.Clear(true)
+	g.form.Form.AddInputField("File", "", gridFSFormInputWidth, nil, nil)
+	g.form.Form.AddInputField("Filename (default: file name)", "", gridFSFormInputWidth, nil, nil)
+	g.form.Form.AddInputField("Metadata (JSON)", "", gridFSFormInputWidth, nil, nil)
+
+	g.showForm(fmt.Sprintf(" Upload into %s.%s ", g.db, g.bucket), "Upload", func() error {
+		path := g.formText("File")
+		if path == "" {
+			return fmt.Errorf("file path cannot be empty")
+		}
+		filename := g.formText("Filename (default: file name)")
+		if filename == "" {
+			filename = filepath.Base(path)
+		}
+		var metadata map[string]any
+		if text := g.formText("Metadata (JSON)"); text != "" {
+			doc, err := mongo.ParseJsonToBson(text)
+			if err != nil {
+				return fmt.Errorf("invalid metadata: %w", err)
+			}
+			metadata = doc
+		}
+		in, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		g.transfer(fmt.Sprintf("Uploading %s...", filename), func(ctx context.Context) (string, error) {
+			defer in.Close()
+			if _, err := g.Dao.UploadGridFSFile(ctx, g.db, g.bucket, filename, in, metadata); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Uploaded %s into %s.%s", filename, g.db, g.bucket), nil
+		})
+		return nil
+	})
+}
+
+// transfer runs the download or upload in the background, the status is shown
+// in the header and files are refreshed when it's done
+func (g *GridFS) transfer(status string, run func(ctx context.Context) (string, error)) {
+	g.renderHeader(status)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), gridFSTransferTimeout)
+		defer cancel()
+		message, err := run(ctx)
+
+		g.App.QueueUpdateDraw(func() {
+			g.refresh()
+			if err != nil {
+				modal.ShowError(g.App.Pages, "Error transferring GridFS file", err)
+				return
+			}
+			modal.ShowInfo(g.App.Pages, message)
+		})
+	}()
+}
+
+func (g *GridFS) deleteFile() {
+	file := g.selectedFile()
+	if file == nil {
+		return
+	}
+	selected := *file
+
+	g.deleteConfirm.SetConfirmButtonLabel("Delete")
+	g.deleteConfirm.SetText(fmt.Sprintf("Are you sure you want to delete [blue]%s[-] (%s) with all its chunks?",
+		tview.Escape(selected.Filename), tview.Escape(mongo.StringifyId(selected.ID))))
+	g.deleteConfirm.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		g.App.Pages.RemovePage(g.deleteConfirm.GetIdentifier())
+		if buttonLabel != "Delete" {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), gridFSCommandTimeout)
+		defer cancel()
+		if err := g.Dao.DeleteGridFSFile(ctx, g.db, g.bucket, selected.ID); err != nil {
+			modal.ShowError(g.App.Pages, "Error deleting GridFS file", err)
+			return
+		}
+		g.refresh()
+	})
+	g.App.Pages.AddPage(g.deleteConfirm.GetIdentifier(), g.deleteConfirm, true, true)
+}
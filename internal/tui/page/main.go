@@ -3,7 +3,6 @@ package page
 import (
 	"context"
 	"fmt"
-	"time"
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/kopecmaciej/tview"
@@ -29,7 +28,15 @@ type Main struct {
 	content      *component.Content
 	index        *component.Index
 	aggregation  *component.Aggregation
+	schema       *component.Schema
 	aiPrompt     *component.AIQuery
+	operations   *Operations
+	profiler     *Profiler
+	dashboard    *Dashboard
+	sharding     *Sharding
+	users        *Users
+	stats        *Stats
+	gridFS       *GridFS
 	headerHeight int
 }
 
@@ -44,7 +51,15 @@ func NewMain() *Main {
 		content:     component.NewContent(),
 		index:       component.NewIndex(),
 		aggregation: component.NewAggregation(),
+		schema:      component.NewSchema(),
 		aiPrompt:    component.NewAIQuery(),
+		operations:  NewOperations(),
+		profiler:    NewProfiler(),
+		dashboard:   NewDashboard(),
+		sharding:    NewSharding(),
+		users:       NewUsers(),
+		stats:       NewStats(),
+		gridFS:      NewGridFS(),
 	}
 
 	m.SetIdentifier(MainPageId)
@@ -73,6 +88,11 @@ func (m *Main) handleEvents() {
 		switch event.Message.Type {
 		case manager.StyleChanged:
 			m.setStyles()
+		case manager.RunPipeline:
+			// pipelines written in the query bar are shown in the Aggregation view
+			go m.App.QueueUpdateDraw(func() {
+				m.switchTab("Aggregation")
+			})
 		}
 	})
 }
@@ -109,13 +129,46 @@ func (m *Main) initComponents() error {
 		return err
 	}
 
+	if err := m.schema.Init(m.App); err != nil {
+		return err
+	}
+
 	if err := m.aiPrompt.Init(m.App); err != nil {
 		return err
 	}
 
+	if err := m.operations.Init(m.App); err != nil {
+		return err
+	}
+
+	if err := m.profiler.Init(m.App); err != nil {
+		return err
+	}
+
+	if err := m.dashboard.Init(m.App); err != nil {
+		return err
+	}
+
+	if err := m.sharding.Init(m.App); err != nil {
+		return err
+	}
+
+	if err := m.users.Init(m.App); err != nil {
+		return err
+	}
+
+	if err := m.stats.Init(m.App); err != nil {
+		return err
+	}
+
+	if err := m.gridFS.Init(m.App); err != nil {
+		return err
+	}
+
 	m.tabBar.AddTab("Content", m.content, true)
 	m.tabBar.AddTab("Aggregation", m.aggregation, false)
 	m.tabBar.AddTab("Indexes", m.index, false)
+	m.tabBar.AddTab("Schema", m.schema, false)
 
 	return nil
 }
@@ -132,9 +185,23 @@ func (m *Main) Render() {
 		}
 		m.index.HandleDatabaseSelection(ctx, db, coll)
 		m.aggregation.HandleDatabaseSelection(ctx, db, coll)
+		m.schema.HandleDatabaseSelection(ctx, db, coll)
 		m.App.SetFocus(m.tabBar.GetActiveComponent())
 		return nil
 	})
+	m.databases.SetProfilerFunc(m.profiler.Render)
+	m.databases.SetUsersFunc(m.users.Render)
+	m.databases.SetStatsFunc(m.stats.Render)
+	m.databases.SetBucketFunc(m.gridFS.Render)
+	m.profiler.SetJumpFunc(m.jumpToIndexes)
+	jumpToCollection := func(db, coll string) {
+		if err := m.JumpToCollection(db, coll); err != nil {
+			modal.ShowError(m.App.Pages, "Error jumping to collection", err)
+		}
+	}
+	m.sharding.SetJumpFunc(jumpToCollection)
+	m.stats.SetJumpFunc(jumpToCollection)
+	m.content.SetJumpFunc(m.JumpToCollection)
 
 	m.render()
 }
@@ -146,6 +213,15 @@ func (m *Main) UpdateDao(dao *mongo.Dao) {
 	m.content.UpdateDao(dao)
 	m.index.UpdateDao(dao)
 	m.aggregation.UpdateDao(dao)
+	m.schema.UpdateDao(dao)
+	m.operations.UpdateDao(dao)
+	m.profiler.UpdateDao(dao)
+	m.dashboard.UpdateDao(dao)
+	m.dashboard.Reset()
+	m.sharding.UpdateDao(dao)
+	m.users.UpdateDao(dao)
+	m.stats.UpdateDao(dao)
+	m.gridFS.UpdateDao(dao)
 }
 
 func (m *Main) JumpToCollection(dbName, collectionName string) error {
@@ -162,12 +238,31 @@ func (m *Main) JumpToCollection(dbName, collectionName string) error {
 
 	m.index.HandleDatabaseSelection(ctx, dbName, collectionName)
 	m.aggregation.HandleDatabaseSelection(ctx, dbName, collectionName)
+	m.schema.HandleDatabaseSelection(ctx, dbName, collectionName)
 
 	m.App.SetFocus(m.tabBar.GetActiveComponent())
 
 	return nil
 }
 
+// jumpToIndexes selects the collection and switches to the Indexes tab
+func (m *Main) jumpToIndexes(dbName, collectionName string) {
+	if err := m.JumpToCollection(dbName, collectionName); err != nil {
+		modal.ShowError(m.App.Pages, "Error jumping to collection", err)
+		return
+	}
+
+	m.switchTab("Indexes")
+}
+
+// switchTab shows the tab with the given name and focuses it
+func (m *Main) switchTab(name string) {
+	m.innerFlex.RemoveItem(m.tabBar.GetActiveComponent())
+	m.tabBar.SetActiveTab(name)
+	m.innerFlex.AddItem(m.tabBar.GetActiveComponentAndRender(), 0, 7, true)
+	m.App.SetFocus(m.tabBar.GetActiveComponent())
+}
+
 func (m *Main) render() {
 	m.Clear()
 
@@ -191,6 +286,14 @@ func (m *Main) rebuildInnerFlex() {
 	m.innerFlex.AddItem(m.tabBar.GetActiveComponentAndRender(), 0, 7, true)
 }
 
+// CancelQuery cancels queries running in the Content and Aggregation views,
+// it returns false when none of them is running
+func (m *Main) CancelQuery() bool {
+	contentCancelled := m.content.CancelQuery()
+	aggregationCancelled := m.aggregation.CancelQuery()
+	return contentCancelled || aggregationCancelled
+}
+
 func (m *Main) ToggleHeader() {
 	m.headerHeight = m.header.Toggle()
 	m.rebuildInnerFlex()
@@ -239,31 +342,22 @@ func (m *Main) setKeybindings() {
 			}
 			return nil
 		case k.Contains(k.Main.ShowServerInfo, event.Name()):
-			m.ShowServerInfoModal()
+			m.dashboard.Render()
 			return nil
 		case k.Contains(k.Main.ShowAIQuery, event.Name()):
 			m.ShowAIPrompt()
 			return nil
+		case k.Contains(k.Main.ShowOperations, event.Name()):
+			m.operations.Render()
+			return nil
+		case k.Contains(k.Main.ShowSharding, event.Name()):
+			m.sharding.Render()
+			return nil
 		}
 		return event
 	})
 }
 
-func (m *Main) ShowServerInfoModal() {
-	serverInfoModal := modal.NewServerInfoModal(m.Dao)
-	serverInfoModal.Init(m.App)
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := serverInfoModal.Render(ctx); err != nil {
-		modal.ShowError(m.App.Pages, "Failed to render server info modal", err)
-		return
-	}
-
-	m.App.Pages.AddPage(modal.ServerInfoModalId, serverInfoModal, true, true)
-}
-
 func (m *Main) ShowAIPrompt() {
 	m.aiPrompt.Render()
 	m.App.Pages.AddPage(component.AIQueryId, m.aiPrompt, true, true)
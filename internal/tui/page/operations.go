@@ -0,0 +1,399 @@
+package page
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+)
+
+const (
+	OperationsPageId        = "Operations"
+	OperationPeekId         = "OperationPeek"
+	OperationKillConfirmId  = "OperationKillConfirm"
+	operationsRefreshPeriod = 2 * time.Second
+	// operationCommandWidth is the maximum width of the command shown in the table
+	operationCommandWidth = 80
+)
+
+// operationMinDurations are the minimum running times in seconds
+// the user can switch between
+var operationMinDurations = []int64{0, 1, 5, 30, 60}
+
+// Operations is a view that lists in-progress operations reported by currentOp
+type Operations struct {
+	*core.BaseElement
+	*core.Flex
+
+	header      *core.TextView
+	filterInput *core.InputField
+	table       *core.Table
+	peek        *core.ViewModal
+	killConfirm *modal.Confirm
+
+	operations  []mongo.Operation
+	namespace   string
+	minDuration int64
+	// autoRefresh is read by the refreshing goroutine, so it's atomic
+	autoRefresh atomic.Bool
+	// stopRefresh stops the auto refresh, it's set only while the view is shown
+	stopRefresh context.CancelFunc
+}
+
+func NewOperations() *Operations {
+	o := &Operations{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		header:      core.NewTextView(),
+		filterInput: core.NewInputField(),
+		table:       core.NewTable(),
+		peek:        core.NewViewModal(),
+		killConfirm: modal.NewConfirm(OperationKillConfirmId),
+	}
+	o.autoRefresh.Store(true)
+
+	o.SetIdentifier(OperationsPageId)
+	o.SetAfterInitFunc(o.init)
+
+	return o
+}
+
+func (o *Operations) init() error {
+	o.setLayout()
+	o.setStyle()
+	o.setKeybindings()
+
+	if err := o.killConfirm.Init(o.App); err != nil {
+		return err
+	}
+
+	o.handleEvents()
+
+	return nil
+}
+
+func (o *Operations) setLayout() {
+	o.SetBorder(true)
+	o.SetTitle(" Operations ")
+	o.SetTitleAlign(tview.AlignCenter)
+	o.SetBorderPadding(0, 0, 1, 1)
+	o.SetDirection(tview.FlexRow)
+
+	o.table.SetSelectable(true, false)
+	o.filterInput.SetLabel(" Namespace: ")
+	o.filterInput.SetBorder(true)
+
+	o.peek.SetBorder(true)
+	o.peek.SetTitle("Operation Details")
+	o.peek.SetTitleAlign(tview.AlignLeft)
+	o.peek.AddButtons([]string{"Close"})
+}
+
+func (o *Operations) setStyle() {
+	styles := o.App.GetStyles()
+	o.SetStyle(styles)
+	o.header.SetStyle(styles)
+	o.filterInput.SetStyle(styles)
+	o.table.SetStyle(styles)
+
+	o.header.SetTextColor(styles.Content.StatusTextColor.Color())
+	o.table.SetSeparator(styles.Others.SeparatorSymbol.Rune())
+	o.table.SetBordersColor(styles.Others.SeparatorColor.Color())
+
+	o.peek.SetPeekStyle(styles)
+}
+
+func (o *Operations) setKeybindings() {
+	k := o.App.GetKeys()
+
+	o.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case k.Contains(k.Navigation.MoveUp, event.Name()):
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case k.Contains(k.Navigation.MoveDown, event.Name()):
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case k.Contains(k.Operations.Close, event.Name()):
+			o.Hide()
+			return nil
+		case k.Contains(k.Operations.Refresh, event.Name()):
+			o.refresh(context.Background())
+			return nil
+		case k.Contains(k.Operations.ToggleAutoRefresh, event.Name()):
+			o.autoRefresh.Store(!o.autoRefresh.Load())
+			o.renderHeader()
+			return nil
+		case k.Contains(k.Operations.FilterNamespace, event.Name()):
+			o.showFilter()
+			return nil
+		case k.Contains(k.Operations.ChangeMinDuration, event.Name()):
+			o.nextMinDuration()
+			o.renderTable()
+			return nil
+		case k.Contains(k.Operations.PeekOperation, event.Name()):
+			o.peekOperation()
+			return nil
+		case k.Contains(k.Operations.KillOperation, event.Name()):
+			o.killOperation()
+			return nil
+		}
+		return event
+	})
+
+	o.filterInput.SetDoneFunc(func(key tcell.Key) {
+		switch key {
+		case tcell.KeyEnter:
+			o.namespace = o.filterInput.GetText()
+		case tcell.KeyEsc:
+			o.filterInput.SetText(o.namespace)
+		}
+		o.hideFilter()
+		o.renderTable()
+	})
+
+	o.peek.SetNavigationKeys(k)
+	o.peek.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		o.App.Pages.RemovePage(OperationPeekId)
+	})
+}
+
+func (o *Operations) handleEvents() {
+	go o.HandleEvents(OperationsPageId, func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			o.setStyle()
+			go o.App.QueueUpdateDraw(func() {
+				o.renderTable()
+			})
+		}
+	})
+}
+
+// Render shows the view and starts refreshing operations periodically
+func (o *Operations) Render() {
+	o.Flex.Clear()
+	o.Flex.AddItem(o.header, 1, 0, false)
+	o.Flex.AddItem(o.table, 0, 1, true)
+
+	o.refresh(context.Background())
+	o.App.Pages.AddPage(OperationsPageId, o, true, true)
+	o.App.SetFocus(o.table)
+
+	// Render may be called while the view is shown, so the previous refresh is stopped
+	o.stopAutoRefresh()
+	ctx, cancel := context.WithCancel(context.Background())
+	o.stopRefresh = cancel
+	go o.refreshPeriodically(ctx)
+}
+
+// Hide closes the view and stops refreshing operations
+func (o *Operations) Hide() {
+	o.stopAutoRefresh()
+	o.App.Pages.RemovePage(OperationsPageId)
+}
+
+func (o *Operations) stopAutoRefresh() {
+	if o.stopRefresh != nil {
+		o.stopRefresh()
+		o.stopRefresh = nil
+	}
+}
+
+func (o *Operations) refreshPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(operationsRefreshPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !o.autoRefresh.Load() {
+				continue
+			}
+			operations, err := o.getOperations(ctx)
+			if err != nil {
+				// errors are shown only on manual refresh to not spam the user
+				continue
+			}
+			o.App.QueueUpdateDraw(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				o.operations = operations
+				o.renderTable()
+			})
+		}
+	}
+}
+
+func (o *Operations) getOperations(ctx context.Context) ([]mongo.Operation, error) {
+	ctx, cancel := context.WithTimeout(ctx, operationsRefreshPeriod)
+	defer cancel()
+	return o.Dao.GetCurrentOperations(ctx)
+}
+
+func (o *Operations) refresh(ctx context.Context) {
+	operations, err := o.getOperations(ctx)
+	if err != nil {
+		modal.ShowError(o.App.Pages, "Error getting current operations", err)
+		return
+	}
+	o.operations = operations
+	o.renderTable()
+}
+
+func (o *Operations) nextMinDuration() {
+	for i, duration := range operationMinDurations {
+		if duration == o.minDuration {
+			o.minDuration = operationMinDurations[(i+1)%len(operationMinDurations)]
+			return
+		}
+	}
+	o.minDuration = operationMinDurations[0]
+}
+
+func (o *Operations) showFilter() {
+	o.Flex.Clear()
+	o.Flex.AddItem(o.filterInput, 3, 0, true)
+	o.Flex.AddItem(o.header, 1, 0, false)
+	o.Flex.AddItem(o.table, 0, 1, false)
+	o.App.SetFocus(o.filterInput)
+}
+
+func (o *Operations) hideFilter() {
+	o.Flex.RemoveItem(o.filterInput)
+	o.App.SetFocus(o.table)
+}
+
+func (o *Operations) filteredOperations() []mongo.Operation {
+	return mongo.FilterOperations(o.operations, o.namespace, o.minDuration)
+}
+
+func (o *Operations) renderHeader() {
+	autoRefresh := "off"
+	if o.autoRefresh.Load() {
+		autoRefresh = fmt.Sprintf("every %s", operationsRefreshPeriod)
+	}
+	text := fmt.Sprintf("Operations: %d/%d | Running at least: %ds | Auto refresh: %s",
+		len(o.filteredOperations()), len(o.operations), o.minDuration, autoRefresh)
+	if o.namespace != "" {
+		text += fmt.Sprintf(" | Namespace: %s", o.namespace)
+	}
+	o.header.SetText(text)
+}
+
+func (o *Operations) renderTable() {
+	o.renderHeader()
+
+	// keep the selected operation when operations are refreshed
+	selectedOpId := o.selectedOpId()
+
+	styles := o.App.GetStyles()
+	o.table.Clear()
+	o.table.SetFixed(1, 0)
+
+	headers := []string{"OpId", "Namespace", "Op", "Running", "Client", "Plan", "Command"}
+	o.table.SetHeaderRow(headers, styles)
+
+	selectedRow := 1
+	for i, op := range o.filteredOperations() {
+		row := i + 1
+		if selectedOpId != nil && op.OpId == selectedOpId {
+			selectedRow = row
+		}
+
+		command := op.Command
+		if command == "" {
+			command = op.Description
+		}
+		if len(command) > operationCommandWidth {
+			command = command[:operationCommandWidth-3] + "..."
+		}
+
+		o.table.SetCell(row, 0, tview.NewTableCell(fmt.Sprintf(" %v ", op.OpId)).
+			SetReference(op.OpId))
+		o.table.SetCell(row, 1, tview.NewTableCell(" "+tview.Escape(op.Namespace)+" "))
+		o.table.SetCell(row, 2, tview.NewTableCell(" "+op.Type+" "))
+		o.table.SetCell(row, 3, tview.NewTableCell(fmt.Sprintf(" %ds ", op.SecsRunning)).
+			SetAlign(tview.AlignRight))
+		o.table.SetCell(row, 4, tview.NewTableCell(" "+op.Client+" "))
+		o.table.SetCell(row, 5, tview.NewTableCell(" "+tview.Escape(op.PlanSummary)+" "))
+		o.table.SetCell(row, 6, tview.NewTableCell(" "+tview.Escape(command)+" "))
+	}
+	o.table.Select(selectedRow, 0)
+}
+
+func (o *Operations) selectedOpId() any {
+	row, _ := o.table.GetSelection()
+	cell := o.table.GetCell(row, 0)
+	if row < 1 || cell == nil {
+		return nil
+	}
+	return cell.GetReference()
+}
+
+func (o *Operations) selectedOperation() *mongo.Operation {
+	opId := o.selectedOpId()
+	if opId == nil {
+		return nil
+	}
+	for _, op := range o.operations {
+		if op.OpId == opId {
+			return &op
+		}
+	}
+	return nil
+}
+
+func (o *Operations) peekOperation() {
+	op := o.selectedOperation()
+	if op == nil {
+		return
+	}
+
+	doc, err := mongo.ParseBsonDocument(op.Raw)
+	if err != nil {
+		modal.ShowError(o.App.Pages, "Error parsing operation", err)
+		return
+	}
+
+	o.peek.MoveToTop()
+	o.peek.SetText(primitives.Text{
+		Content: doc,
+		Color:   o.App.GetStyles().DocPeeker.ValueColor.Color(),
+		Align:   tview.AlignLeft,
+	})
+	o.App.Pages.AddPage(OperationPeekId, o.peek, true, true)
+}
+
+func (o *Operations) killOperation() {
+	op := o.selectedOperation()
+	if op == nil {
+		return
+	}
+	opId := op.OpId
+
+	o.killConfirm.SetConfirmButtonLabel("Kill")
+	o.killConfirm.SetText(fmt.Sprintf("Are you sure you want to kill operation [blue]%v[-] on %s running for %ds?",
+		opId, tview.Escape(op.Namespace), op.SecsRunning))
+	o.killConfirm.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		o.App.Pages.RemovePage(o.killConfirm.GetIdentifier())
+		if buttonLabel != "Kill" {
+			return
+		}
+		if err := o.Dao.KillOperation(context.Background(), opId); err != nil {
+			modal.ShowError(o.App.Pages, "Error killing operation", err)
+			return
+		}
+		o.refresh(context.Background())
+	})
+	o.App.Pages.AddPage(o.killConfirm.GetIdentifier(), o.killConfirm, true, true)
+}
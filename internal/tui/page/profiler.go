@@ -0,0 +1,334 @@
+package page
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+)
+
+const (
+	ProfilerPageId = "Profiler"
+	ProfilerPeekId = "ProfilerPeek"
+	// profilerEntriesLimit is the maximum number of slow operations fetched at once
+	profilerEntriesLimit = 200
+	// profilerCommandWidth is the maximum width of the command shown in the table
+	profilerCommandWidth = 80
+)
+
+// Profiler is a view that lists slow operations stored in system.profile
+// of a database and allows to change profiling level and threshold
+type Profiler struct {
+	*core.BaseElement
+	*core.Flex
+
+	header      *core.TextView
+	slowMsInput *core.InputField
+	table       *core.Table
+	peek        *core.ViewModal
+
+	db      string
+	status  mongo.ProfilingStatus
+	entries []mongo.ProfileEntry
+	// jumpFunc is called with the namespace of the selected operation
+	jumpFunc func(db, coll string)
+}
+
+func NewProfiler() *Profiler {
+	p := &Profiler{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		header:      core.NewTextView(),
+		slowMsInput: core.NewInputField(),
+		table:       core.NewTable(),
+		peek:        core.NewViewModal(),
+	}
+
+	p.SetIdentifier(ProfilerPageId)
+	p.SetAfterInitFunc(p.init)
+
+	return p
+}
+
+func (p *Profiler) init() error {
+	p.setLayout()
+	p.setStyle()
+	p.setKeybindings()
+
+	p.handleEvents()
+
+	return nil
+}
+
+func (p *Profiler) setLayout() {
+	p.SetBorder(true)
+	p.SetTitleAlign(tview.AlignCenter)
+	p.SetBorderPadding(0, 0, 1, 1)
+	p.SetDirection(tview.FlexRow)
+
+	p.table.SetSelectable(true, false)
+	p.slowMsInput.SetLabel(" Slow ms: ")
+	p.slowMsInput.SetBorder(true)
+	p.slowMsInput.SetAcceptanceFunc(tview.InputFieldInteger)
+
+	p.peek.SetBorder(true)
+	p.peek.SetTitle("Profiled Operation")
+	p.peek.SetTitleAlign(tview.AlignLeft)
+	p.peek.AddButtons([]string{"Close"})
+}
+
+func (p *Profiler) setStyle() {
+	styles := p.App.GetStyles()
+	p.SetStyle(styles)
+	p.header.SetStyle(styles)
+	p.slowMsInput.SetStyle(styles)
+	p.table.SetStyle(styles)
+
+	p.header.SetTextColor(styles.Content.StatusTextColor.Color())
+	p.table.SetSeparator(styles.Others.SeparatorSymbol.Rune())
+	p.table.SetBordersColor(styles.Others.SeparatorColor.Color())
+
+	p.peek.SetPeekStyle(styles)
+}
+
+func (p *Profiler) setKeybindings() {
+	k := p.App.GetKeys()
+
+	p.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case k.Contains(k.Navigation.MoveUp, event.Name()):
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case k.Contains(k.Navigation.MoveDown, event.Name()):
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case k.Contains(k.Profiler.Close, event.Name()):
+			p.Hide()
+			return nil
+		case k.Contains(k.Profiler.Refresh, event.Name()):
+			p.refresh(context.Background())
+			return nil
+		case k.Contains(k.Profiler.ChangeLevel, event.Name()):
+			p.nextLevel(context.Background())
+			return nil
+		case k.Contains(k.Profiler.SetSlowMs, event.Name()):
+			p.showSlowMsInput()
+			return nil
+		case k.Contains(k.Profiler.PeekOperation, event.Name()):
+			p.peekEntry()
+			return nil
+		case k.Contains(k.Profiler.JumpToIndexes, event.Name()):
+			p.jumpToIndexes()
+			return nil
+		}
+		return event
+	})
+
+	p.slowMsInput.SetDoneFunc(func(key tcell.Key) {
+		defer p.hideSlowMsInput()
+		if key != tcell.KeyEnter {
+			return
+		}
+		slowMs, err := strconv.ParseInt(p.slowMsInput.GetText(), 10, 64)
+		if err != nil {
+			modal.ShowError(p.App.Pages, "Invalid slowms value", err)
+			return
+		}
+		p.setProfilingLevel(context.Background(), p.status.Level, slowMs)
+	})
+
+	p.peek.SetNavigationKeys(k)
+	p.peek.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		p.App.Pages.RemovePage(ProfilerPeekId)
+	})
+}
+
+func (p *Profiler) handleEvents() {
+	go p.HandleEvents(ProfilerPageId, func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			p.setStyle()
+			go p.App.QueueUpdateDraw(func() {
+				p.renderTable()
+			})
+		}
+	})
+}
+
+// SetJumpFunc sets the function called when user wants to see
+// indexes of the collection of the selected operation
+func (p *Profiler) SetJumpFunc(f func(db, coll string)) {
+	p.jumpFunc = f
+}
+
+// Render shows slow operations of the given database
+func (p *Profiler) Render(db string) {
+	p.db = db
+	p.SetTitle(fmt.Sprintf(" Profiler - %s ", db))
+
+	p.Flex.Clear()
+	p.Flex.AddItem(p.header, 1, 0, false)
+	p.Flex.AddItem(p.table, 0, 1, true)
+
+	p.refresh(context.Background())
+	p.App.Pages.AddPage(ProfilerPageId, p, true, true)
+	p.App.SetFocus(p.table)
+}
+
+func (p *Profiler) Hide() {
+	p.App.Pages.RemovePage(ProfilerPageId)
+}
+
+func (p *Profiler) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	status, err := p.Dao.GetProfilingStatus(ctx, p.db)
+	if err != nil {
+		modal.ShowError(p.App.Pages, "Error getting profiling status", err)
+		return
+	}
+	entries, err := p.Dao.GetProfileEntries(ctx, p.db, profilerEntriesLimit)
+	if err != nil {
+		modal.ShowError(p.App.Pages, "Error getting profiled operations", err)
+		return
+	}
+	p.status = status
+	p.entries = entries
+	p.renderTable()
+}
+
+func (p *Profiler) nextLevel(ctx context.Context) {
+	level := mongo.ProfilingLevels[0]
+	for i, l := range mongo.ProfilingLevels {
+		if l == p.status.Level {
+			level = mongo.ProfilingLevels[(i+1)%len(mongo.ProfilingLevels)]
+			break
+		}
+	}
+	p.setProfilingLevel(ctx, level, p.status.SlowMs)
+}
+
+func (p *Profiler) setProfilingLevel(ctx context.Context, level int32, slowMs int64) {
+	if err := p.Dao.SetProfilingLevel(ctx, p.db, level, slowMs); err != nil {
+		modal.ShowError(p.App.Pages, "Error setting profiling level", err)
+		return
+	}
+	p.refresh(ctx)
+}
+
+func (p *Profiler) showSlowMsInput() {
+	p.slowMsInput.SetText(strconv.FormatInt(p.status.SlowMs, 10))
+	p.Flex.Clear()
+	p.Flex.AddItem(p.slowMsInput, 3, 0, true)
+	p.Flex.AddItem(p.header, 1, 0, false)
+	p.Flex.AddItem(p.table, 0, 1, false)
+	p.App.SetFocus(p.slowMsInput)
+}
+
+func (p *Profiler) hideSlowMsInput() {
+	p.Flex.RemoveItem(p.slowMsInput)
+	p.App.SetFocus(p.table)
+}
+
+func (p *Profiler) renderHeader() {
+	level := "off"
+	switch p.status.Level {
+	case 1:
+		level = "slow operations"
+	case 2:
+		level = "all operations"
+	}
+	p.header.SetText(fmt.Sprintf("Database: %s | Level: %d (%s) | Slow ms: %d | Entries: %d",
+		p.db, p.status.Level, level, p.status.SlowMs, len(p.entries)))
+}
+
+func (p *Profiler) renderTable() {
+	p.renderHeader()
+
+	styles := p.App.GetStyles()
+	p.table.Clear()
+	p.table.SetFixed(1, 0)
+
+	headers := []string{"Time", "Namespace", "Op", "Millis", "Plan", "Examined", "Returned", "Ratio", "Command"}
+	p.table.SetHeaderRow(headers, styles)
+
+	for i, entry := range p.entries {
+		row := i + 1
+
+		command := entry.Command
+		if len(command) > profilerCommandWidth {
+			command = command[:profilerCommandWidth-3] + "..."
+		}
+
+		p.table.SetCell(row, 0, tview.NewTableCell(" "+entry.Timestamp.Local().Format(time.DateTime)+" ").
+			SetReference(i))
+		p.table.SetCell(row, 1, tview.NewTableCell(" "+tview.Escape(entry.Namespace)+" "))
+		p.table.SetCell(row, 2, tview.NewTableCell(" "+entry.Op+" "))
+		p.table.SetCell(row, 3, tview.NewTableCell(fmt.Sprintf(" %d ", entry.Millis)).
+			SetAlign(tview.AlignRight))
+		p.table.SetCell(row, 4, tview.NewTableCell(" "+tview.Escape(entry.PlanSummary)+" "))
+		p.table.SetCell(row, 5, tview.NewTableCell(fmt.Sprintf(" %d ", entry.DocsExamined)).
+			SetAlign(tview.AlignRight))
+		p.table.SetCell(row, 6, tview.NewTableCell(fmt.Sprintf(" %d ", entry.NReturned)).
+			SetAlign(tview.AlignRight))
+		p.table.SetCell(row, 7, tview.NewTableCell(fmt.Sprintf(" %.1f ", entry.ExaminedRatio())).
+			SetAlign(tview.AlignRight))
+		p.table.SetCell(row, 8, tview.NewTableCell(" "+tview.Escape(command)+" "))
+	}
+	p.table.Select(1, 0)
+}
+
+func (p *Profiler) selectedEntry() *mongo.ProfileEntry {
+	row, _ := p.table.GetSelection()
+	cell := p.table.GetCell(row, 0)
+	if row < 1 || cell == nil {
+		return nil
+	}
+	index, ok := cell.GetReference().(int)
+	if !ok || index >= len(p.entries) {
+		return nil
+	}
+	return &p.entries[index]
+}
+
+func (p *Profiler) peekEntry() {
+	entry := p.selectedEntry()
+	if entry == nil {
+		return
+	}
+
+	doc, err := mongo.ParseBsonDocument(entry.Raw)
+	if err != nil {
+		modal.ShowError(p.App.Pages, "Error parsing profiled operation", err)
+		return
+	}
+
+	p.peek.MoveToTop()
+	p.peek.SetText(primitives.Text{
+		Content: doc,
+		Color:   p.App.GetStyles().DocPeeker.ValueColor.Color(),
+		Align:   tview.AlignLeft,
+	})
+	p.App.Pages.AddPage(ProfilerPeekId, p.peek, true, true)
+}
+
+func (p *Profiler) jumpToIndexes() {
+	entry := p.selectedEntry()
+	if entry == nil || p.jumpFunc == nil {
+		return
+	}
+	coll := entry.Collection()
+	if coll == "" {
+		modal.ShowInfo(p.App.Pages, "Operation is not related to any collection")
+		return
+	}
+	p.Hide()
+	p.jumpFunc(p.db, coll)
+}
@@ -0,0 +1,265 @@
+package page
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
+)
+
+const (
+	ShardingPageId = "Sharding"
+)
+
+// Sharding is a view that shows shards, sharded collections with their
+// chunk distribution and the balancer state of a sharded cluster
+type Sharding struct {
+	*core.BaseElement
+	*core.Flex
+
+	header      *core.TextView
+	shards      *core.Table
+	collections *core.Table
+
+	status mongo.ShardingStatus
+	// jumpFunc is called with the namespace of the selected collection
+	jumpFunc func(db, coll string)
+}
+
+func NewSharding() *Sharding {
+	s := &Sharding{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		header:      core.NewTextView(),
+		shards:      core.NewTable(),
+		collections: core.NewTable(),
+	}
+
+	s.SetIdentifier(ShardingPageId)
+	s.SetAfterInitFunc(s.init)
+
+	return s
+}
+
+func (s *Sharding) init() error {
+	s.setLayout()
+	s.setStyle()
+	s.setKeybindings()
+
+	s.handleEvents()
+
+	return nil
+}
+
+func (s *Sharding) setLayout() {
+	s.SetBorder(true)
+	s.SetTitle(" Sharding ")
+	s.SetTitleAlign(tview.AlignCenter)
+	s.SetBorderPadding(0, 0, 1, 1)
+	s.SetDirection(tview.FlexRow)
+
+	s.shards.SetBorder(true)
+	s.shards.SetTitle(" Shards ")
+	s.shards.SetTitleAlign(tview.AlignLeft)
+	s.shards.SetSelectable(false, false)
+
+	s.collections.SetBorder(true)
+	s.collections.SetTitle(" Sharded collections ")
+	s.collections.SetTitleAlign(tview.AlignLeft)
+	s.collections.SetSelectable(true, false)
+}
+
+func (s *Sharding) setStyle() {
+	styles := s.App.GetStyles()
+	s.SetStyle(styles)
+	s.header.SetStyle(styles)
+	s.shards.SetStyle(styles)
+	s.collections.SetStyle(styles)
+
+	s.header.SetTextColor(styles.Content.StatusTextColor.Color())
+	for _, table := range []*core.Table{s.shards, s.collections} {
+		table.SetSeparator(styles.Others.SeparatorSymbol.Rune())
+		table.SetBordersColor(styles.Others.SeparatorColor.Color())
+	}
+}
+
+func (s *Sharding) setKeybindings() {
+	k := s.App.GetKeys()
+
+	s.collections.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case k.Contains(k.Navigation.MoveUp, event.Name()):
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case k.Contains(k.Navigation.MoveDown, event.Name()):
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case k.Contains(k.Sharding.Close, event.Name()):
+			s.Hide()
+			return nil
+		case k.Contains(k.Sharding.Refresh, event.Name()):
+			s.refresh(context.Background())
+			return nil
+		case k.Contains(k.Sharding.JumpToCollection, event.Name()):
+			s.jumpToCollection()
+			return nil
+		}
+		return event
+	})
+}
+
+func (s *Sharding) handleEvents() {
+	go s.HandleEvents(ShardingPageId, func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			s.setStyle()
+			go s.App.QueueUpdateDraw(func() {
+				s.render()
+			})
+		}
+	})
+}
+
+// SetJumpFunc sets the function called when user selects a sharded collection
+func (s *Sharding) SetJumpFunc(f func(db, coll string)) {
+	s.jumpFunc = f
+}
+
+// Render shows the sharding status, an info is shown instead
+// when the client is not connected to mongos
+func (s *Sharding) Render() {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	status, err := s.Dao.GetShardingStatus(ctx)
+	if errors.Is(err, mongo.ErrNotMongos) {
+		modal.ShowInfo(s.App.Pages, "Sharding status is available only when connected to mongos")
+		return
+	}
+	if err != nil {
+		modal.ShowError(s.App.Pages, "Error getting sharding status", err)
+		return
+	}
+	s.status = status
+	s.render()
+
+	s.App.Pages.AddPage(ShardingPageId, s, true, true)
+	s.App.SetFocus(s.collections)
+}
+
+func (s *Sharding) Hide() {
+	s.App.Pages.RemovePage(ShardingPageId)
+}
+
+func (s *Sharding) refresh(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	status, err := s.Dao.GetShardingStatus(ctx)
+	if err != nil {
+		modal.ShowError(s.App.Pages, "Error getting sharding status", err)
+		return
+	}
+	s.status = status
+	s.render()
+}
+
+func (s *Sharding) render() {
+	s.Flex.Clear()
+	s.Flex.AddItem(s.header, 1, 0, false)
+	// shards table has a border and a header row
+	s.Flex.AddItem(s.shards, len(s.status.Shards)+3, 0, false)
+	s.Flex.AddItem(s.collections, 0, 1, true)
+
+	s.renderHeader()
+	s.renderShards()
+	s.renderCollections()
+}
+
+func (s *Sharding) renderHeader() {
+	balancer := s.status.Balancer
+	mode := balancer.Mode
+	if mode == "" {
+		mode = "unknown"
+	}
+	inRound := "idle"
+	if balancer.InRound {
+		inRound = "balancing"
+	}
+	s.header.SetText(fmt.Sprintf("Shards: %d | Sharded collections: %d | Balancer: %s (%s, %d rounds)",
+		len(s.status.Shards), len(s.status.Collections), mode, inRound, balancer.Rounds))
+}
+
+func (s *Sharding) setHeaderRow(table *core.Table, headers []string) {
+	table.Clear()
+	table.SetFixed(1, 0)
+	table.SetHeaderRow(headers, s.App.GetStyles())
+}
+
+func (s *Sharding) renderShards() {
+	s.setHeaderRow(s.shards, []string{"Shard", "Host", "State", "Tags"})
+
+	for i, shard := range s.status.Shards {
+		row := i + 1
+		state := "active"
+		if shard.Draining {
+			state = "draining"
+		}
+		s.shards.SetCell(row, 0, tview.NewTableCell(" "+tview.Escape(shard.Id)+" "))
+		s.shards.SetCell(row, 1, tview.NewTableCell(" "+tview.Escape(shard.Host)+" "))
+		s.shards.SetCell(row, 2, tview.NewTableCell(" "+state+" "))
+		s.shards.SetCell(row, 3, tview.NewTableCell(" "+tview.Escape(strings.Join(shard.Tags, ", "))+" "))
+	}
+}
+
+func (s *Sharding) renderCollections() {
+	headers := []string{"Namespace", "Shard key", "Unique", "Balancing", "Chunks"}
+	for _, shard := range s.status.Shards {
+		headers = append(headers, shard.Id)
+	}
+	s.setHeaderRow(s.collections, headers)
+
+	for i, coll := range s.status.Collections {
+		row := i + 1
+		balancing := "enabled"
+		if coll.NoBalance {
+			balancing = "disabled"
+		}
+		total := coll.TotalChunks()
+
+		s.collections.SetCell(row, 0, tview.NewTableCell(" "+tview.Escape(coll.Namespace)+" ").
+			SetReference(coll.Namespace))
+		s.collections.SetCell(row, 1, tview.NewTableCell(" "+tview.Escape(coll.Key)+" "))
+		s.collections.SetCell(row, 2, tview.NewTableCell(fmt.Sprintf(" %v ", coll.Unique)))
+		s.collections.SetCell(row, 3, tview.NewTableCell(" "+balancing+" "))
+		s.collections.SetCell(row, 4, tview.NewTableCell(fmt.Sprintf(" %d ", total)).
+			SetAlign(tview.AlignRight))
+
+		for col, shard := range s.status.Shards {
+			chunks := coll.Chunks[shard.Id]
+			text := "-"
+			if chunks > 0 {
+				text = fmt.Sprintf("%d (%.0f%%)", chunks, float64(chunks)/float64(total)*100)
+			}
+			s.collections.SetCell(row, col+5, tview.NewTableCell(" "+text+" ").
+				SetAlign(tview.AlignRight))
+		}
+	}
+	s.collections.Select(1, 0)
+}
+
+func (s *Sharding) jumpToCollection() {
+	row, _ := s.collections.GetSelection()
+	if row < 1 || row > len(s.status.Collections) || s.jumpFunc == nil {
+		return
+	}
+	coll := s.status.Collections[row-1]
+	s.Hide()
+	s.jumpFunc(coll.Database(), coll.Collection())
+}
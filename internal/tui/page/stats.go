@@ -0,0 +1,290 @@
+package page
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
+	"github.com/kopecmaciej/vi-mongo/internal/util"
+)
+
+const (
+	StatsPageId = "Stats"
+)
+
+// statsTimeout limits reading statistics of all collections of the database
+const statsTimeout = 30 * time.Second
+
+// Stats is a view that shows storage statistics of a collection,
+// or of a database together with all its collections
+type Stats struct {
+	*core.BaseElement
+	*core.Flex
+
+	summary     *core.Table
+	collections *core.Table
+
+	db   string
+	coll string
+	// collStats are set when statistics of the database are shown
+	collStats []mongo.CollectionStats
+	// jumpFunc is called with the namespace of the selected collection
+	jumpFunc func(db, coll string)
+}
+
+func NewStats() *Stats {
+	s := &Stats{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		summary:     core.NewTable(),
+		collections: core.NewTable(),
+	}
+
+	s.SetIdentifier(StatsPageId)
+	s.SetAfterInitFunc(s.init)
+
+	return s
+}
+
+func (s *Stats) init() error {
+	s.setLayout()
+	s.setStyle()
+	s.setKeybindings()
+
+	s.handleEvents()
+
+	return nil
+}
+
+func (s *Stats) setLayout() {
+	s.SetBorder(true)
+	s.SetTitleAlign(tview.AlignCenter)
+	s.SetBorderPadding(0, 0, 1, 1)
+	s.SetDirection(tview.FlexRow)
+
+	s.summary.SetBorder(true)
+	s.summary.SetTitle(" Summary ")
+	s.summary.SetTitleAlign(tview.AlignLeft)
+	s.summary.SetSelectable(false, false)
+
+	s.collections.SetBorder(true)
+	s.collections.SetTitle(" Collections by storage size ")
+	s.collections.SetTitleAlign(tview.AlignLeft)
+	s.collections.SetSelectable(true, false)
+}
+
+func (s *Stats) setStyle() {
+	styles := s.App.GetStyles()
+	s.SetStyle(styles)
+	s.summary.SetStyle(styles)
+	s.collections.SetStyle(styles)
+
+	for _, table := range []*core.Table{s.summary, s.collections} {
+		table.SetSeparator(styles.Others.SeparatorSymbol.Rune())
+		table.SetBordersColor(styles.Others.SeparatorColor.Color())
+	}
+}
+
+func (s *Stats) setKeybindings() {
+	k := s.App.GetKeys()
+
+	s.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case k.Contains(k.Navigation.MoveUp, event.Name()):
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case k.Contains(k.Navigation.MoveDown, event.Name()):
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case k.Contains(k.Stats.Close, event.Name()):
+			s.Hide()
+			return nil
+		case k.Contains(k.Stats.Refresh, event.Name()):
+			s.refresh()
+			return nil
+		case k.Contains(k.Stats.JumpToCollection, event.Name()):
+			s.jumpToCollection()
+			return nil
+		}
+		return event
+	})
+}
+
+func (s *Stats) handleEvents() {
+	go s.HandleEvents(StatsPageId, func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			s.setStyle()
+		}
+	})
+}
+
+// SetJumpFunc sets the function called when user selects a collection
+func (s *Stats) SetJumpFunc(f func(db, coll string)) {
+	s.jumpFunc = f
+}
+
+// Render shows statistics of the collection, or of the database
+// with all its collections when coll is empty
+func (s *Stats) Render(db, coll string) {
+	s.db, s.coll = db, coll
+	if !s.refresh() {
+		return
+	}
+
+	s.App.Pages.AddPage(StatsPageId, s, true, true)
+	if s.coll == "" {
+		s.App.SetFocus(s.collections)
+	} else {
+		s.App.SetFocus(s)
+	}
+}
+
+func (s *Stats) Hide() {
+	s.App.Pages.RemovePage(StatsPageId)
+}
+
+func (s *Stats) refresh() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), statsTimeout)
+	defer cancel()
+
+	s.Flex.Clear()
+	if s.coll != "" {
+		stats, err := s.Dao.GetCollectionStats(ctx, s.db, s.coll)
+		if err != nil {
+			modal.ShowError(s.App.Pages, "Error getting collection stats", err)
+			return false
+		}
+		s.SetTitle(fmt.Sprintf(" Stats of %s.%s ", s.db, s.coll))
+		s.renderCollectionSummary(stats)
+		s.Flex.AddItem(s.summary, 0, 1, false)
+		return true
+	}
+
+	stats, err := s.Dao.GetDatabaseStats(ctx, s.db)
+	if err != nil {
+		modal.ShowError(s.App.Pages, "Error getting database stats", err)
+		return false
+	}
+	collStats, err := s.Dao.ListCollectionStats(ctx, s.db)
+	if err != nil {
+		modal.ShowError(s.App.Pages, "Error getting collection stats", err)
+		return false
+	}
+	sort.SliceStable(collStats, func(i, j int) bool {
+		return collStats[i].StorageSize > collStats[j].StorageSize
+	})
+	s.collStats = collStats
+
+	s.SetTitle(fmt.Sprintf(" Stats of %s ", s.db))
+	s.renderDatabaseSummary(stats)
+	s.renderCollections()
+	// summary table has a border and one row per metric
+	s.Flex.AddItem(s.summary, s.summary.GetRowCount()+2, 0, false)
+	s.Flex.AddItem(s.collections, 0, 1, true)
+	return true
+}
+
+func (s *Stats) renderDatabaseSummary(stats mongo.DatabaseStats) {
+	s.setSummaryRows([][2]string{
+		{"Collections", fmt.Sprintf("%d (%d views)", stats.Collections, stats.Views)},
+		{"Documents", util.FormatCount(stats.Objects)},
+		{"Average document size", util.FormatBytes(float64(stats.AvgObjSize))},
+		{"Data size", util.FormatBytes(float64(stats.DataSize))},
+		{"Storage size", util.FormatBytes(float64(stats.StorageSize))},
+		{"Free storage", util.FormatBytes(float64(stats.FreeStorageSize))},
+		{"Indexes", fmt.Sprintf("%d (%s)", stats.Indexes, util.FormatBytes(float64(stats.IndexSize)))},
+		{"Compression ratio", formatRatio(stats.CompressionRatio())},
+	})
+}
+
+func (s *Stats) renderCollectionSummary(stats mongo.CollectionStats) {
+	capped := "no"
+	if stats.Capped {
+		capped = fmt.Sprintf("yes (max %s", util.FormatBytes(float64(stats.MaxSize)))
+		if stats.MaxDocuments > 0 {
+			capped += fmt.Sprintf(", %d documents", stats.MaxDocuments)
+		}
+		capped += ")"
+	}
+	sharded := "no"
+	if stats.Sharded {
+		sharded = "yes"
+	}
+	s.setSummaryRows([][2]string{
+		{"Documents", util.FormatCount(stats.Count)},
+		{"Average document size", util.FormatBytes(float64(stats.AvgObjSize))},
+		{"Data size", util.FormatBytes(float64(stats.Size))},
+		{"Storage size", util.FormatBytes(float64(stats.StorageSize))},
+		{"Free storage", util.FormatBytes(float64(stats.FreeStorageSize))},
+		{"Indexes", fmt.Sprintf("%d (%s)", stats.Indexes, util.FormatBytes(float64(stats.TotalIndexSize)))},
+		{"Compression ratio", formatRatio(stats.CompressionRatio())},
+		{"Capped", capped},
+		{"Sharded", sharded},
+	})
+}
+
+func (s *Stats) setSummaryRows(rows [][2]string) {
+	styles := s.App.GetStyles()
+	s.summary.Clear()
+	for row, metric := range rows {
+		s.summary.SetCell(row, 0, tview.NewTableCell(" "+metric[0]+" ").
+			SetTextColor(styles.Content.ColumnKeyColor.Color()))
+		s.summary.SetCell(row, 1, tview.NewTableCell(" "+metric[1]+" "))
+	}
+}
+
+func (s *Stats) renderCollections() {
+	styles := s.App.GetStyles()
+	s.collections.Clear()
+	s.collections.SetFixed(1, 0)
+
+	headers := []string{"Collection", "Documents", "Avg size", "Data size", "Storage", "Indexes", "Compression", "Capped", "Sharded"}
+	s.collections.SetHeaderRow(headers, styles)
+
+	for i, stats := range s.collStats {
+		row := i + 1
+		values := []string{
+			util.FormatCount(stats.Count),
+			util.FormatBytes(float64(stats.AvgObjSize)),
+			util.FormatBytes(float64(stats.Size)),
+			util.FormatBytes(float64(stats.StorageSize)),
+			util.FormatBytes(float64(stats.TotalIndexSize)),
+			formatRatio(stats.CompressionRatio()),
+			fmt.Sprintf("%v", stats.Capped),
+			fmt.Sprintf("%v", stats.Sharded),
+		}
+		s.collections.SetCell(row, 0, tview.NewTableCell(" "+tview.Escape(stats.Name)+" ").
+			SetReference(stats.Name))
+		for col, value := range values {
+			s.collections.SetCell(row, col+1, tview.NewTableCell(" "+value+" ").
+				SetAlign(tview.AlignRight))
+		}
+	}
+	s.collections.Select(1, 0)
+}
+
+func (s *Stats) jumpToCollection() {
+	if s.coll != "" || s.jumpFunc == nil {
+		return
+	}
+	row, _ := s.collections.GetSelection()
+	coll, _ := s.collections.GetCell(row, 0).GetReference().(string)
+	if coll == "" {
+		return
+	}
+	s.Hide()
+	s.jumpFunc(s.db, coll)
+}
+
+func formatRatio(ratio float64) string {
+	if ratio == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2fx", ratio)
+}
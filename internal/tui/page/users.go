@@ -0,0 +1,472 @@
+package page
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/kopecmaciej/tview"
+	"github.com/kopecmaciej/vi-mongo/internal/manager"
+	"github.com/kopecmaciej/vi-mongo/internal/mongo"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/core"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/modal"
+	"github.com/kopecmaciej/vi-mongo/internal/tui/primitives"
+)
+
+const (
+	UsersPageId          = "Users"
+	UsersFormId          = "UsersForm"
+	UsersPrivilegesId    = "UsersPrivileges"
+	UserDropConfirmId    = "UserDropConfirm"
+	usersCommandTimeout  = 10 * time.Second
+	usersFormInputWidth  = 40
+	usersRolesInputLabel = "Roles (role or role@db, comma separated)"
+)
+
+// Users is a view that lists users and roles of a database
+// and allows to manage users
+type Users struct {
+	*core.BaseElement
+	*core.Flex
+
+	header      *core.TextView
+	table       *core.Table
+	form        *core.FormModal
+	privileges  *core.ViewModal
+	dropConfirm *modal.Confirm
+
+	db    string
+	users []mongo.User
+	roles []mongo.Role
+	// showRoles switches the table between users and roles
+	showRoles bool
+}
+
+func NewUsers() *Users {
+	u := &Users{
+		BaseElement: core.NewBaseElement(),
+		Flex:        core.NewFlex(),
+		header:      core.NewTextView(),
+		table:       core.NewTable(),
+		form:        core.NewFormModal(),
+		privileges:  core.NewViewModal(),
+		dropConfirm: modal.NewConfirm(UserDropConfirmId),
+	}
+
+	u.SetIdentifier(UsersPageId)
+	u.SetAfterInitFunc(u.init)
+
+	return u
+}
+
+func (u *Users) init() error {
+	u.setLayout()
+	u.setStyle()
+	u.setKeybindings()
+
+	if err := u.dropConfirm.Init(u.App); err != nil {
+		return err
+	}
+
+	u.handleEvents()
+
+	return nil
+}
+
+func (u *Users) setLayout() {
+	u.SetBorder(true)
+	u.SetTitleAlign(tview.AlignCenter)
+	u.SetBorderPadding(0, 0, 1, 1)
+	u.SetDirection(tview.FlexRow)
+
+	u.table.SetSelectable(true, false)
+
+	u.form.SetBorder(true)
+	u.form.SetTitleAlign(tview.AlignCenter)
+	u.form.Form.SetBorderPadding(1, 1, 2, 2)
+
+	u.privileges.SetBorder(true)
+	u.privileges.SetTitleAlign(tview.AlignLeft)
+	u.privileges.AddButtons([]string{"Close"})
+
+	u.Flex.AddItem(u.header, 1, 0, false)
+	u.Flex.AddItem(u.table, 0, 1, true)
+}
+
+func (u *Users) setStyle() {
+	styles := u.App.GetStyles()
+	u.SetStyle(styles)
+	u.header.SetStyle(styles)
+	u.table.SetStyle(styles)
+	u.form.SetStyle(styles)
+	u.privileges.SetStyle(styles)
+
+	u.header.SetTextColor(styles.Content.StatusTextColor.Color())
+	u.table.SetSeparator(styles.Others.SeparatorSymbol.Rune())
+	u.table.SetBordersColor(styles.Others.SeparatorColor.Color())
+
+	u.form.Form.SetFieldTextColor(styles.Connection.FormInputColor.Color())
+	u.form.Form.SetFieldBackgroundColor(styles.Connection.FormInputBackgroundColor.Color())
+	u.form.Form.SetLabelColor(styles.Connection.FormLabelColor.Color())
+}
+
+func (u *Users) setKeybindings() {
+	k := u.App.GetKeys()
+
+	u.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case k.Contains(k.Navigation.MoveUp, event.Name()):
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case k.Contains(k.Navigation.MoveDown, event.Name()):
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case k.Contains(k.Users.Close, event.Name()):
+			u.Hide()
+			return nil
+		case k.Contains(k.Users.Refresh, event.Name()):
+			u.refresh(context.Background())
+			return nil
+		case k.Contains(k.Users.ToggleRoles, event.Name()):
+			u.showRoles = !u.showRoles
+			u.renderTable()
+			return nil
+		case k.Contains(k.Users.ShowPrivileges, event.Name()):
+			u.showPrivileges()
+			return nil
+		}
+
+		// user management is not available in the roles view
+		if u.showRoles {
+			return event
+		}
+		switch {
+		case k.Contains(k.Users.AddUser, event.Name()):
+			u.showCreateUserForm()
+			return nil
+		case k.Contains(k.Users.DropUser, event.Name()):
+			u.dropUser()
+			return nil
+		case k.Contains(k.Users.ChangePassword, event.Name()):
+			u.showChangePasswordForm()
+			return nil
+		case k.Contains(k.Users.GrantRoles, event.Name()):
+			u.showRolesForm(true)
+			return nil
+		case k.Contains(k.Users.RevokeRoles, event.Name()):
+			u.showRolesForm(false)
+			return nil
+		}
+		return event
+	})
+
+	u.form.SetCancelFunc(u.hideForm)
+
+	u.privileges.SetNavigationKeys(k)
+	u.privileges.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		u.App.Pages.RemovePage(UsersPrivilegesId)
+	})
+}
+
+func (u *Users) handleEvents() {
+	go u.HandleEvents(UsersPageId, func(event manager.EventMsg) {
+		switch event.Message.Type {
+		case manager.StyleChanged:
+			u.setStyle()
+			go u.App.QueueUpdateDraw(func() {
+				u.renderTable()
+			})
+		}
+	})
+}
+
+// Render shows users and roles of the given database
+func (u *Users) Render(db string) {
+	u.db = db
+	u.showRoles = false
+	u.SetTitle(fmt.Sprintf(" Users - %s ", db))
+
+	if !u.refresh(context.Background()) {
+		return
+	}
+	u.App.Pages.AddPage(UsersPageId, u, true, true)
+	u.App.SetFocus(u.table)
+}
+
+func (u *Users) Hide() {
+	u.App.Pages.RemovePage(UsersPageId)
+}
+
+// refresh fetches users and roles, returns false when fetching failed
+func (u *Users) refresh(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, usersCommandTimeout)
+	defer cancel()
+
+	users, err := u.Dao.ListUsers(ctx, u.db)
+	if err != nil {
+		modal.ShowError(u.App.Pages, "Error listing users", err)
+		return false
+	}
+	roles, err := u.Dao.ListRoles(ctx, u.db)
+	if err != nil {
+		modal.ShowError(u.App.Pages, "Error listing roles", err)
+		return false
+	}
+	u.users = users
+	u.roles = roles
+	u.renderTable()
+	return true
+}
+
+func (u *Users) renderHeader() {
+	view := "users"
+	if u.showRoles {
+		view = "roles"
+	}
+	u.header.SetText(fmt.Sprintf("Database: %s | Users: %d | Roles: %d | Showing: %s",
+		u.db, len(u.users), len(u.roles), view))
+}
+
+func (u *Users) renderTable() {
+	u.renderHeader()
+
+	u.table.Clear()
+	u.table.SetFixed(1, 0)
+
+	if u.showRoles {
+		u.renderRoles()
+	} else {
+		u.renderUsers()
+	}
+	u.table.Select(1, 0)
+}
+
+func (u *Users) renderUsers() {
+	u.table.SetHeaderRow([]string{"User", "Roles", "Mechanisms"}, u.App.GetStyles())
+
+	for i, user := range u.users {
+		row := i + 1
+		u.table.SetCell(row, 0, tview.NewTableCell(" "+tview.Escape(user.Name)+" "))
+		u.table.SetCell(row, 1, tview.NewTableCell(" "+tview.Escape(joinRoles(user.Roles))+" "))
+		u.table.SetCell(row, 2, tview.NewTableCell(" "+strings.Join(user.Mechanisms, ", ")+" "))
+	}
+}
+
+func (u *Users) renderRoles() {
+	u.table.SetHeaderRow([]string{"Role", "Builtin", "Inherits", "Privileges"}, u.App.GetStyles())
+
+	for i, role := range u.roles {
+		row := i + 1
+		u.table.SetCell(row, 0, tview.NewTableCell(" "+tview.Escape(role.Name)+" "))
+		u.table.SetCell(row, 1, tview.NewTableCell(fmt.Sprintf(" %v ", role.IsBuiltin)))
+		u.table.SetCell(row, 2, tview.NewTableCell(" "+tview.Escape(joinRoles(role.Roles))+" "))
+		u.table.SetCell(row, 3, tview.NewTableCell(fmt.Sprintf(" %d ", len(role.Privileges))).
+			SetAlign(tview.AlignRight))
+	}
+}
+
+func joinRoles(roles []mongo.RoleRef) string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+func (u *Users) selectedUser() *mongo.User {
+	row, _ := u.table.GetSelection()
+	if u.showRoles || row < 1 || row > len(u.users) {
+		return nil
+	}
+	return &u.users[row-1]
+}
+
+func (u *Users) selectedRole() *mongo.Role {
+	row, _ := u.table.GetSelection()
+	if !u.showRoles || row < 1 || row > len(u.roles) {
+		return nil
+	}
+	return &u.roles[row-1]
+}
+
+// showPrivileges shows effective privileges of the selected user
+// or privileges of the selected role
+func (u *Users) showPrivileges() {
+	var title string
+	var privileges []mongo.Privilege
+
+	if u.showRoles {
+		role := u.selectedRole()
+		if role == nil {
+			return
+		}
+		title = fmt.Sprintf(" Privileges of role %s ", role.Name)
+		privileges = role.Privileges
+	} else {
+		user := u.selectedUser()
+		if user == nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), usersCommandTimeout)
+		defer cancel()
+		detailed, err := u.Dao.GetUser(ctx, u.db, user.Name)
+		if err != nil {
+			modal.ShowError(u.App.Pages, "Error getting user privileges", err)
+			return
+		}
+		title = fmt.Sprintf(" Effective privileges of %s ", user.Name)
+		privileges = detailed.Privileges
+	}
+
+	styles := u.App.GetStyles()
+	var sb strings.Builder
+	if len(privileges) == 0 {
+		sb.WriteString("No privileges")
+	}
+	for _, privilege := range privileges {
+		sb.WriteString(fmt.Sprintf("[%s]%s[-]\n  %s\n",
+			styles.Others.ModalTextColor.Color(), tview.Escape(privilege.Resource),
+			strings.Join(privilege.Actions, ", ")))
+	}
+
+	u.privileges.SetTitle(title)
+	u.privileges.MoveToTop()
+	u.privileges.SetText(primitives.Text{
+		Content: sb.String(),
+		Color:   styles.Others.ModalSecondaryTextColor.Color(),
+		Align:   tview.AlignLeft,
+	})
+	u.App.Pages.AddPage(UsersPrivilegesId, u.privileges, true, true)
+}
+
+func (u *Users) showForm(title, submitLabel string, submit func() error) {
+	u.form.SetTitle(title)
+	u.form.Form.AddButton(submitLabel, func() {
+		if err := submit(); err != nil {
+			modal.ShowError(u.App.Pages, "Error managing user", err)
+			return
+		}
+		u.hideForm()
+		u.refresh(context.Background())
+	})
+	u.form.Form.AddButton("Cancel", u.hideForm)
+	u.App.Pages.AddPage(UsersFormId, u.form, true, true)
+}
+
+func (u *Users) hideForm() {
+	u.App.Pages.RemovePage(UsersFormId)
+	u.App.SetFocus(u.table)
+}
+
+func (u *Users) formText(label string) string {
+	return u.form.Form.GetFormItemByLabel(label).(*tview.InputField).GetText()
+}
+
+func (u *Users) showCreateUserForm() {
+	u.form.Form.Clear(true)
+	u.form.Form.AddInputField("Name", "", usersFormInputWidth, nil, nil)
+	u.form.Form.AddPasswordField("Password", "", usersFormInputWidth, '*', nil)
+	u.form.Form.AddInputField(usersRolesInputLabel, "", usersFormInputWidth, nil, nil)
+
+	u.showForm(fmt.Sprintf(" Create user in %s ", u.db), "Create", func() error {
+		name := strings.TrimSpace(u.formText("Name"))
+		password := u.formText("Password")
+		if name == "" || password == "" {
+			return fmt.Errorf("name and password are required")
+		}
+		roles, err := mongo.ParseRoleRefs(u.formText(usersRolesInputLabel), u.db)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), usersCommandTimeout)
+		defer cancel()
+		return u.Dao.CreateUser(ctx, u.db, name, password, roles)
+	})
+}
+
+func (u *Users) showChangePasswordForm() {
+	user := u.selectedUser()
+	if user == nil {
+		return
+	}
+	name := user.Name
+
+	u.form.Form.Clear(true)
+	u.form.Form.AddPasswordField("New password", "", usersFormInputWidth, '*', nil)
+	u.form.Form.AddPasswordField("Repeat password", "", usersFormInputWidth, '*', nil)
+
+	u.showForm(fmt.Sprintf(" Change password of %s ", name), "Change", func() error {
+		password := u.formText("New password")
+		if password == "" {
+			return fmt.Errorf("password is required")
+		}
+		if password != u.formText("Repeat password") {
+			return fmt.Errorf("passwords do not match")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), usersCommandTimeout)
+		defer cancel()
+		return u.Dao.ChangeUserPassword(ctx, u.db, name, password)
+	})
+}
+
+// showRolesForm shows the form for granting or revoking roles of the selected user
+func (u *Users) showRolesForm(grant bool) {
+	user := u.selectedUser()
+	if user == nil {
+		return
+	}
+	name := user.Name
+
+	title, submitLabel, initial := fmt.Sprintf(" Grant roles to %s ", name), "Grant", ""
+	if !grant {
+		title, submitLabel, initial = fmt.Sprintf(" Revoke roles from %s ", name), "Revoke", joinRoles(user.Roles)
+	}
+
+	u.form.Form.Clear(true)
+	u.form.Form.AddInputField(usersRolesInputLabel, initial, usersFormInputWidth, nil, nil)
+
+	u.showForm(title, submitLabel, func() error {
+		roles, err := mongo.ParseRoleRefs(u.formText(usersRolesInputLabel), u.db)
+		if err != nil {
+			return err
+		}
+		if len(roles) == 0 {
+			return fmt.Errorf("at least one role is required")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), usersCommandTimeout)
+		defer cancel()
+		if grant {
+			return u.Dao.GrantRoles(ctx, u.db, name, roles)
+		}
+		return u.Dao.RevokeRoles(ctx, u.db, name, roles)
+	})
+}
+
+func (u *Users) dropUser() {
+	user := u.selectedUser()
+	if user == nil {
+		return
+	}
+	name := user.Name
+
+	u.dropConfirm.SetConfirmButtonLabel("Drop")
+	u.dropConfirm.SetText(fmt.Sprintf("Are you sure you want to drop user [blue]%s[-] from %s?",
+		tview.Escape(name), tview.Escape(u.db)))
+	u.dropConfirm.SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+		u.App.Pages.RemovePage(u.dropConfirm.GetIdentifier())
+		if buttonLabel != "Drop" {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), usersCommandTimeout)
+		defer cancel()
+		if err := u.Dao.DropUser(ctx, u.db, name); err != nil {
+			modal.ShowError(u.App.Pages, "Error dropping user", err)
+			return
+		}
+		u.refresh(context.Background())
+	})
+	u.App.Pages.AddPage(u.dropConfirm.GetIdentifier(), u.dropConfirm, true, true)
+}
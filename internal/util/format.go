@@ -0,0 +1,32 @@
+package util
+
+import "fmt"
+
+// FormatBytes returns human readable size using binary units
+func FormatBytes(bytes float64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB"}
+	unit := 0
+	for bytes >= 1024 && unit < len(units)-1 {
+		bytes /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%.0f %s", bytes, units[unit])
+	}
+	return fmt.Sprintf("%.1f %s", bytes, units[unit])
+}
+
+// FormatCount returns human readable number using k, M and B suffixes
+func FormatCount(count int64) string {
+	value := float64(count)
+	suffixes := []string{"", "k", "M", "B"}
+	suffix := 0
+	for value >= 1000 && suffix < len(suffixes)-1 {
+		value /= 1000
+		suffix++
+	}
+	if suffix == 0 {
+		return fmt.Sprintf("%d", count)
+	}
+	return fmt.Sprintf("%.1f%s", value, suffixes[suffix])
+}
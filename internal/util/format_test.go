@@ -0,0 +1,20 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", FormatBytes(512))
+	assert.Equal(t, "1.5 KB", FormatBytes(1536))
+	assert.Equal(t, "2.0 GB", FormatBytes(2*1024*1024*1024))
+}
+
+func TestFormatCount(t *testing.T) {
+	assert.Equal(t, "999", FormatCount(999))
+	assert.Equal(t, "1.5k", FormatCount(1500))
+	assert.Equal(t, "12.3M", FormatCount(12_300_000))
+	assert.Equal(t, "2.0B", FormatCount(2_000_000_000))
+}
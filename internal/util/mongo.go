@@ -6,6 +6,7 @@ import (
 	"sort"
 
 	"github.com/kopecmaciej/tview"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -66,13 +67,19 @@ func StringifyMongoValueByType(v any) string {
 		return t.Hex()
 	case primitive.DateTime:
 		return t.Time().UTC().Format("2006-01-02T15:04:05.000+00:00")
-	case primitive.A, primitive.D, primitive.M, map[string]any, []any:
+	case primitive.D:
+		b, _ := bson.MarshalExtJSON(t, false, false)
+		return tview.Escape(string(b))
+	case primitive.A, primitive.M, map[string]any, []any:
 		b, _ := json.Marshal(t)
 		// Use tview's Escape function to prevent brackets from being interpreted as color tags
 		return tview.Escape(string(b))
 	case primitive.E:
 		return fmt.Sprintf("%v", t)
 	case primitive.Binary:
+		if uuid, ok := FormatUUID(t); ok {
+			return uuid
+		}
 		return fmt.Sprintf("%v", t)
 	case primitive.Regex:
 		return fmt.Sprintf("%v", t)
@@ -87,6 +94,15 @@ func StringifyMongoValueByType(v any) string {
 	}
 }
 
+// FormatUUID formats binary of UUID subtype in the canonical 8-4-4-4-12 form
+func FormatUUID(bin primitive.Binary) (string, bool) {
+	if bin.Subtype != bson.TypeBinaryUUID || len(bin.Data) != 16 {
+		return "", false
+	}
+	d := bin.Data
+	return fmt.Sprintf("%x-%x-%x-%x-%x", d[0:4], d[4:6], d[6:8], d[8:10], d[10:16]), true
+}
+
 // Helper function to determine MongoDB type
 func GetMongoType(v any) string {
 	switch v.(type) {
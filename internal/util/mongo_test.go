@@ -49,6 +49,8 @@ func TestGetValueByType(t *testing.T) {
 		{"Array", primitive.A{"a", "b"}, `["a","b"[]`},
 		{"Single item array", primitive.A{"single"}, `["single"[]`},
 		{"Object", primitive.M{"key": "value"}, `{"key":"value"}`},
+		{"Ordered object", primitive.D{{Key: "seq", Value: int64(7)}, {Key: "region", Value: "eu"}}, `{"seq":7,"region":"eu"}`},
+		{"UUID", primitive.Binary{Subtype: 4, Data: []byte{0x12, 0x3e, 0x45, 0x67, 0xe8, 0x9b, 0x12, 0xd3, 0xa4, 0x56, 0x42, 0x66, 0x14, 0x17, 0x40, 0x00}}, "123e4567-e89b-12d3-a456-426614174000"},
 		{"Null", nil, "null"},
 	}
 
@@ -0,0 +1,33 @@
+package util
+
+import "strings"
+
+var sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws values as a line of block characters scaled between 0 and
+// the highest value, only the last width values are drawn
+func Sparkline(values []float64, width int) string {
+	if width <= 0 || len(values) == 0 {
+		return ""
+	}
+	if len(values) > width {
+		values = values[len(values)-width:]
+	}
+
+	max := 0.0
+	for _, value := range values {
+		if value > max {
+			max = value
+		}
+	}
+
+	var sb strings.Builder
+	for _, value := range values {
+		level := 0
+		if max > 0 && value > 0 {
+			level = int(value / max * float64(len(sparklineBlocks)-1))
+		}
+		sb.WriteRune(sparklineBlocks[level])
+	}
+	return sb.String()
+}
@@ -0,0 +1,28 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		width    int
+		expected string
+	}{
+		{"empty", nil, 10, ""},
+		{"zero width", []float64{1, 2}, 0, ""},
+		{"all zeros", []float64{0, 0, 0}, 10, "▁▁▁"},
+		{"scaled to max", []float64{0, 7, 14}, 10, "▁▄█"},
+		{"only last values", []float64{100, 0, 1, 2}, 2, "▄█"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Sparkline(tt.values, tt.width))
+		})
+	}
+}